@@ -0,0 +1,50 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v2"
+)
+
+// CommandsFilename is the command allow-list file inside the config directory.
+const CommandsFilename = "commands.yml"
+
+// ArgRule describes the arguments an allowed command accepts. An argument
+// matches the rule if it is one of Values or Pattern matches all of it.
+type ArgRule struct {
+	Values  []string `yaml:"values"`
+	Pattern string   `yaml:"pattern"`
+	Max     int      `yaml:"max"` // how many arguments may match this rule, 0 for no limit
+}
+
+// CommandRule is a command the agent is allowed to execute.
+type CommandRule struct {
+	Command string    `yaml:"command"`
+	OS      string    `yaml:"os"`
+	Path    string    `yaml:"path"`     // absolute path of the binary, looked up in PATH if empty
+	MaxArgs int       `yaml:"max_args"` // 0 for no limit
+	Args    []ArgRule `yaml:"args"`     // each argument must match one rule, no rules means no arguments
+}
+
+type CommandsConfig struct {
+	Commands []CommandRule `yaml:"commands"`
+}
+
+// ParseCommandsConfig parses the contents of a command allow-list file.
+func ParseCommandsConfig(data []byte) (*CommandsConfig, error) {
+	var cfg CommandsConfig
+	if err := yaml.UnmarshalStrict(data, &cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// LoadCommandsConfig reads the command allow-list from the config directory.
+func LoadCommandsConfig(configPath string) (*CommandsConfig, error) {
+	data, err := os.ReadFile(filepath.Join(configPath, CommandsFilename))
+	if err != nil {
+		return nil, err
+	}
+	return ParseCommandsConfig(data)
+}
@@ -1,9 +1,12 @@
 package config
 
 import (
+	"fmt"
+	"log"
 	"os"
-
+	"path/filepath"
 	"runtime"
+	"sort"
 
 	"gopkg.in/yaml.v2"
 )
@@ -25,44 +28,178 @@ func init() {
 	}
 }
 
-var GlobalConfig Config
+// ConfigFilename is the agent's main configuration file inside the config directory.
+const ConfigFilename = "config.yml"
+
+// DropInDir is the directory inside the config directory whose .yml files
+// are merged over config.yml in lexical order.
+const DropInDir = "config.d"
 
+// Config is the agent's configuration. It is assembled from defaults,
+// config.yml, the drop-in files, OPENSHIELD_* environment variables and
+// command-line flags, each overriding the ones before.
 type Config struct {
-	MANAGER_ADDRESS       string `yaml:"MANAGER_ADDRESS"`
-	MANAGER_API_PORT      string `yaml:"MANAGER_API_PORT"`
-	MANAGER_GRPC_PORT     string `yaml:"MANAGER_GRPC_PORT"`
-	MANAGER_REGISTER_PORT string `yaml:"MANAGER_REGISTER_PORT"`
-	COMMAND_TIMEOUT       string `yaml:"COMMAND_TIMEOUT"`
+	Manager   ManagerConfig   `yaml:"manager"`
+	Heartbeat HeartbeatConfig `yaml:"heartbeat"`
+	Sync      SyncConfig      `yaml:"sync"`
+	Executor  ExecutorConfig  `yaml:"executor"`
+	TLS       TLSConfig       `yaml:"tls"`
 }
 
-func GenerateConfig(managerAddress string) *Config {
+type ManagerConfig struct {
+	Address      string `yaml:"address"`
+	APIPort      int    `yaml:"api_port"`
+	GRPCPort     int    `yaml:"grpc_port"`
+	RegisterPort int    `yaml:"register_port"`
+}
+
+type HeartbeatConfig struct {
+	Interval Duration `yaml:"interval"`
+}
+
+type SyncConfig struct {
+	Interval Duration `yaml:"interval"` // between pulls from the manager, 0 to disable
+}
+
+type ExecutorConfig struct {
+	CommandTimeout     Duration `yaml:"command_timeout"`          // for jobs that set none
+	MaxConcurrentTasks int      `yaml:"max_concurrent_tasks"`     // applies after a restart
+	JobMemoryMax       string   `yaml:"job_memory_max,omitempty"` // cgroup memory.max for each job, e.g. "512M"
+	JobCPUMax          string   `yaml:"job_cpu_max,omitempty"`    // cgroup cpu.max for each job, e.g. "50000 100000"
+	// PrivilegeHelper is the command that runs commands as root when the
+	// agent is not root, such as [sudo, -n] or [doas, -n]. It must not
+	// prompt for a password. Empty to never elevate.
+	PrivilegeHelper []string `yaml:"privilege_helper"`
+}
+
+// TLSConfig locates the agent's certificates, relative to the certs directory
+// unless absolute, and restricts the TLS connections to and from the manager.
+type TLSConfig struct {
+	CAFile       string   `yaml:"ca_file"`
+	CertFile     string   `yaml:"cert_file"`
+	KeyFile      string   `yaml:"key_file"`
+	MinVersion   string   `yaml:"min_version"`             // "1.2" or "1.3"
+	CipherSuites []string `yaml:"cipher_suites,omitempty"` // names as in crypto/tls, all secure suites if empty
+}
+
+// Default returns the configuration used for settings that are not set.
+func Default() *Config {
 	return &Config{
-		MANAGER_ADDRESS:       managerAddress,
-		MANAGER_API_PORT:      "9000",
-		MANAGER_GRPC_PORT:     "50052",
-		MANAGER_REGISTER_PORT: "50053",
-		COMMAND_TIMEOUT:       "60",
+		Manager: ManagerConfig{
+			APIPort:      9000,
+			GRPCPort:     50052,
+			RegisterPort: 50053,
+		},
+		Heartbeat: HeartbeatConfig{Interval: Seconds(10)},
+		Sync:      SyncConfig{Interval: Seconds(300)},
+		Executor: ExecutorConfig{
+			CommandTimeout:     Seconds(60),
+			MaxConcurrentTasks: 4,
+			PrivilegeHelper:    []string{"sudo", "-n"},
+		},
+		TLS: TLSConfig{
+			CAFile:     "ca.crt",
+			CertFile:   "agent.crt",
+			KeyFile:    "agent.key",
+			MinVersion: "1.2",
+		},
+	}
+}
+
+func GenerateConfig(managerAddress string) *Config {
+	cfg := Default()
+	cfg.Manager.Address = managerAddress
+	return cfg
+}
+
+// certPath resolves a certificate file against the certs directory.
+func certPath(file string) string {
+	if filepath.IsAbs(file) {
+		return file
 	}
+	return filepath.Join(CertsPath, file)
 }
 
+func (t TLSConfig) CAPath() string   { return certPath(t.CAFile) }
+func (t TLSConfig) CertPath() string { return certPath(t.CertFile) }
+func (t TLSConfig) KeyPath() string  { return certPath(t.KeyFile) }
+
+// LoadConfig assembles the configuration from config.yml in the config
+// directory and the layers over it.
 func LoadConfig(configPath string) (*Config, error) {
-	configFile := configPath + string(os.PathSeparator) + "config.yml"
-	data, err := os.ReadFile(configFile)
+	data, err := os.ReadFile(filepath.Join(configPath, ConfigFilename))
 	if err != nil {
 		return nil, err
 	}
-	var cfg Config
-	if err := yaml.Unmarshal(data, &cfg); err != nil {
+	return parseConfig(configPath, data)
+}
+
+// ParseConfig assembles the configuration as if data were config.yml, and
+// validates it.
+func ParseConfig(data []byte) (*Config, error) {
+	return parseConfig(ConfigPath, data)
+}
+
+func parseConfig(configPath string, data []byte) (*Config, error) {
+	cfg := Default()
+	if err := cfg.merge(data); err != nil {
+		return nil, err
+	}
+
+	// Drop-in files override config.yml in lexical order
+	dropIns, _ := filepath.Glob(filepath.Join(configPath, DropInDir, "*.yml"))
+	sort.Strings(dropIns)
+	for _, path := range dropIns {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := cfg.merge(data); err != nil {
+			return nil, fmt.Errorf("%s: %w", filepath.Join(DropInDir, filepath.Base(path)), err)
+		}
+	}
+
+	if err := cfg.applyEnv(os.LookupEnv); err != nil {
+		return nil, err
+	}
+	cfg.applyOverrides()
+
+	if err := cfg.Validate(); err != nil {
 		return nil, err
 	}
-	return &cfg, nil
+	return cfg, nil
+}
+
+// merge applies the settings of a YAML document, in the sectioned or the
+// flat format, over the configuration.
+func (c *Config) merge(data []byte) error {
+	if err := yaml.Unmarshal(data, c); err != nil {
+		return err
+	}
+	var flat flatConfig
+	if err := yaml.Unmarshal(data, &flat); err != nil {
+		return err
+	}
+	return flat.apply(c)
 }
 
+// ValidateConfig checks the contents of a config.yml before it is written.
+func ValidateConfig(data []byte) error {
+	_, err := ParseConfig(data)
+	return err
+}
+
+// LoadAndSetConfig loads config.yml and puts it into effect. If it cannot be
+// loaded, the last known-good version is restored instead.
 func LoadAndSetConfig(path string) error {
 	cfg, err := LoadConfig(path)
 	if err != nil {
-		return err
+		if len(knownGood()) == 0 {
+			return err
+		}
+		log.Printf("[CONFIG SYNC] Failed to load %s, restoring the last known-good version: %v", ConfigFilename, err)
+		return restoreKnownGood()
 	}
-	GlobalConfig = *cfg
+	Set(cfg)
 	return nil
 }
@@ -0,0 +1,63 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLayers(t *testing.T) {
+	configPath := ConfigPath
+	ConfigPath = t.TempDir()
+	t.Cleanup(func() { ConfigPath = configPath })
+
+	write := func(name, content string) {
+		t.Helper()
+		path := filepath.Join(ConfigPath, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(ConfigFilename, "manager:\n  address: file\nheartbeat:\n  interval: 30s\nexecutor:\n  command_timeout: 90\n")
+	write(filepath.Join(DropInDir, "20-tasks.yml"), "executor:\n  max_concurrent_tasks: 2\n")
+	write(filepath.Join(DropInDir, "10-tasks.yml"), "executor:\n  max_concurrent_tasks: 8\n  job_memory_max: 512M\n")
+	write(filepath.Join(DropInDir, "30-legacy.yml"), "SYNC_INTERVAL: \"0\"\n")
+	t.Setenv("OPENSHIELD_HEARTBEAT_INTERVAL", "1m")
+	t.Setenv("OPENSHIELD_MANAGER_ADDRESS", "env")
+	t.Setenv("OPENSHIELD_TLS_CIPHER_SUITES", "TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256, TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256")
+
+	saved := overrides
+	t.Cleanup(func() { overrides = saved })
+	Override(func(cfg *Config) { cfg.Manager.Address = "flag" })
+
+	cfg, err := LoadConfig(ConfigPath)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	for _, check := range []struct {
+		name      string
+		got, want interface{}
+	}{
+		{"manager.address", cfg.Manager.Address, "flag"},
+		{"manager.grpc_port", cfg.Manager.GRPCPort, 50052},
+		{"heartbeat.interval", cfg.Heartbeat.Interval.Std(), time.Minute},
+		{"sync.interval", cfg.Sync.Interval.Std(), time.Duration(0)},
+		{"executor.command_timeout", cfg.Executor.CommandTimeout.Std(), 90 * time.Second},
+		{"executor.max_concurrent_tasks", cfg.Executor.MaxConcurrentTasks, 2},
+		{"executor.job_memory_max", cfg.Executor.JobMemoryMax, "512M"},
+		{"len(tls.cipher_suites)", len(cfg.TLS.CipherSuites), 2},
+	} {
+		if check.got != check.want {
+			t.Errorf("%s = %v, want %v", check.name, check.got, check.want)
+		}
+	}
+
+	t.Setenv("OPENSHIELD_EXECUTOR_MAX_CONCURRENT_TASKS", "many")
+	if _, err := LoadConfig(ConfigPath); err == nil {
+		t.Error("LoadConfig() accepted an invalid environment variable")
+	}
+}
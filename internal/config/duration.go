@@ -0,0 +1,64 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Duration is a time.Duration that is written in YAML as a string such as
+// "90s" or "5m". A bare number is read as seconds, as in older config files.
+type Duration time.Duration
+
+// Seconds returns a Duration of n seconds.
+func Seconds(n int) Duration {
+	return Duration(time.Duration(n) * time.Second)
+}
+
+// Std returns the duration as a time.Duration.
+func (d Duration) Std() time.Duration {
+	return time.Duration(d)
+}
+
+func (d Duration) String() string {
+	return time.Duration(d).String()
+}
+
+// ParseDuration parses a duration string or a number of seconds.
+func ParseDuration(s string) (Duration, error) {
+	if n, err := strconv.Atoi(s); err == nil {
+		return Seconds(n), nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a duration such as 30s or 5m", s)
+	}
+	return Duration(d), nil
+}
+
+func (d *Duration) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s string
+	if err := unmarshal(&s); err != nil {
+		return err
+	}
+	parsed, err := ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+func (d Duration) MarshalYAML() (interface{}, error) {
+	return d.String(), nil
+}
+
+// Set parses the duration of a command-line flag.
+func (d *Duration) Set(s string) error {
+	parsed, err := ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
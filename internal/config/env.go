@@ -0,0 +1,84 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v2"
+)
+
+// EnvPrefix starts the environment variables that override settings. A
+// setting is named by its section and key, such as OPENSHIELD_MANAGER_ADDRESS
+// for manager.address or OPENSHIELD_EXECUTOR_COMMAND_TIMEOUT. Lists are
+// separated by commas.
+const EnvPrefix = "OPENSHIELD_"
+
+var (
+	overridesMu sync.Mutex
+	overrides   []func(*Config)
+)
+
+// Override registers a function that is applied to every configuration
+// after the files and the environment, such as for command-line flags.
+func Override(fn func(*Config)) {
+	overridesMu.Lock()
+	defer overridesMu.Unlock()
+	overrides = append(overrides, fn)
+}
+
+// applyOverrides applies the registered overrides to the configuration.
+func (c *Config) applyOverrides() {
+	overridesMu.Lock()
+	defer overridesMu.Unlock()
+	for _, override := range overrides {
+		override(c)
+	}
+}
+
+// applyEnv sets the settings that have an environment variable.
+func (c *Config) applyEnv(lookup func(string) (string, bool)) error {
+	sections := reflect.ValueOf(c).Elem()
+	for i := 0; i < sections.NumField(); i++ {
+		section := sections.Field(i)
+		sectionName := yamlName(sections.Type().Field(i))
+		for j := 0; j < section.NumField(); j++ {
+			key := yamlName(section.Type().Field(j))
+			name := EnvPrefix + strings.ToUpper(sectionName+"_"+key)
+			value, ok := lookup(name)
+			if !ok {
+				continue
+			}
+			if err := setField(section.Field(j), value); err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// yamlName returns the YAML key of a struct field.
+func yamlName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+	return name
+}
+
+// setField sets a setting from the text of an environment variable.
+func setField(field reflect.Value, value string) error {
+	switch field.Interface().(type) {
+	case string:
+		field.SetString(value)
+		return nil
+	case []string:
+		var list []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		field.Set(reflect.ValueOf(list))
+		return nil
+	}
+	return yaml.UnmarshalStrict([]byte(value), field.Addr().Interface())
+}
@@ -0,0 +1,154 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// KeepKnownGood is how many known-good versions of config.yml are kept.
+	KeepKnownGood = 5
+	// RollbackGracePeriod is how long a reloaded config.yml has to reach the
+	// manager before the last known-good version is restored.
+	RollbackGracePeriod = 2 * time.Minute
+)
+
+var (
+	historyMu sync.Mutex
+	// probation is the reloaded configuration waiting to reach the manager
+	probation *Config
+	rollback  *time.Timer
+	// recorded is the configuration MarkKnownGood last handled, so later
+	// heartbeats with it do not touch the disk again
+	recorded *Config
+)
+
+// historyDir holds known-good versions of config.yml, oldest first by name.
+func historyDir() string {
+	return filepath.Join(ConfigPath, "state", "config-history")
+}
+
+// knownGood returns the paths of the known-good versions, oldest first.
+func knownGood() []string {
+	paths, _ := filepath.Glob(filepath.Join(historyDir(), "config-*.yml"))
+	sort.Strings(paths)
+	return paths
+}
+
+// MarkKnownGood records cfg as known good once the agent reached the manager
+// with it. It is ignored unless cfg is still the configuration in effect, so
+// a success of a connection made with an older configuration does not count.
+// Only the first call for a configuration reads and writes files.
+func MarkKnownGood(cfg *Config) {
+	historyMu.Lock()
+	defer historyMu.Unlock()
+
+	if cfg != Get() {
+		return
+	}
+	if probation == cfg {
+		log.Printf("[CONFIG SYNC] Reloaded %s reached the manager, keeping it", ConfigFilename)
+		rollback.Stop()
+		probation = nil
+	}
+	if recorded == cfg {
+		return
+	}
+	// A failure to write the history below is retried on the next call
+	recorded = cfg
+
+	data, err := os.ReadFile(filepath.Join(ConfigPath, ConfigFilename))
+	if err != nil {
+		return
+	}
+	// Only record the file if it is what is in effect, not a later local edit
+	if onDisk, err := ParseConfig(data); err != nil || !reflect.DeepEqual(onDisk, cfg) {
+		return
+	}
+	paths := knownGood()
+	if len(paths) > 0 {
+		if last, err := os.ReadFile(paths[len(paths)-1]); err == nil && bytes.Equal(last, data) {
+			return
+		}
+	}
+
+	if err := os.MkdirAll(historyDir(), 0700); err != nil {
+		log.Printf("[CONFIG SYNC] Failed to record known-good config: %v", err)
+		recorded = nil
+		return
+	}
+	name := fmt.Sprintf("config-%s.yml", time.Now().UTC().Format("20060102T150405.000000000"))
+	if err := os.WriteFile(filepath.Join(historyDir(), name), data, 0600); err != nil {
+		log.Printf("[CONFIG SYNC] Failed to record known-good config: %v", err)
+		recorded = nil
+		return
+	}
+	paths = knownGood()
+	for len(paths) > KeepKnownGood {
+		os.Remove(paths[0])
+		paths = paths[1:]
+	}
+}
+
+// startProbation rolls back to the last known-good configuration unless cfg
+// reaches the manager within the grace period.
+func startProbation(cfg *Config, grace time.Duration) {
+	historyMu.Lock()
+	defer historyMu.Unlock()
+
+	if len(knownGood()) == 0 {
+		// Nothing to roll back to
+		return
+	}
+	if rollback != nil {
+		rollback.Stop()
+	}
+	probation = cfg
+	rollback = time.AfterFunc(grace, func() {
+		historyMu.Lock()
+		defer historyMu.Unlock()
+		if probation != cfg {
+			return
+		}
+		probation = nil
+		log.Printf("[CONFIG SYNC] Reloaded %s did not reach the manager within %s, rolling back", ConfigFilename, grace)
+		if err := restoreKnownGood(); err != nil {
+			log.Printf("[CONFIG SYNC] Rollback failed: %v", err)
+		}
+	})
+}
+
+// restoreKnownGood writes the newest known-good version over config.yml and
+// puts it into effect.
+func restoreKnownGood() error {
+	paths := knownGood()
+	if len(paths) == 0 {
+		return fmt.Errorf("no known-good %s", ConfigFilename)
+	}
+	data, err := os.ReadFile(paths[len(paths)-1])
+	if err != nil {
+		return err
+	}
+	cfg, err := ParseConfig(data)
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(ConfigPath, ConfigFilename)
+	if err := os.WriteFile(path+".tmp", data, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return err
+	}
+	Set(cfg)
+	log.Printf("[CONFIG SYNC] Restored known-good %s from %s", ConfigFilename, filepath.Base(paths[len(paths)-1]))
+	return nil
+}
@@ -0,0 +1,123 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeConfig(t *testing.T, address string) *Config {
+	t.Helper()
+	content := fmt.Sprintf("manager:\n  address: %s\n", address)
+	if err := os.WriteFile(filepath.Join(ConfigPath, ConfigFilename), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadConfig(ConfigPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cfg
+}
+
+func TestRollback(t *testing.T) {
+	configPath := ConfigPath
+	ConfigPath = t.TempDir()
+	t.Cleanup(func() { ConfigPath = configPath })
+
+	good := writeConfig(t, "good-manager")
+	Set(good)
+	MarkKnownGood(good)
+	if n := len(knownGood()); n != 1 {
+		t.Fatalf("%d known-good versions, want 1", n)
+	}
+
+	// A new config that reaches the manager is kept
+	working := writeConfig(t, "new-manager")
+	Set(working)
+	startProbation(working, 50*time.Millisecond)
+	MarkKnownGood(working)
+	time.Sleep(100 * time.Millisecond)
+	if Get().Manager.Address != "new-manager" {
+		t.Fatalf("confirmed config was rolled back")
+	}
+
+	// One that does not is rolled back to the newest known-good version
+	broken := writeConfig(t, "unreachable")
+	Set(broken)
+	startProbation(broken, 50*time.Millisecond)
+	// A success of an older connection does not count
+	MarkKnownGood(working)
+	time.Sleep(100 * time.Millisecond)
+
+	if got := Get().Manager.Address; got != "new-manager" {
+		t.Errorf("manager.address after rollback = %q, want new-manager", got)
+	}
+	onDisk, err := LoadConfig(ConfigPath)
+	if err != nil || onDisk.Manager.Address != "new-manager" {
+		t.Errorf("config.yml after rollback = %+v, %v", onDisk, err)
+	}
+}
+
+func TestKnownGoodLimit(t *testing.T) {
+	configPath := ConfigPath
+	ConfigPath = t.TempDir()
+	t.Cleanup(func() { ConfigPath = configPath })
+
+	for i := 0; i < KeepKnownGood+3; i++ {
+		cfg := writeConfig(t, fmt.Sprintf("manager-%d", i))
+		Set(cfg)
+		MarkKnownGood(cfg)
+	}
+	if n := len(knownGood()); n != KeepKnownGood {
+		t.Errorf("%d known-good versions, want %d", n, KeepKnownGood)
+	}
+
+	// An invalid config.yml at startup falls back to the newest one
+	if err := os.WriteFile(filepath.Join(ConfigPath, ConfigFilename), []byte("manager:\n  api_port: none\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := LoadAndSetConfig(ConfigPath); err != nil {
+		t.Fatalf("LoadAndSetConfig() error = %v", err)
+	}
+	if want := fmt.Sprintf("manager-%d", KeepKnownGood+2); Get().Manager.Address != want {
+		t.Errorf("manager.address = %q, want %s", Get().Manager.Address, want)
+	}
+}
+
+func TestMarkKnownGoodOnce(t *testing.T) {
+	configPath := ConfigPath
+	ConfigPath = t.TempDir()
+	t.Cleanup(func() { ConfigPath = configPath })
+
+	cfg := writeConfig(t, "manager")
+	Set(cfg)
+	MarkKnownGood(cfg)
+	if n := len(knownGood()); n != 1 {
+		t.Fatalf("%d known-good versions, want 1", n)
+	}
+
+	// Later heartbeats with the same config leave the history alone
+	os.Remove(knownGood()[0])
+	MarkKnownGood(cfg)
+	if n := len(knownGood()); n != 0 {
+		t.Errorf("%d known-good versions after marking the config again, want 0", n)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	cfg := GenerateConfig("manager")
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("generated config is invalid: %v", err)
+	}
+
+	bad := *cfg
+	bad.Manager.GRPCPort = 70000
+	bad.Executor.CommandTimeout = 0
+	bad.Executor.JobMemoryMax = "lots"
+	bad.TLS.MinVersion = "1.0"
+	if err := bad.Validate(); err == nil {
+		t.Error("Validate() accepted an invalid config")
+	}
+}
@@ -0,0 +1,71 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// flatConfig holds the keys of the flat config.yml format used before the
+// configuration had sections. Files in that format are still accepted, and
+// its keys override the sectioned ones in the same file.
+type flatConfig struct {
+	MANAGER_ADDRESS       string `yaml:"MANAGER_ADDRESS"`
+	MANAGER_API_PORT      string `yaml:"MANAGER_API_PORT"`
+	MANAGER_GRPC_PORT     string `yaml:"MANAGER_GRPC_PORT"`
+	MANAGER_REGISTER_PORT string `yaml:"MANAGER_REGISTER_PORT"`
+	COMMAND_TIMEOUT       string `yaml:"COMMAND_TIMEOUT"`
+	MAX_CONCURRENT_TASKS  string `yaml:"MAX_CONCURRENT_TASKS"`
+	JOB_MEMORY_MAX        string `yaml:"JOB_MEMORY_MAX"`
+	JOB_CPU_MAX           string `yaml:"JOB_CPU_MAX"`
+	SYNC_INTERVAL         string `yaml:"SYNC_INTERVAL"`
+	HEARTBEAT_INTERVAL    string `yaml:"HEARTBEAT_INTERVAL"`
+}
+
+// apply sets the configuration from the flat keys that are present.
+func (f flatConfig) apply(c *Config) error {
+	if f.MANAGER_ADDRESS != "" {
+		c.Manager.Address = f.MANAGER_ADDRESS
+	}
+	if f.JOB_MEMORY_MAX != "" {
+		c.Executor.JobMemoryMax = f.JOB_MEMORY_MAX
+	}
+	if f.JOB_CPU_MAX != "" {
+		c.Executor.JobCPUMax = f.JOB_CPU_MAX
+	}
+	for _, field := range []struct {
+		name, value string
+		dst         *int
+	}{
+		{"MANAGER_API_PORT", f.MANAGER_API_PORT, &c.Manager.APIPort},
+		{"MANAGER_GRPC_PORT", f.MANAGER_GRPC_PORT, &c.Manager.GRPCPort},
+		{"MANAGER_REGISTER_PORT", f.MANAGER_REGISTER_PORT, &c.Manager.RegisterPort},
+		{"MAX_CONCURRENT_TASKS", f.MAX_CONCURRENT_TASKS, &c.Executor.MaxConcurrentTasks},
+	} {
+		if field.value == "" {
+			continue
+		}
+		n, err := strconv.Atoi(field.value)
+		if err != nil {
+			return fmt.Errorf("%s %q is not a whole number", field.name, field.value)
+		}
+		*field.dst = n
+	}
+	for _, field := range []struct {
+		name, value string
+		dst         *Duration
+	}{
+		{"COMMAND_TIMEOUT", f.COMMAND_TIMEOUT, &c.Executor.CommandTimeout},
+		{"SYNC_INTERVAL", f.SYNC_INTERVAL, &c.Sync.Interval},
+		{"HEARTBEAT_INTERVAL", f.HEARTBEAT_INTERVAL, &c.Heartbeat.Interval},
+	} {
+		if field.value == "" {
+			continue
+		}
+		d, err := ParseDuration(field.value)
+		if err != nil {
+			return fmt.Errorf("%s %w", field.name, err)
+		}
+		*field.dst = d
+	}
+	return nil
+}
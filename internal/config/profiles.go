@@ -0,0 +1,52 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v2"
+)
+
+// ProfilesFilename is the execution profile file inside the config directory.
+const ProfilesFilename = "profiles.yml"
+
+// ResourceLimits are rlimits applied to a sandboxed process. Zero means unlimited.
+type ResourceLimits struct {
+	CPUSeconds  uint64 `yaml:"cpu_seconds"`
+	MemoryBytes uint64 `yaml:"memory_bytes"`
+	OpenFiles   uint64 `yaml:"open_files"`
+	Processes   uint64 `yaml:"processes"` // counted per user, so best combined with a dedicated user
+}
+
+// ExecutionProfile describes how a job or tool action is sandboxed.
+type ExecutionProfile struct {
+	Name       string         `yaml:"name"`
+	User       string         `yaml:"user"` // user name, alternative to uid/gid
+	UID        *int           `yaml:"uid"`
+	GID        *int           `yaml:"gid"`
+	Limits     ResourceLimits `yaml:"limits"`
+	Namespaces []string       `yaml:"namespaces"` // any of mount, network, pid
+	NoNewPrivs bool           `yaml:"no_new_privs"`
+}
+
+type ProfilesConfig struct {
+	Profiles []ExecutionProfile `yaml:"profiles"`
+}
+
+// ParseProfilesConfig parses the contents of an execution profile file.
+func ParseProfilesConfig(data []byte) (*ProfilesConfig, error) {
+	var cfg ProfilesConfig
+	if err := yaml.UnmarshalStrict(data, &cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// LoadProfilesConfig reads the execution profiles from the config directory.
+func LoadProfilesConfig(configPath string) (*ProfilesConfig, error) {
+	data, err := os.ReadFile(filepath.Join(configPath, ProfilesFilename))
+	if err != nil {
+		return nil, err
+	}
+	return ParseProfilesConfig(data)
+}
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"log"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	current atomic.Pointer[Config]
+
+	subscribersMu sync.Mutex
+	subscribers   []chan *Config
+)
+
+func init() {
+	current.Store(Default())
+}
+
+// Get returns the configuration in effect. The returned value must not be
+// modified; it is replaced as a whole when the configuration is reloaded.
+func Get() *Config {
+	return current.Load()
+}
+
+// Set replaces the configuration in effect and notifies subscribers.
+func Set(cfg *Config) {
+	current.Store(cfg)
+
+	subscribersMu.Lock()
+	defer subscribersMu.Unlock()
+	for _, ch := range subscribers {
+		// Only the latest configuration matters to a subscriber that has not
+		// caught up yet
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- cfg
+	}
+}
+
+// Subscribe returns a channel that receives the new configuration each time
+// it is reloaded. A slow subscriber only receives the latest one.
+func Subscribe() <-chan *Config {
+	ch := make(chan *Config, 1)
+	subscribersMu.Lock()
+	subscribers = append(subscribers, ch)
+	subscribersMu.Unlock()
+	return ch
+}
+
+// Reload reads config.yml from the config directory again and puts it into
+// effect. If the file is invalid the current configuration is kept. If the
+// new configuration does not reach the manager within RollbackGracePeriod,
+// the last known-good one is restored.
+func Reload() error {
+	cfg, err := LoadConfig(ConfigPath)
+	if err != nil {
+		log.Printf("[CONFIG SYNC] Keeping current config, reload failed: %v", err)
+		return err
+	}
+	Set(cfg)
+	startProbation(cfg, RollbackGracePeriod)
+	log.Printf("[CONFIG SYNC] Reloaded %s", ConfigFilename)
+	return nil
+}
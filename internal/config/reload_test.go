@@ -0,0 +1,49 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReload(t *testing.T) {
+	configPath := ConfigPath
+	ConfigPath = t.TempDir()
+	t.Cleanup(func() { ConfigPath = configPath })
+
+	write := func(content string) {
+		t.Helper()
+		content += "MANAGER_API_PORT: \"9000\"\nMANAGER_GRPC_PORT: \"50052\"\nMANAGER_REGISTER_PORT: \"50053\"\n"
+		if err := os.WriteFile(filepath.Join(ConfigPath, ConfigFilename), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	changes := Subscribe()
+	write("MANAGER_ADDRESS: manager-a\nHEARTBEAT_INTERVAL: \"10\"\n")
+	if err := Reload(); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	write("MANAGER_ADDRESS: manager-b\nHEARTBEAT_INTERVAL: \"30\"\n")
+	if err := Reload(); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+
+	// A subscriber that did not keep up only sees the latest configuration
+	if cfg := <-changes; cfg.Manager.Address != "manager-b" {
+		t.Errorf("notified manager.address = %q, want manager-b", cfg.Manager.Address)
+	}
+
+	write("HEARTBEAT_INTERVAL: \"5\"\n")
+	if err := Reload(); err == nil {
+		t.Error("Reload() accepted a config without manager.address")
+	}
+	if got := Get(); got.Manager.Address != "manager-b" || got.Heartbeat.Interval != Seconds(30) {
+		t.Errorf("Get() = %+v, want the last valid config", got)
+	}
+	select {
+	case cfg := <-changes:
+		t.Errorf("notified of a rejected config %+v", cfg)
+	default:
+	}
+}
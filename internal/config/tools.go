@@ -1,12 +1,95 @@
 package config
 
+import (
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v2"
+)
+
+// ToolsFilename is the declarative tool definition file inside the config directory.
+const ToolsFilename = "tools.yml"
+
+// ToolOptionConfig describes an option of a declarative tool action.
+type ToolOptionConfig struct {
+	Name        string   `yaml:"name"`
+	Type        string   `yaml:"type"` // string, int, bool, path or list
+	Description string   `yaml:"description"`
+	Enum        []string `yaml:"enum"`
+	Default     string   `yaml:"default"`
+	Required    bool     `yaml:"required"`
+}
+
+// PackageSet names the packages of an action, either as a list or by
+// package manager, such as apt or dnf, with "default" for the others.
+type PackageSet map[string][]string
+
+func (p *PackageSet) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var list []string
+	if err := unmarshal(&list); err == nil {
+		*p = PackageSet{"default": list}
+		return nil
+	}
+	var byBackend map[string][]string
+	if err := unmarshal(&byBackend); err != nil {
+		return err
+	}
+	*p = byBackend
+	return nil
+}
+
+// For returns the packages for the package manager.
+func (p PackageSet) For(backend string) []string {
+	if packages, ok := p[backend]; ok {
+		return packages
+	}
+	return p["default"]
+}
+
+// ToolActionConfig is an action of a declarative tool. It runs a script
+// from the scripts directory with Args or the command line Command, or it
+// installs or removes packages with the distribution's package manager.
+// A placeholder such as {path} in Args or Command is replaced by the value
+// of the option; an argument that is just a placeholder for a list option
+// becomes one argument per item. Arguments with placeholders of options that
+// are not set are left out.
+type ToolActionConfig struct {
+	Name    string             `yaml:"name"`
+	Script  string             `yaml:"script"`  // overrides the tool's script
+	Args    []string           `yaml:"args"`    // script arguments, the action name if empty
+	Command []string           `yaml:"command"` // argv template, instead of a script
+	Install PackageSet         `yaml:"install"`
+	Remove  PackageSet         `yaml:"remove"`
+	Options []ToolOptionConfig `yaml:"options"`
+	Profile string             `yaml:"profile"` // execution profile the action's processes run with
+}
+
 type ToolConfig struct {
-	Name      string   `yaml:"name"`
-	ScriptURL string   `yaml:"script"`
-	Actions   []string `yaml:"actions"`
-	OS        []string `yaml:"os"`
+	Name    string             `yaml:"name"`
+	Script  string             `yaml:"script"` // script run by actions without a command
+	Actions []ToolActionConfig `yaml:"actions"`
+	OS      []string           `yaml:"os"`
+	Lock    string             `yaml:"lock"` // shared with tools that must not run at the same time
 }
 
 type ToolsConfig struct {
 	Tools []ToolConfig `yaml:"tools"`
 }
+
+// ParseToolsConfig parses the contents of a tool definition file.
+func ParseToolsConfig(data []byte) (*ToolsConfig, error) {
+	var cfg ToolsConfig
+	if err := yaml.UnmarshalStrict(data, &cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// LoadToolsConfig reads the tool definitions from the config directory.
+func LoadToolsConfig(configPath string) (*ToolsConfig, error) {
+	data, err := os.ReadFile(filepath.Join(configPath, ToolsFilename))
+	if err != nil {
+		return nil, err
+	}
+	return ParseToolsConfig(data)
+}
@@ -0,0 +1,94 @@
+package config
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+var (
+	memoryMaxPattern = regexp.MustCompile(`^(max|[0-9]+[KMGT]?)$`)
+	cpuMaxPattern    = regexp.MustCompile(`^(max|[0-9]+)( [0-9]+)?$`)
+)
+
+// TLSVersions maps the accepted tls.min_version values to their versions.
+var TLSVersions = map[string]uint16{
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// Validate checks the configuration against its schema and reports every
+// setting that is wrong.
+func (c *Config) Validate() error {
+	var errs []error
+	if c.Manager.Address == "" {
+		errs = append(errs, errors.New("manager.address is required"))
+	}
+	for _, field := range []struct {
+		name  string
+		value int
+	}{
+		{"manager.api_port", c.Manager.APIPort},
+		{"manager.grpc_port", c.Manager.GRPCPort},
+		{"manager.register_port", c.Manager.RegisterPort},
+	} {
+		if field.value < 1 || field.value > 65535 {
+			errs = append(errs, fmt.Errorf("%s %d is not a port between 1 and 65535", field.name, field.value))
+		}
+	}
+	for _, field := range []struct {
+		name  string
+		value Duration
+	}{
+		{"heartbeat.interval", c.Heartbeat.Interval},
+		{"executor.command_timeout", c.Executor.CommandTimeout},
+	} {
+		if field.value <= 0 {
+			errs = append(errs, fmt.Errorf("%s must be positive", field.name))
+		}
+	}
+	if c.Sync.Interval < 0 {
+		errs = append(errs, errors.New("sync.interval must not be negative"))
+	}
+	if c.Executor.MaxConcurrentTasks < 1 {
+		errs = append(errs, fmt.Errorf("executor.max_concurrent_tasks %d must be at least 1", c.Executor.MaxConcurrentTasks))
+	}
+	if c.Executor.JobMemoryMax != "" && !memoryMaxPattern.MatchString(c.Executor.JobMemoryMax) {
+		errs = append(errs, fmt.Errorf("executor.job_memory_max %q is not a size such as 512M or max", c.Executor.JobMemoryMax))
+	}
+	if c.Executor.JobCPUMax != "" && !cpuMaxPattern.MatchString(c.Executor.JobCPUMax) {
+		errs = append(errs, fmt.Errorf("executor.job_cpu_max %q is not a quota and period such as \"50000 100000\"", c.Executor.JobCPUMax))
+	}
+	if len(c.Executor.PrivilegeHelper) > 0 && c.Executor.PrivilegeHelper[0] == "" {
+		errs = append(errs, errors.New("executor.privilege_helper must start with a command"))
+	}
+	if _, ok := TLSVersions[c.TLS.MinVersion]; !ok {
+		errs = append(errs, fmt.Errorf("tls.min_version %q is not 1.2 or 1.3", c.TLS.MinVersion))
+	}
+	if _, err := c.TLS.CipherSuiteIDs(); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// CipherSuiteIDs returns the IDs of the configured cipher suites, or nil for
+// the defaults of crypto/tls. Only TLS 1.2 suites can be configured.
+func (t TLSConfig) CipherSuiteIDs() ([]uint16, error) {
+	if len(t.CipherSuites) == 0 {
+		return nil, nil
+	}
+	secure := map[string]uint16{}
+	for _, suite := range tls.CipherSuites() {
+		secure[suite.Name] = suite.ID
+	}
+	var ids []uint16
+	for _, name := range t.CipherSuites {
+		id, ok := secure[name]
+		if !ok {
+			return nil, fmt.Errorf("tls.cipher_suites: %q is not a secure cipher suite", name)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
@@ -0,0 +1,261 @@
+//go:build linux
+
+package executor
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"log"
+	"math"
+	"openshield-agent/internal/config"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+const cgroupRoot = "/sys/fs/cgroup"
+
+var (
+	cgroupOnce sync.Once
+	cgroupBase string // the agent's delegated cgroup, empty if unavailable
+	cgroupFD   bool   // whether processes can be started inside a cgroup
+	cgroupSeq  atomic.Uint64
+)
+
+// jobCgroup is the cgroup v2 child a single command runs in.
+type jobCgroup struct {
+	path string
+	dir  *os.File
+}
+
+// cloneArgs is struct clone_args of the clone3 system call.
+type cloneArgs struct {
+	flags, pidfd, childTID, parentTID, exitSignal, stack, stackSize, tls, setTID, setTIDSize, cgroup uint64
+}
+
+// probeCgroupFD reports whether the kernel can start processes directly
+// inside a cgroup, which needs clone3 with CLONE_INTO_CGROUP (Linux 5.7).
+// The probe passes a file descriptor that is not open, so no process is
+// created: kernels that support it fail with EBADF, while older kernels and
+// seccomp filters that block clone3 fail with another error.
+func probeCgroupFD() bool {
+	args := cloneArgs{flags: unix.CLONE_INTO_CGROUP, cgroup: math.MaxInt32}
+	_, _, errno := unix.Syscall(unix.SYS_CLONE3, uintptr(unsafe.Pointer(&args)), unsafe.Sizeof(args), 0)
+	return errno == unix.EBADF
+}
+
+// setupCgroups prepares the agent's cgroup so jobs can get their own children.
+// The agent moves itself into an "agent" leaf, since a cgroup that hands out
+// controllers to its children cannot contain processes itself.
+func setupCgroups() {
+	var fs unix.Statfs_t
+	if err := unix.Statfs(cgroupRoot, &fs); err != nil || fs.Type != unix.CGROUP2_SUPER_MAGIC {
+		log.Printf("[EXECUTOR] Cgroup v2 not mounted at %s, falling back to rusage", cgroupRoot)
+		return
+	}
+
+	data, err := os.ReadFile("/proc/self/cgroup")
+	if err != nil {
+		log.Printf("[EXECUTOR] Cgroups unavailable, falling back to rusage: %v", err)
+		return
+	}
+	var rel string
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		if strings.HasPrefix(line, "0::") {
+			rel = strings.TrimPrefix(line, "0::")
+		}
+	}
+	if rel == "" {
+		log.Print("[EXECUTOR] Agent is not in a cgroup v2 hierarchy, falling back to rusage")
+		return
+	}
+
+	base := filepath.Join(cgroupRoot, rel)
+	if filepath.Base(base) == "agent" {
+		// Already moved, e.g. by an earlier agent process in the same service
+		base = filepath.Dir(base)
+	}
+	if err := setupCgroupBase(base); err != nil {
+		log.Printf("[EXECUTOR] Cgroups not delegated to the agent, falling back to rusage: %v", err)
+		return
+	}
+	cgroupBase = base
+	removeStaleCgroups(base)
+	cgroupFD = probeCgroupFD()
+	if !cgroupFD {
+		log.Print("[EXECUTOR] Kernel cannot start processes in a cgroup, moving jobs after they start")
+	}
+	log.Printf("[EXECUTOR] Accounting jobs in cgroup %s", base)
+}
+
+func setupCgroupBase(base string) error {
+	leaf := filepath.Join(base, "agent")
+	if err := os.Mkdir(leaf, 0755); err != nil && !os.IsExist(err) {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(leaf, "cgroup.procs"), []byte(strconv.Itoa(os.Getpid())), 0644); err != nil {
+		return err
+	}
+
+	available, err := os.ReadFile(filepath.Join(base, "cgroup.controllers"))
+	if err != nil {
+		return err
+	}
+	var enable []string
+	for _, c := range strings.Fields(string(available)) {
+		if c == "cpu" || c == "memory" || c == "pids" {
+			enable = append(enable, "+"+c)
+		}
+	}
+	if len(enable) == 0 {
+		return nil
+	}
+	return os.WriteFile(filepath.Join(base, "cgroup.subtree_control"), []byte(strings.Join(enable, " ")), 0644)
+}
+
+// removeStaleCgroups deletes job cgroups left behind by an agent process that
+// exited without cleaning up, killing any processes still in them.
+func removeStaleCgroups(base string) {
+	stale, _ := filepath.Glob(filepath.Join(base, "job-*"))
+	for _, path := range stale {
+		log.Printf("[EXECUTOR] Removing stale job cgroup %s", path)
+		(&jobCgroup{path: path}).remove()
+	}
+}
+
+// newJobCgroup creates a cgroup for one command with the configured limits.
+// It returns nil if cgroups are not available to the agent. Failing to create
+// the cgroup is only an error if limits are configured, since the command
+// would otherwise run without them; without limits it falls back to rusage.
+func newJobCgroup() (*jobCgroup, error) {
+	cgroupOnce.Do(setupCgroups)
+	if cgroupBase == "" {
+		return nil, nil
+	}
+	limits := map[string]string{
+		"memory.max": config.Get().Executor.JobMemoryMax,
+		"cpu.max":    config.Get().Executor.JobCPUMax,
+	}
+	limited := limits["memory.max"] != "" || limits["cpu.max"] != ""
+	fail := func(err error) (*jobCgroup, error) {
+		if limited {
+			return nil, fmt.Errorf("failed to create job cgroup for the configured limits: %w", err)
+		}
+		log.Printf("[EXECUTOR] Failed to create job cgroup: %v", err)
+		return nil, nil
+	}
+
+	// The PID keeps names unique across agent restarts
+	path := filepath.Join(cgroupBase, fmt.Sprintf("job-%d-%d", os.Getpid(), cgroupSeq.Add(1)))
+	if err := os.Mkdir(path, 0755); err != nil {
+		return fail(err)
+	}
+	cg := &jobCgroup{path: path}
+
+	for file, value := range limits {
+		if value == "" {
+			continue
+		}
+		if err := os.WriteFile(filepath.Join(path, file), []byte(value), 0644); err != nil {
+			cg.remove()
+			return fail(fmt.Errorf("failed to set %s to %q: %w", file, value, err))
+		}
+	}
+
+	dir, err := os.Open(path)
+	if err != nil {
+		cg.remove()
+		return fail(err)
+	}
+	cg.dir = dir
+	return cg, nil
+}
+
+// attach makes cmd start directly inside the cgroup, if the kernel supports
+// it. Otherwise join moves the process once it started.
+func (cg *jobCgroup) attach(cmd *exec.Cmd) {
+	if !cgroupFD {
+		return
+	}
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.UseCgroupFD = true
+	cmd.SysProcAttr.CgroupFD = int(cg.dir.Fd())
+}
+
+// join moves a started process into the cgroup, unless it was started inside
+// it. Processes it forked before are not moved along. It reports whether the
+// process is in the cgroup.
+func (cg *jobCgroup) join(pid int) bool {
+	if cgroupFD {
+		return true
+	}
+	if err := os.WriteFile(filepath.Join(cg.path, "cgroup.procs"), []byte(strconv.Itoa(pid)), 0644); err != nil {
+		log.Printf("[EXECUTOR] Failed to move process %d into job cgroup: %v", pid, err)
+		return false
+	}
+	return true
+}
+
+// usage reads what the processes in the cgroup consumed.
+func (cg *jobCgroup) usage() *ResourceUsage {
+	usage := &ResourceUsage{Source: "cgroup"}
+
+	if peak, err := os.ReadFile(filepath.Join(cg.path, "memory.peak")); err == nil {
+		usage.PeakMemoryBytes, _ = strconv.ParseUint(strings.TrimSpace(string(peak)), 10, 64)
+	}
+	if stat, err := os.ReadFile(filepath.Join(cg.path, "cpu.stat")); err == nil {
+		if usec, ok := readKeyedValue(stat, "usage_usec"); ok {
+			usage.CPUTime = time.Duration(usec) * time.Microsecond
+		}
+	}
+	if events, err := os.ReadFile(filepath.Join(cg.path, "memory.events")); err == nil {
+		if kills, ok := readKeyedValue(events, "oom_kill"); ok {
+			usage.OOMKilled = kills > 0
+		}
+	}
+	return usage
+}
+
+// remove kills any processes the job left behind and deletes the cgroup.
+func (cg *jobCgroup) remove() {
+	if cg.dir != nil {
+		cg.dir.Close()
+	}
+	// cgroup.kill needs Linux 5.14, older kernels just keep leftover processes
+	_ = os.WriteFile(filepath.Join(cg.path, "cgroup.kill"), []byte("1"), 0644)
+
+	var err error
+	for i := 0; i < 50; i++ {
+		// The cgroup can only be removed once the killed processes are gone
+		if err = os.Remove(cg.path); err == nil || os.IsNotExist(err) {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	log.Printf("[EXECUTOR] Failed to remove job cgroup %s: %v", cg.path, err)
+}
+
+// readKeyedValue returns the value for key in a flat-keyed cgroup file.
+func readKeyedValue(data []byte, key string) (uint64, bool) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && fields[0] == key {
+			v, err := strconv.ParseUint(fields[1], 10, 64)
+			return v, err == nil
+		}
+	}
+	return 0, false
+}
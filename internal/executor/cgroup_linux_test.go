@@ -0,0 +1,33 @@
+//go:build linux
+
+package executor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRunCgroupUsage(t *testing.T) {
+	cg, err := newJobCgroup()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cg == nil {
+		t.Skip("cgroups are not delegated to the agent")
+	}
+	_, err = os.Stat(filepath.Join(cg.path, "memory.peak"))
+	cg.remove()
+	if err != nil {
+		t.Skip("memory.peak is not available")
+	}
+
+	res, err := Run(context.Background(), Options{}, "/bin/sh", "-c", "exit 0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Resources == nil || res.Resources.Source != "cgroup" || res.Resources.PeakMemoryBytes == 0 {
+		t.Errorf("Expected usage measured in the job's cgroup, got %+v", res.Resources)
+	}
+}
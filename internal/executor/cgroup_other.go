@@ -0,0 +1,19 @@
+//go:build !linux
+
+package executor
+
+import "os/exec"
+
+// jobCgroup is unused, as cgroups only exist on Linux.
+type jobCgroup struct{}
+
+// newJobCgroup always returns nil, as cgroups only exist on Linux.
+func newJobCgroup() (*jobCgroup, error) { return nil, nil }
+
+func (cg *jobCgroup) attach(cmd *exec.Cmd) {}
+
+func (cg *jobCgroup) join(pid int) bool { return false }
+
+func (cg *jobCgroup) usage() *ResourceUsage { return nil }
+
+func (cg *jobCgroup) remove() {}
@@ -1,49 +1,125 @@
 package executor
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"fmt"
+	"io"
 	"log"
 	"openshield-agent/internal/config"
 	"openshield-agent/internal/models"
 	"os/exec"
-	"strconv"
 	"time"
 )
 
-// ExecuteCommand executes a command with the given arguments and returns the output.
-func ExecuteCommand(cmd models.Command) (string, error) {
-	// Check if the command is whitelisted
+// waitDelay bounds how long to wait for output pipes after the process is killed.
+const waitDelay = 5 * time.Second
+
+// ExecuteCommand executes a whitelisted command with the given arguments.
+// Cancelling ctx kills the command and any processes it started.
+//
+// The result is nil if the command could not be started. If it ran but did not
+// succeed, both the result and an error are returned.
+func ExecuteCommand(ctx context.Context, cmd models.Command, opts Options) (*Result, error) {
+	// Check if the command targets this OS
 	if !IsValidForCurrentOS(cmd) {
-		return "", errors.New("command not valid for this OS")
+		return nil, errors.New("command not valid for this OS")
 	}
 
-	// Check if the command is whitelisted
-	if !IsCommandWhitelisted(cmd) {
-		log.Printf("Rejected command: %+v", cmd)
-		return "", errors.New("command not whitelisted")
+	// Check if the command and its arguments are whitelisted
+	binary, err := ResolveCommand(cmd)
+	if err != nil {
+		log.Printf("Rejected command: %+v: %v", cmd, err)
+		return nil, err
 	}
 
-	out, err := runCommand(cmd.Command, cmd.Args...)
-	return out, err
+	return Run(ctx, opts, binary, cmd.Args...)
 }
 
-// runCommand executes a command with a timeout.
-func runCommand(command string, args ...string) (string, error) {
-	config := config.GlobalConfig
+// defaultTimeout returns the configured executor.command_timeout.
+func defaultTimeout() time.Duration {
+	return config.Get().Executor.CommandTimeout.Std()
+}
 
-	timeoutStr := config.COMMAND_TIMEOUT
-	timeout := 30 // default timeout in seconds
-	if timeoutStr != "" {
-		if t, err := strconv.Atoi(timeoutStr); err == nil {
-			timeout = t
-		}
+// Run executes a command with the configured timeout, without checking it
+// against the whitelist. It is meant for commands built by the agent itself,
+// such as tool actions, and has the same result semantics as ExecuteCommand.
+func Run(ctx context.Context, opts Options, command string, args ...string) (*Result, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+
+	timeout := opts.Timeout
+	if timeout == 0 {
+		timeout = defaultTimeout()
 	}
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeout)*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
 	cmd := exec.CommandContext(ctx, command, args...)
-	output, err := cmd.CombinedOutput()
+	setProcessGroup(cmd)
+	if opts.Profile != "" {
+		spec, err := lookupProfile(opts.Profile)
+		if err != nil {
+			return nil, err
+		}
+		if err := applySandbox(cmd, spec); err != nil {
+			return nil, err
+		}
+	}
+	cgroup, err := newJobCgroup()
+	if err != nil {
+		return nil, err
+	}
+	if cgroup != nil {
+		defer cgroup.remove()
+		cgroup.attach(cmd)
+	}
+	cmd.WaitDelay = waitDelay
+	cmd.Dir = opts.Dir
+	cmd.Env = opts.environ()
+	if opts.Stdin != nil {
+		cmd.Stdin = bytes.NewReader(opts.Stdin)
+	}
+
+	stdout := newOutputBuffer(maxOutputSize)
+	stderr := newOutputBuffer(maxOutputSize)
+	combined := newOutputBuffer(maxOutputSize)
+	cmd.Stdout = teeWriter(io.MultiWriter(stdout, combined), opts.Stdout)
+	cmd.Stderr = teeWriter(io.MultiWriter(stderr, combined), opts.Stderr)
+
+	start := time.Now()
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start %s: %w", command, err)
+	}
+	if cgroup != nil && !cgroup.join(cmd.Process.Pid) {
+		// Measure with rusage instead, the deferred remove still cleans up
+		cgroup = nil
+	}
+	err = cmd.Wait()
 
-	return string(output), err
+	result := &Result{
+		ExitCode:  cmd.ProcessState.ExitCode(),
+		Stdout:    stdout.String(),
+		Stderr:    stderr.String(),
+		Output:    combined.String(),
+		Signal:    exitSignal(cmd.ProcessState),
+		StartTime: start,
+		EndTime:   time.Now(),
+		Truncated: stdout.Truncated() || stderr.Truncated() || combined.Truncated(),
+	}
+	if cgroup != nil {
+		result.Resources = cgroup.usage()
+	} else {
+		result.Resources = processUsage(cmd.ProcessState)
+	}
+
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return result, fmt.Errorf("%s timed out after %s", command, timeout)
+	}
+	if err != nil {
+		return result, fmt.Errorf("%s failed: %w", command, err)
+	}
+	return result, nil
 }
@@ -0,0 +1,79 @@
+package executor
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestRunResult(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires /bin/sh")
+	}
+
+	res, err := Run(context.Background(), Options{}, "/bin/sh", "-c", "echo out; echo err >&2; exit 3")
+	if err == nil {
+		t.Error("Expected a non-zero exit to return an error")
+	}
+	if res == nil {
+		t.Fatal("Expected a result for a command that started")
+	}
+	if res.ExitCode != 3 || res.Stdout != "out\n" || res.Stderr != "err\n" {
+		t.Errorf("Unexpected result: %+v", res)
+	}
+	if res.EndTime.Before(res.StartTime) {
+		t.Error("Expected end time after start time")
+	}
+	if res.Resources == nil {
+		t.Error("Expected resource usage to be measured")
+	}
+
+	// Each stream fits, but the combined output does not
+	res, err = Run(context.Background(), Options{}, "/bin/sh", "-c", "head -c 600000 /dev/zero; head -c 600000 /dev/zero >&2")
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if len(res.Stdout) != 600000 || len(res.Output) != maxOutputSize || !res.Truncated {
+		t.Errorf("Expected the cut combined output to mark the result truncated, got %d bytes of output, truncated %v", len(res.Output), res.Truncated)
+	}
+
+	res, err = Run(context.Background(), Options{}, "/nonexistent/binary")
+	if err == nil || res != nil {
+		t.Errorf("Expected no result for a command that could not start, got %+v", res)
+	}
+}
+
+func TestRunOptions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires /bin/sh")
+	}
+
+	dir := t.TempDir()
+	opts := Options{
+		Env:   map[string]string{"GREETING": "hello"},
+		Dir:   dir,
+		Stdin: []byte("world"),
+	}
+	res, err := Run(context.Background(), opts, "/bin/sh", "-c", `printf '%s %s %s' "$GREETING" "$(cat)" "$(pwd)"`)
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if want := "hello world " + dir; res.Stdout != want {
+		t.Errorf("Expected %q, got %q", want, res.Stdout)
+	}
+
+	if _, err := Run(context.Background(), Options{Env: map[string]string{"BAD=KEY": "x"}}, "/bin/true"); err == nil {
+		t.Error("Expected an invalid environment variable name to be rejected")
+	}
+	for _, key := range []string{"BASH_ENV", "LD_PRELOAD", "ld_library_path", "PATH", "PYTHONSTARTUP"} {
+		if _, err := Run(context.Background(), Options{Env: map[string]string{key: "/tmp/x"}}, "/bin/true"); err == nil {
+			t.Errorf("Expected environment variable %s to be rejected", key)
+		}
+	}
+
+	res, err = Run(context.Background(), Options{Timeout: 100 * time.Millisecond}, "/bin/sh", "-c", "sleep 5")
+	if err == nil || res == nil || res.Signal == "" {
+		t.Errorf("Expected the timeout to kill the process, got %+v, %v", res, err)
+	}
+}
@@ -0,0 +1,141 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"openshield-agent/internal/config"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// ErrInsufficientPrivileges is returned for work that needs root privileges
+// when the agent is not root and the privilege helper cannot provide them.
+var ErrInsufficientPrivileges = errors.New("insufficient privileges")
+
+// isElevated reports whether the agent runs as root, or with an elevated
+// token on Windows.
+var isElevated = processElevated
+
+// helperDenials are messages of privilege helpers that refused to run a
+// command, such as sudo -n when it would have to ask for a password. Helpers
+// run in the C locale so that they print these untranslated.
+var helperDenials = []string{
+	"sudo: a password is required",
+	"sudo: a terminal is required",
+	"is not in the sudoers file",
+	"is not allowed to execute",
+	"effective uid is not 0",
+	"doas: Authentication required",
+	"doas: Operation not permitted",
+}
+
+// IsElevated reports whether the agent runs as root, or with an elevated
+// token on Windows.
+func IsElevated() bool {
+	return isElevated()
+}
+
+// Elevate returns the command line that runs a command as root: the command
+// itself if the agent is root, or the command behind the configured
+// executor.privilege_helper otherwise.
+func Elevate(command string, args ...string) ([]string, error) {
+	cmdline := append([]string{command}, args...)
+	if isElevated() {
+		return cmdline, nil
+	}
+	helper := config.Get().Executor.PrivilegeHelper
+	if len(helper) == 0 {
+		return nil, fmt.Errorf("%w to run %s: the agent is not root and no privilege helper is configured", ErrInsufficientPrivileges, command)
+	}
+	if _, err := exec.LookPath(helper[0]); err != nil {
+		return nil, fmt.Errorf("%w to run %s: privilege helper %s not found", ErrInsufficientPrivileges, command, helper[0])
+	}
+	return append(slices.Clone(helper), cmdline...), nil
+}
+
+// RunElevated runs a command as root, through the privilege helper unless
+// the agent is root. It has the same result semantics as Run. A helper that
+// refuses to run the command is reported as ErrInsufficientPrivileges. The
+// helper and the command run with LC_ALL=C.
+func RunElevated(ctx context.Context, opts Options, command string, args ...string) (*Result, error) {
+	if opts.Profile != "" && !isElevated() {
+		// Profiles can drop privileges or set no_new_privs, which keep
+		// helpers such as sudo from working
+		return nil, fmt.Errorf("%w to run %s: the privilege helper cannot be used with execution profile %s", ErrInsufficientPrivileges, command, opts.Profile)
+	}
+	cmdline, err := Elevate(command, args...)
+	if err != nil {
+		return nil, err
+	}
+	if !isElevated() {
+		opts.Env = maps.Clone(opts.Env)
+		if opts.Env == nil {
+			opts.Env = map[string]string{}
+		}
+		opts.Env["LC_ALL"] = "C"
+	}
+	res, err := Run(ctx, opts, cmdline[0], cmdline[1:]...)
+	if err != nil && res != nil && helperDenied(res.Stderr) {
+		return res, fmt.Errorf("%w to run %s: %s", ErrInsufficientPrivileges, command, strings.TrimSpace(res.Stderr))
+	}
+	return res, err
+}
+
+// helperDenied reports whether the output of a privilege helper says it
+// refused to run the command.
+func helperDenied(stderr string) bool {
+	for _, denial := range helperDenials {
+		if strings.Contains(stderr, denial) {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteFile writes a file that may need root privileges to write, without
+// going through a shell. As root, the file is replaced atomically. Otherwise
+// the privilege helper runs tee with the data on its standard input, then
+// chmod.
+func WriteFile(ctx context.Context, path string, data []byte, perm os.FileMode) error {
+	if !filepath.IsAbs(path) {
+		return fmt.Errorf("path must be absolute: %s", path)
+	}
+	if isElevated() {
+		return writeFileAtomic(path, data, perm)
+	}
+	if _, err := RunElevated(ctx, Options{Stdin: data}, "tee", path); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if _, err := RunElevated(ctx, Options{}, "chmod", fmt.Sprintf("%o", perm.Perm()), path); err != nil {
+		return fmt.Errorf("failed to set the mode of %s: %w", path, err)
+	}
+	return nil
+}
+
+// writeFileAtomic writes a file through a temporary file in the same
+// directory, so readers never see it partly written.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,75 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"openshield-agent/internal/config"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestElevate(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires /bin/sh")
+	}
+	defer func(old func() bool) { isElevated = old }(isElevated)
+	defer config.Set(config.Get())
+	setHelper := func(helper ...string) {
+		cfg := config.Default()
+		cfg.Executor.PrivilegeHelper = helper
+		config.Set(cfg)
+	}
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	isElevated = func() bool { return true }
+	if cmdline, err := Elevate("true"); err != nil || len(cmdline) != 1 {
+		t.Errorf("Elevate() as root = %q, %v, want the command itself", cmdline, err)
+	}
+
+	isElevated = func() bool { return false }
+	setHelper()
+	if _, err := Elevate("true"); !errors.Is(err, ErrInsufficientPrivileges) {
+		t.Errorf("Elevate() without a helper error = %v, want ErrInsufficientPrivileges", err)
+	}
+	setHelper("/nonexistent/sudo", "-n")
+	if _, err := Elevate("true"); !errors.Is(err, ErrInsufficientPrivileges) {
+		t.Errorf("Elevate() with a missing helper error = %v, want ErrInsufficientPrivileges", err)
+	}
+
+	// A helper that would ask for a password, in the agent's language
+	// unless it runs in the C locale
+	t.Setenv("LC_ALL", "de_DE.UTF-8")
+	deny := filepath.Join(dir, "deny")
+	script := "#!/bin/sh\n" +
+		"if [ \"$LC_ALL\" = C ]; then echo 'sudo: a password is required' >&2\n" +
+		"else echo 'sudo: Ein Passwort ist notwendig' >&2; fi\n" +
+		"exit 1\n"
+	if err := os.WriteFile(deny, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	setHelper(deny, "-n")
+	if _, err := RunElevated(ctx, Options{}, "true"); !errors.Is(err, ErrInsufficientPrivileges) {
+		t.Errorf("RunElevated() with a refusing helper error = %v, want ErrInsufficientPrivileges", err)
+	}
+
+	// env stands in for a helper that grants the privileges
+	setHelper("env")
+	path := filepath.Join(dir, "jail.conf")
+	if err := WriteFile(ctx, path, []byte("[sshd]\nenabled = true\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile() through the helper error = %v", err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("WriteFile() through the helper wrote %v, %v, want mode 0600", info, err)
+	}
+
+	isElevated = func() bool { return true }
+	if err := WriteFile(ctx, path, []byte("[sshd]\nenabled = false\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile() as root error = %v", err)
+	}
+	if data, err := os.ReadFile(path); err != nil || string(data) != "[sshd]\nenabled = false\n" {
+		t.Errorf("WriteFile() as root wrote %q, %v", data, err)
+	}
+}
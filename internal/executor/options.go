@@ -0,0 +1,158 @@
+package executor
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// envKeyPattern matches valid environment variable names.
+var envKeyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Environment variables that make the dynamic loader, a shell or an
+// interpreter run code of the caller's choosing before or instead of the
+// command, which would get around the command allow-list and script
+// signatures. Names are compared in upper case, as Windows ignores case.
+var (
+	unsafeEnvKeys = map[string]bool{
+		"PATH": true, "IFS": true, "CDPATH": true, "ENV": true, "BASH_ENV": true,
+		"SHELLOPTS": true, "BASHOPTS": true, "PS4": true, "GLOBIGNORE": true,
+		"PERL5LIB": true, "PERL5OPT": true, "PERLLIB": true,
+		"RUBYOPT": true, "RUBYLIB": true, "NODE_OPTIONS": true, "NODE_PATH": true,
+		"GCONV_PATH": true, "JAVA_TOOL_OPTIONS": true, "_JAVA_OPTIONS": true,
+	}
+	unsafeEnvPrefixes = []string{"LD_", "DYLD_", "PYTHON", "BASH_FUNC_"}
+)
+
+// Options controls how a command or script is run.
+type Options struct {
+	// Timeout overrides the configured executor.command_timeout when greater than zero.
+	Timeout time.Duration
+	// Env holds variables added to the agent's environment.
+	Env map[string]string
+	// Dir is the working directory. The agent's working directory is used if empty.
+	Dir string
+	// Stdin is passed to the process on standard input.
+	Stdin []byte
+	// Profile names the execution profile from profiles.yml to sandbox the
+	// process with. The process runs unrestricted if empty.
+	Profile string
+
+	// Stdout and Stderr, if set, receive output as it is produced.
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// validate checks the options before anything is started.
+func (o Options) validate() error {
+	if o.Timeout < 0 {
+		return fmt.Errorf("timeout cannot be negative")
+	}
+	for key := range o.Env {
+		if !envKeyPattern.MatchString(key) {
+			return fmt.Errorf("invalid environment variable name: %q", key)
+		}
+		if unsafeEnvKey(key) {
+			return fmt.Errorf("environment variable %s is not allowed", key)
+		}
+	}
+	if o.Dir != "" {
+		if !filepath.IsAbs(o.Dir) {
+			return fmt.Errorf("working directory must be an absolute path: %s", o.Dir)
+		}
+		info, err := os.Stat(o.Dir)
+		if err != nil {
+			return fmt.Errorf("invalid working directory: %w", err)
+		}
+		if !info.IsDir() {
+			return fmt.Errorf("working directory is not a directory: %s", o.Dir)
+		}
+	}
+	return nil
+}
+
+// unsafeEnvKey reports whether a variable can inject code into the process.
+func unsafeEnvKey(key string) bool {
+	key = strings.ToUpper(key)
+	if unsafeEnvKeys[key] {
+		return true
+	}
+	for _, prefix := range unsafeEnvPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// environ returns the environment for the process, or nil to inherit the agent's.
+func (o Options) environ() []string {
+	if len(o.Env) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(o.Env))
+	for key := range o.Env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	env := os.Environ()
+	for _, key := range keys {
+		env = append(env, key+"="+o.Env[key])
+	}
+	return env
+}
+
+// outputBuffer collects up to limit bytes of output. It can be written to from
+// the stdout and stderr copy goroutines at the same time.
+type outputBuffer struct {
+	mu        sync.Mutex
+	buf       bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func newOutputBuffer(limit int) *outputBuffer {
+	return &outputBuffer{limit: limit}
+}
+
+func (b *outputBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if remaining := b.limit - b.buf.Len(); len(p) > remaining {
+		b.truncated = true
+		b.buf.Write(p[:remaining])
+	} else {
+		b.buf.Write(p)
+	}
+	// Report the full length so the process is never blocked by the limit
+	return len(p), nil
+}
+
+func (b *outputBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func (b *outputBuffer) Truncated() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.truncated
+}
+
+// teeWriter returns a writer that writes to the buffer and, if set, to live.
+func teeWriter(buf io.Writer, live io.Writer) io.Writer {
+	if live == nil {
+		return buf
+	}
+	return io.MultiWriter(buf, live)
+}
@@ -0,0 +1,52 @@
+//go:build !windows
+
+package executor
+
+import (
+	"os"
+	"os/exec"
+	"runtime"
+	"syscall"
+	"time"
+)
+
+// setProcessGroup starts the command in its own process group and makes
+// context cancellation kill the whole group, including any children it spawned.
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+}
+
+// exitSignal returns the name of the signal that terminated the process, if any.
+func exitSignal(state *os.ProcessState) string {
+	status, ok := state.Sys().(syscall.WaitStatus)
+	if !ok || !status.Signaled() {
+		return ""
+	}
+	return status.Signal().String()
+}
+
+// processUsage returns the resource usage of the process and the children it waited for.
+func processUsage(state *os.ProcessState) *ResourceUsage {
+	rusage, ok := state.SysUsage().(*syscall.Rusage)
+	if !ok {
+		return nil
+	}
+	peak := uint64(rusage.Maxrss)
+	if runtime.GOOS != "darwin" {
+		// Reported in kilobytes everywhere but on macOS
+		peak *= 1024
+	}
+	return &ResourceUsage{
+		PeakMemoryBytes: peak,
+		CPUTime:         time.Duration(rusage.Utime.Nano() + rusage.Stime.Nano()),
+		Source:          "rusage",
+	}
+}
+
+// processElevated reports whether the agent runs as root.
+func processElevated() bool {
+	return os.Geteuid() == 0
+}
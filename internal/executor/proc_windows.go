@@ -0,0 +1,39 @@
+//go:build windows
+
+package executor
+
+import (
+	"os"
+	"os/exec"
+	"strconv"
+
+	"golang.org/x/sys/windows"
+)
+
+// setProcessGroup makes context cancellation kill the command together with
+// every process it spawned.
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.Cancel = func() error {
+		kill := exec.Command("taskkill", "/T", "/F", "/PID", strconv.Itoa(cmd.Process.Pid))
+		if err := kill.Run(); err != nil {
+			return cmd.Process.Kill()
+		}
+		return nil
+	}
+}
+
+// exitSignal always returns an empty string, as Windows processes are not
+// terminated by signals.
+func exitSignal(state *os.ProcessState) string {
+	return ""
+}
+
+// processUsage returns nil, as resource usage is not measured on Windows.
+func processUsage(state *os.ProcessState) *ResourceUsage {
+	return nil
+}
+
+// processElevated reports whether the agent runs with an elevated token.
+func processElevated() bool {
+	return windows.GetCurrentProcessToken().IsElevated()
+}
@@ -0,0 +1,119 @@
+package executor
+
+import (
+	"errors"
+	"fmt"
+	"openshield-agent/internal/config"
+	"os"
+	"os/user"
+	"strconv"
+)
+
+// Namespaces a profile can isolate a process in.
+const (
+	NamespaceMount   = "mount"
+	NamespaceNetwork = "network"
+	NamespacePID     = "pid"
+)
+
+// sandboxSpec is a resolved execution profile, passed to the sandbox helper.
+type sandboxSpec struct {
+	Profile    string                `json:"profile"`
+	SetIDs     bool                  `json:"set_ids"`
+	UID        int                   `json:"uid"`
+	GID        int                   `json:"gid"`
+	Limits     config.ResourceLimits `json:"limits"`
+	Mount      bool                  `json:"mount"`
+	Network    bool                  `json:"network"`
+	PID        bool                  `json:"pid"`
+	NoNewPrivs bool                  `json:"no_new_privs"`
+}
+
+// ValidateProfilesConfig checks the contents of a profiles.yml file without applying it.
+func ValidateProfilesConfig(data []byte) error {
+	cfg, err := config.ParseProfilesConfig(data)
+	if err != nil {
+		return err
+	}
+	seen := make(map[string]bool)
+	for _, p := range cfg.Profiles {
+		if p.Name == "" {
+			return errors.New("profile without a name")
+		}
+		if seen[p.Name] {
+			return fmt.Errorf("duplicate profile %s", p.Name)
+		}
+		seen[p.Name] = true
+		if _, err := resolveProfile(p, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lookupProfile loads the named profile from profiles.yml and resolves it.
+func lookupProfile(name string) (*sandboxSpec, error) {
+	cfg, err := config.LoadProfilesConfig(config.ConfigPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("execution profile %s not found: no %s", name, config.ProfilesFilename)
+		}
+		return nil, fmt.Errorf("failed to load execution profiles: %w", err)
+	}
+	for _, p := range cfg.Profiles {
+		if p.Name == name {
+			return resolveProfile(p, true)
+		}
+	}
+	return nil, fmt.Errorf("execution profile %s not found", name)
+}
+
+// resolveProfile checks the profile and turns it into a sandbox spec. User
+// names are only looked up when lookupUser is set, as a pushed profile may
+// refer to a user that is created later.
+func resolveProfile(p config.ExecutionProfile, lookupUser bool) (*sandboxSpec, error) {
+	spec := &sandboxSpec{
+		Profile:    p.Name,
+		Limits:     p.Limits,
+		NoNewPrivs: p.NoNewPrivs,
+	}
+
+	if p.User != "" && (p.UID != nil || p.GID != nil) {
+		return nil, fmt.Errorf("profile %s: set either user or uid/gid", p.Name)
+	}
+	if (p.UID == nil) != (p.GID == nil) {
+		return nil, fmt.Errorf("profile %s: uid and gid must be set together", p.Name)
+	}
+	if p.UID != nil {
+		if *p.UID < 0 || *p.GID < 0 {
+			return nil, fmt.Errorf("profile %s: uid and gid cannot be negative", p.Name)
+		}
+		spec.SetIDs, spec.UID, spec.GID = true, *p.UID, *p.GID
+	}
+	if p.User != "" && lookupUser {
+		u, err := user.Lookup(p.User)
+		if err != nil {
+			return nil, fmt.Errorf("profile %s: %w", p.Name, err)
+		}
+		uid, err1 := strconv.Atoi(u.Uid)
+		gid, err2 := strconv.Atoi(u.Gid)
+		if err1 != nil || err2 != nil {
+			return nil, fmt.Errorf("profile %s: user %s has non-numeric IDs", p.Name, p.User)
+		}
+		spec.SetIDs, spec.UID, spec.GID = true, uid, gid
+	}
+
+	for _, ns := range p.Namespaces {
+		switch ns {
+		case NamespaceMount:
+			spec.Mount = true
+		case NamespaceNetwork:
+			spec.Network = true
+		case NamespacePID:
+			spec.PID = true
+		default:
+			return nil, fmt.Errorf("profile %s: unknown namespace %s", p.Name, ns)
+		}
+	}
+	return spec, nil
+}
@@ -0,0 +1,38 @@
+package executor
+
+import "time"
+
+// maxOutputSize is the maximum number of bytes kept for each of stdout,
+// stderr and the combined output. Anything beyond it is discarded and the
+// result is marked as truncated.
+const maxOutputSize = 1024 * 1024
+
+// Result describes a command or script that was started.
+type Result struct {
+	ExitCode  int       `json:"exit_code"`
+	Stdout    string    `json:"stdout,omitempty"`
+	Stderr    string    `json:"stderr,omitempty"`
+	Output    string    `json:"-"`                // stdout and stderr interleaved
+	Signal    string    `json:"signal,omitempty"` // set if a signal terminated the process
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+	Truncated bool      `json:"truncated,omitempty"`
+
+	Resources *ResourceUsage `json:"resources,omitempty"` // nil if it could not be measured
+}
+
+// ResourceUsage is what a command and its children consumed.
+type ResourceUsage struct {
+	PeakMemoryBytes uint64        `json:"peak_memory_bytes"`
+	CPUTime         time.Duration `json:"cpu_time"`
+	OOMKilled       bool          `json:"oom_killed,omitempty"`
+	// Source is "cgroup" when measured from the job's cgroup, or "rusage" when
+	// taken from the process's resource usage, which misses orphaned children
+	// and cannot detect OOM kills.
+	Source string `json:"source"`
+}
+
+// Duration returns how long the process ran.
+func (r *Result) Duration() time.Duration {
+	return r.EndTime.Sub(r.StartTime)
+}
@@ -0,0 +1,138 @@
+//go:build linux
+
+package executor
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// sandboxHelperArg marks an invocation of the agent binary as the sandbox helper.
+const sandboxHelperArg = "__openshield-sandbox"
+
+// applySandbox rewrites cmd so it is started through the sandbox helper, which
+// applies the spec and then executes the original command. It must be called
+// after setProcessGroup.
+func applySandbox(cmd *exec.Cmd, spec *sandboxSpec) error {
+	self, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate agent binary for sandboxing: %w", err)
+	}
+	encoded, err := json.Marshal(spec)
+	if err != nil {
+		return err
+	}
+
+	// cmd.Path is already resolved against the agent's PATH
+	cmd.Args = append([]string{self, sandboxHelperArg, string(encoded), cmd.Path}, cmd.Args[1:]...)
+	cmd.Path = self
+
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	if spec.Mount {
+		cmd.SysProcAttr.Cloneflags |= syscall.CLONE_NEWNS
+	}
+	if spec.Network {
+		cmd.SysProcAttr.Cloneflags |= syscall.CLONE_NEWNET
+	}
+	if spec.PID {
+		cmd.SysProcAttr.Cloneflags |= syscall.CLONE_NEWPID
+	}
+	return nil
+}
+
+// RunSandboxHelper turns this process into the sandbox helper if it was started
+// as one, and never returns in that case. It must be called at the very start
+// of main, before any other work is done.
+func RunSandboxHelper() {
+	if len(os.Args) < 4 || os.Args[1] != sandboxHelperArg {
+		return
+	}
+	// no_new_privs is a thread attribute, so it must be set on the thread
+	// that executes the command
+	runtime.LockOSThread()
+
+	var spec sandboxSpec
+	if err := json.Unmarshal([]byte(os.Args[2]), &spec); err != nil {
+		sandboxFatal("invalid sandbox spec: %v", err)
+	}
+	if err := enterSandbox(&spec); err != nil {
+		sandboxFatal("%v", err)
+	}
+
+	argv := os.Args[3:]
+	if err := syscall.Exec(argv[0], argv, os.Environ()); err != nil {
+		sandboxFatal("failed to execute %s: %v", argv[0], err)
+	}
+}
+
+// enterSandbox applies the spec to the current process. Privileged steps run
+// first, since they are no longer possible once the IDs have been dropped.
+func enterSandbox(spec *sandboxSpec) error {
+	if spec.Mount {
+		// Keep mounts made inside the sandbox from propagating to the host
+		if err := unix.Mount("", "/", "", unix.MS_REC|unix.MS_PRIVATE, ""); err != nil {
+			return fmt.Errorf("failed to make mounts private: %w", err)
+		}
+		if spec.PID {
+			// Show only the sandbox's own processes in /proc
+			if err := unix.Mount("proc", "/proc", "proc", unix.MS_NOSUID|unix.MS_NODEV|unix.MS_NOEXEC, ""); err != nil {
+				return fmt.Errorf("failed to mount /proc: %w", err)
+			}
+		}
+	}
+
+	limits := []struct {
+		resource int
+		value    uint64
+		name     string
+	}{
+		{unix.RLIMIT_CPU, spec.Limits.CPUSeconds, "cpu"},
+		{unix.RLIMIT_AS, spec.Limits.MemoryBytes, "memory"},
+		{unix.RLIMIT_NOFILE, spec.Limits.OpenFiles, "open files"},
+		{unix.RLIMIT_NPROC, spec.Limits.Processes, "processes"},
+	}
+	for _, l := range limits {
+		if l.value == 0 {
+			continue
+		}
+		if err := unix.Setrlimit(l.resource, &unix.Rlimit{Cur: l.value, Max: l.value}); err != nil {
+			return fmt.Errorf("failed to set %s limit: %w", l.name, err)
+		}
+	}
+
+	if spec.SetIDs {
+		// syscall's Setgroups, Setgid and Setuid apply to all threads of the
+		// process, unix.Setgroups only to the calling one
+		if err := syscall.Setgroups(nil); err != nil {
+			return fmt.Errorf("failed to drop supplementary groups: %w", err)
+		}
+		if err := syscall.Setgid(spec.GID); err != nil {
+			return fmt.Errorf("failed to set gid %d: %w", spec.GID, err)
+		}
+		if err := syscall.Setuid(spec.UID); err != nil {
+			return fmt.Errorf("failed to set uid %d: %w", spec.UID, err)
+		}
+	}
+
+	if spec.NoNewPrivs {
+		if err := unix.Prctl(unix.PR_SET_NO_NEW_PRIVS, 1, 0, 0, 0); err != nil {
+			return fmt.Errorf("failed to set no_new_privs: %w", err)
+		}
+	}
+	return nil
+}
+
+// sandboxFatal reports a sandbox setup failure on stderr, where it ends up in
+// the job's output, and exits with a distinctive code.
+func sandboxFatal(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, "openshield sandbox: "+format+"\n", args...)
+	os.Exit(126)
+}
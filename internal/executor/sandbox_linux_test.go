@@ -0,0 +1,49 @@
+//go:build linux
+
+package executor
+
+import (
+	"context"
+	"openshield-agent/internal/config"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestMain lets the test binary act as the sandbox helper, like the agent binary does.
+func TestMain(m *testing.M) {
+	RunSandboxHelper()
+	os.Exit(m.Run())
+}
+
+func TestRunWithProfile(t *testing.T) {
+	oldPath := config.ConfigPath
+	config.ConfigPath = t.TempDir()
+	defer func() { config.ConfigPath = oldPath }()
+
+	profiles := []byte(`profiles:
+  - name: restricted
+    limits:
+      open_files: 64
+    no_new_privs: true
+`)
+	if err := ValidateProfilesConfig(profiles); err != nil {
+		t.Fatalf("ValidateProfilesConfig returned error: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(config.ConfigPath, config.ProfilesFilename), profiles, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := Run(context.Background(), Options{Profile: "restricted"}, "/bin/sh", "-c", "ulimit -n; grep NoNewPrivs /proc/self/status")
+	if err != nil {
+		t.Fatalf("Run returned error: %v (output: %v)", err, res)
+	}
+	if !strings.HasPrefix(res.Stdout, "64\n") || !strings.Contains(res.Stdout, "NoNewPrivs:\t1") {
+		t.Errorf("Expected the profile to be applied, got %q", res.Stdout)
+	}
+
+	if _, err := Run(context.Background(), Options{Profile: "missing"}, "/bin/true"); err == nil {
+		t.Error("Expected an unknown profile to be rejected")
+	}
+}
@@ -0,0 +1,16 @@
+//go:build !linux
+
+package executor
+
+import (
+	"errors"
+	"os/exec"
+)
+
+// applySandbox fails, as execution profiles are only supported on Linux.
+func applySandbox(cmd *exec.Cmd, spec *sandboxSpec) error {
+	return errors.New("execution profiles are only supported on Linux")
+}
+
+// RunSandboxHelper does nothing, as execution profiles are only supported on Linux.
+func RunSandboxHelper() {}
@@ -1,23 +1,79 @@
 package executor
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"openshield-agent/internal/config"
-	"regexp"
+	"openshield-agent/internal/scripts"
 	"runtime"
 )
 
-func ExecuteScript(scriptName string, args []string) (string, error) {
-	allowed := regexp.MustCompile(`^[a-zA-Z0-9_\-]+\.(sh|ps.*)$`)
-	if !allowed.MatchString(scriptName) {
-		return "", fmt.Errorf("invalid script name")
+// ExecuteScript runs a script from the scripts directory with the given arguments.
+// The name is either a script file or a script package, which runs its
+// entrypoint in the package directory unless opts sets another one.
+// Only scripts that match their entry in the signed manifest are run, and the
+// job must satisfy the script's metadata. The metadata's timeout applies if
+// opts has none. Scripts that require root run through the privilege helper
+// unless the agent is root.
+// Cancelling ctx kills the script and any processes it started. It has the
+// same result semantics as ExecuteCommand.
+func ExecuteScript(ctx context.Context, scriptName string, args []string, opts Options) (*Result, error) {
+	script, err := scripts.Lookup(scriptName)
+	if err != nil {
+		return nil, err
 	}
 
-	scriptPath := fmt.Sprintf("%s/%s", config.ScriptsPath, scriptName)
-	// Detect OS and choose shell accordingly
-	if runtime.GOOS == "windows" {
-		psArgs := append([]string{"-ExecutionPolicy", "Bypass", "-File", scriptPath}, args...)
-		return runCommand("powershell", psArgs...)
+	meta := script.Metadata
+	if !meta.SupportsOS(runtime.GOOS) {
+		return nil, fmt.Errorf("script %s does not support %s", scriptName, runtime.GOOS)
 	}
-	return runCommand("/bin/bash", append([]string{scriptPath}, args...)...)
+	if err := meta.ValidateArgs(args); err != nil {
+		return nil, fmt.Errorf("script %s: %w", scriptName, err)
+	}
+	if opts.Timeout == 0 {
+		opts.Timeout, _ = meta.TimeoutDuration()
+	}
+	if opts.Dir == "" {
+		opts.Dir = script.Dir
+	}
+
+	command, cmdArgs := interpreterCommand(meta.Interpreter, script.Path)
+	if meta.Privileges == scripts.PrivilegesRoot {
+		res, err := RunElevated(ctx, opts, command, append(cmdArgs, args...)...)
+		if errors.Is(err, ErrInsufficientPrivileges) {
+			return res, fmt.Errorf("script %s requires root privileges: %w", scriptName, err)
+		}
+		return res, err
+	}
+	return Run(ctx, opts, command, append(cmdArgs, args...)...)
+}
+
+// interpreterCommand returns the command that runs the script at path with
+// the interpreter. Without one, the OS's default shell is used.
+func interpreterCommand(interpreter, path string) (string, []string) {
+	windows := runtime.GOOS == "windows"
+	switch interpreter {
+	case scripts.Bash:
+		if windows {
+			return "bash", []string{path}
+		}
+		return "/bin/bash", []string{path}
+	case scripts.Sh:
+		if windows {
+			return "sh", []string{path}
+		}
+		return "/bin/sh", []string{path}
+	case scripts.Python3:
+		if windows {
+			return "python", []string{path}
+		}
+		return "python3", []string{path}
+	case scripts.Pwsh:
+		return "pwsh", []string{"-NoProfile", "-NonInteractive", "-ExecutionPolicy", "Bypass", "-File", path}
+	}
+
+	if windows {
+		return "powershell", []string{"-ExecutionPolicy", "Bypass", "-File", path}
+	}
+	return "/bin/bash", []string{path}
 }
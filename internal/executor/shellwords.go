@@ -0,0 +1,78 @@
+package executor
+
+import (
+	"errors"
+	"strings"
+)
+
+// SplitCommandLine splits a command line into words the way a POSIX shell
+// would, without performing any expansion or running a shell. Words are
+// separated by unquoted whitespace. Single quotes preserve everything
+// literally, double quotes allow \" \\ \$ and \` escapes, and a backslash
+// outside quotes escapes the next character.
+func SplitCommandLine(line string) ([]string, error) {
+	var (
+		words  []string
+		word   strings.Builder
+		inWord bool
+	)
+	runes := []rune(line)
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch r {
+		case ' ', '\t', '\n', '\r':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+			continue
+		case '\\':
+			i++
+			if i == len(runes) {
+				return nil, errors.New("command line ends with an unfinished escape")
+			}
+			word.WriteRune(runes[i])
+		case '\'':
+			end := indexRune(runes, i+1, '\'')
+			if end < 0 {
+				return nil, errors.New("command line has an unterminated single quote")
+			}
+			word.WriteString(string(runes[i+1 : end]))
+			i = end
+		case '"':
+			i++
+			for ; i < len(runes) && runes[i] != '"'; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) && strings.ContainsRune("\"\\$`", runes[i+1]) {
+					i++
+				}
+				word.WriteRune(runes[i])
+			}
+			if i == len(runes) {
+				return nil, errors.New("command line has an unterminated double quote")
+			}
+		default:
+			word.WriteRune(r)
+		}
+		inWord = true
+	}
+
+	if inWord {
+		words = append(words, word.String())
+	}
+	if len(words) == 0 {
+		return nil, errors.New("command line is empty")
+	}
+	return words, nil
+}
+
+// indexRune returns the index of the first r in runes at or after start, or -1.
+func indexRune(runes []rune, start int, r rune) int {
+	for i := start; i < len(runes); i++ {
+		if runes[i] == r {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,36 @@
+package executor
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitCommandLine(t *testing.T) {
+	cases := []struct {
+		line string
+		want []string
+	}{
+		{"uptime", []string{"uptime"}},
+		{"  ping   -c 4\texample.com ", []string{"ping", "-c", "4", "example.com"}},
+		{`echo 'hello world' "it's" ''`, []string{"echo", "hello world", "it's", ""}},
+		{`echo "a \"quoted\" \$HOME \n"`, []string{"echo", `a "quoted" $HOME \n`}},
+		{`echo foo\ bar a'b'"c"`, []string{"echo", "foo bar", "abc"}},
+		{`grep 'a|b' ; rm`, []string{"grep", "a|b", ";", "rm"}},
+	}
+	for _, c := range cases {
+		got, err := SplitCommandLine(c.line)
+		if err != nil {
+			t.Errorf("SplitCommandLine(%q) returned error: %v", c.line, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("SplitCommandLine(%q) = %q, want %q", c.line, got, c.want)
+		}
+	}
+
+	for _, line := range []string{"", "   ", `echo "unterminated`, `echo 'unterminated`, `echo trailing\`} {
+		if _, err := SplitCommandLine(line); err == nil {
+			t.Errorf("Expected SplitCommandLine(%q) to fail", line)
+		}
+	}
+}
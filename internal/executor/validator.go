@@ -1,38 +1,243 @@
 package executor
 
 import (
+	"errors"
+	"fmt"
+	"log"
+	"openshield-agent/internal/config"
 	"openshield-agent/internal/models"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sync"
+	"time"
 )
 
-// CommandWhitelist is a list of whitelisted commands that can be executed by the agent.
-var CommandWhitelist = []models.Command{
-	{Command: "uptime", TargetOS: models.OSLinux},
-	{Command: "df", TargetOS: models.OSLinux},
-	{Command: "tasklist", TargetOS: models.OSWindows},
-	{Command: "whoami", TargetOS: models.OSLinux},
-	{Command: "timeout", TargetOS: models.OSWindows},
-	{Command: "ping", TargetOS: models.OSWindows},
-	{Command: "ping", TargetOS: models.OSLinux},
+const (
+	hostPattern   = `^[A-Za-z0-9][A-Za-z0-9.\-:]*$`
+	numberPattern = `^[0-9]{1,3}$`
+)
+
+// DefaultCommandRules is the allow-list used when the config directory has no commands.yml.
+var DefaultCommandRules = []config.CommandRule{
+	{Command: "uptime", OS: models.OSLinux},
+	{Command: "df", OS: models.OSLinux, Args: []config.ArgRule{
+		{Values: []string{"-h", "-T", "-i"}},
+	}},
+	{Command: "tasklist", OS: models.OSWindows},
+	{Command: "whoami", OS: models.OSLinux},
+	{Command: "timeout", OS: models.OSWindows, MaxArgs: 3, Args: []config.ArgRule{
+		{Values: []string{"/t", "/nobreak"}},
+		{Pattern: numberPattern, Max: 1},
+	}},
+	{Command: "ping", OS: models.OSWindows, MaxArgs: 3, Args: []config.ArgRule{
+		{Values: []string{"-n"}, Max: 1},
+		{Pattern: numberPattern, Max: 1},
+		{Pattern: hostPattern, Max: 1},
+	}},
+	{Command: "ping", OS: models.OSLinux, MaxArgs: 3, Args: []config.ArgRule{
+		{Values: []string{"-c"}, Max: 1},
+		{Pattern: numberPattern, Max: 1},
+		{Pattern: hostPattern, Max: 1},
+	}},
 }
 
-// IsCommandWhitelisted checks if the command is in the whitelist.
-func IsCommandWhitelisted(cmd models.Command) bool {
-	for _, allowed := range CommandWhitelist {
-		if cmd.Command == allowed.Command &&
-			cmd.TargetOS == allowed.TargetOS {
-			return true
+// allowedCommand is a compiled CommandRule.
+type allowedCommand struct {
+	config.CommandRule
+	patterns []*regexp.Regexp
+}
+
+// allowList is the compiled command allow-list and the state of the file it came from.
+type allowList struct {
+	commands []allowedCommand
+	modTime  time.Time
+	size     int64
+}
+
+var (
+	allowListMu     sync.Mutex
+	activeAllowList *allowList
+)
+
+// compileAllowList validates the rules and compiles their patterns.
+func compileAllowList(rules []config.CommandRule) (*allowList, error) {
+	list := &allowList{}
+	for _, rule := range rules {
+		if rule.Command == "" {
+			return nil, errors.New("command rule without a command")
+		}
+		if rule.OS == "" {
+			return nil, fmt.Errorf("command %s has no os", rule.Command)
+		}
+		if rule.Path != "" && !filepath.IsAbs(rule.Path) {
+			return nil, fmt.Errorf("command %s: path must be absolute: %s", rule.Command, rule.Path)
 		}
+
+		cmd := allowedCommand{CommandRule: rule, patterns: make([]*regexp.Regexp, len(rule.Args))}
+		for i, arg := range rule.Args {
+			if len(arg.Values) == 0 && arg.Pattern == "" {
+				return nil, fmt.Errorf("command %s: argument rule %d has neither values nor a pattern", rule.Command, i)
+			}
+			if arg.Pattern == "" {
+				continue
+			}
+			// Patterns must match the whole argument. Compiling the pattern
+			// on its own first keeps it from closing the group that anchors it
+			if _, err := regexp.Compile(arg.Pattern); err != nil {
+				return nil, fmt.Errorf("command %s: invalid pattern %q: %w", rule.Command, arg.Pattern, err)
+			}
+			cmd.patterns[i] = regexp.MustCompile(`^(?:` + arg.Pattern + `)$`)
+		}
+		list.commands = append(list.commands, cmd)
 	}
-	return false
+	return list, nil
 }
 
-// IsValidForCurrentOS checks if the command is valid for the current OS.
-func IsValidForCurrentOS(cmd models.Command) bool {
-	// Check if the command is whitelisted for the current OS
-	for _, allowed := range CommandWhitelist {
-		if cmd.Command == allowed.Command && cmd.TargetOS == allowed.TargetOS {
+// ValidateCommandsConfig checks the contents of a commands.yml file without applying it.
+func ValidateCommandsConfig(data []byte) error {
+	cfg, err := config.ParseCommandsConfig(data)
+	if err != nil {
+		return err
+	}
+	_, err = compileAllowList(cfg.Commands)
+	return err
+}
+
+// ReloadAllowList forces the allow-list to be read again from the config directory.
+func ReloadAllowList() error {
+	allowListMu.Lock()
+	defer allowListMu.Unlock()
+
+	activeAllowList = nil
+	_, err := loadAllowList()
+	return err
+}
+
+// currentAllowList returns the active allow-list, reloading it if commands.yml changed.
+func currentAllowList() *allowList {
+	allowListMu.Lock()
+	defer allowListMu.Unlock()
+
+	list, err := loadAllowList()
+	if err != nil {
+		log.Printf("[EXECUTOR] Failed to load %s, keeping the previous allow-list: %v", config.CommandsFilename, err)
+	}
+	return list
+}
+
+// loadAllowList (re)loads commands.yml if it changed since it was last read.
+// If the file is invalid the previous allow-list stays active. Callers must hold allowListMu.
+func loadAllowList() (*allowList, error) {
+	path := filepath.Join(config.ConfigPath, config.CommandsFilename)
+	info, err := os.Stat(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return fallbackAllowList(), err
+		}
+		// No file, use the built-in defaults
+		if activeAllowList == nil || !activeAllowList.modTime.IsZero() {
+			activeAllowList, _ = compileAllowList(DefaultCommandRules)
+		}
+		return activeAllowList, nil
+	}
+
+	if activeAllowList != nil && activeAllowList.modTime.Equal(info.ModTime()) && activeAllowList.size == info.Size() {
+		return activeAllowList, nil
+	}
+
+	cfg, err := config.LoadCommandsConfig(config.ConfigPath)
+	if err != nil {
+		return fallbackAllowList(), err
+	}
+	list, err := compileAllowList(cfg.Commands)
+	if err != nil {
+		return fallbackAllowList(), err
+	}
+	list.modTime = info.ModTime()
+	list.size = info.Size()
+	activeAllowList = list
+	log.Printf("[EXECUTOR] Loaded %d allowed commands from %s", len(list.commands), path)
+	return activeAllowList, nil
+}
+
+// fallbackAllowList returns the active allow-list, or an empty one if none was loaded yet.
+// Callers must hold allowListMu.
+func fallbackAllowList() *allowList {
+	if activeAllowList == nil {
+		return &allowList{}
+	}
+	return activeAllowList
+}
+
+// checkArgs verifies the arguments against the command's argument rules.
+func (c *allowedCommand) checkArgs(args []string) error {
+	if c.MaxArgs > 0 && len(args) > c.MaxArgs {
+		return fmt.Errorf("%s accepts at most %d arguments", c.Command, c.MaxArgs)
+	}
+
+	counts := make([]int, len(c.Args))
+	for _, arg := range args {
+		matched := false
+		for i, rule := range c.Args {
+			if rule.Max > 0 && counts[i] >= rule.Max {
+				continue
+			}
+			if c.matches(i, arg) {
+				counts[i]++
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return fmt.Errorf("argument %q not allowed for %s", arg, c.Command)
+		}
+	}
+	return nil
+}
+
+// matches reports whether the argument matches the i-th argument rule.
+func (c *allowedCommand) matches(i int, arg string) bool {
+	for _, v := range c.Args[i].Values {
+		if arg == v {
 			return true
 		}
 	}
-	return false
+	return c.patterns[i] != nil && c.patterns[i].MatchString(arg)
+}
+
+// ResolveCommand checks the command against the allow-list and returns the
+// binary to execute.
+func ResolveCommand(cmd models.Command) (string, error) {
+	var argErr error
+	for _, allowed := range currentAllowList().commands {
+		if cmd.Command != allowed.Command || cmd.TargetOS != allowed.OS {
+			continue
+		}
+		if err := allowed.checkArgs(cmd.Args); err != nil {
+			// Another rule for the same command may still accept the arguments
+			argErr = err
+			continue
+		}
+		if allowed.Path != "" {
+			return allowed.Path, nil
+		}
+		return allowed.Command, nil
+	}
+	if argErr != nil {
+		return "", argErr
+	}
+	return "", errors.New("command not whitelisted")
+}
+
+// IsCommandWhitelisted checks if the command and its arguments are allowed.
+func IsCommandWhitelisted(cmd models.Command) bool {
+	_, err := ResolveCommand(cmd)
+	return err == nil
+}
+
+// IsValidForCurrentOS checks if the command targets the current OS.
+func IsValidForCurrentOS(cmd models.Command) bool {
+	return cmd.TargetOS == runtime.GOOS
 }
@@ -1,7 +1,10 @@
 package executor
 
 import (
+	"openshield-agent/internal/config"
 	"openshield-agent/internal/models"
+	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -16,3 +19,88 @@ func TestIsCommandWhitelisted(t *testing.T) {
 		t.Error("Expected notallowed to NOT be whitelisted")
 	}
 }
+
+func TestCommandArgumentRules(t *testing.T) {
+	ping := func(args ...string) models.Command {
+		return models.Command{Command: "ping", Args: args, TargetOS: models.OSLinux}
+	}
+
+	if !IsCommandWhitelisted(ping("-c", "4", "example.com")) {
+		t.Error("Expected ping with a count and host to be whitelisted")
+	}
+	if IsCommandWhitelisted(ping("-f", "example.com")) {
+		t.Error("Expected ping with an unknown flag to be rejected")
+	}
+	if IsCommandWhitelisted(ping("-c", "4", "a.com", "b.com")) {
+		t.Error("Expected ping with too many arguments to be rejected")
+	}
+	if IsCommandWhitelisted(models.Command{Command: "uptime", Args: []string{"-p"}, TargetOS: models.OSLinux}) {
+		t.Error("Expected arguments to be rejected for a command without argument rules")
+	}
+}
+
+func TestAllowListFromConfig(t *testing.T) {
+	oldPath := config.ConfigPath
+	config.ConfigPath = t.TempDir()
+	defer func() {
+		config.ConfigPath = oldPath
+		ReloadAllowList()
+	}()
+
+	data := []byte(`commands:
+  - command: echo
+    os: linux
+    path: /bin/echo
+    args:
+      - pattern: '^[a-z]+$'
+        max: 2
+`)
+	if err := ValidateCommandsConfig(data); err != nil {
+		t.Fatalf("ValidateCommandsConfig returned error: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(config.ConfigPath, config.CommandsFilename), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ReloadAllowList(); err != nil {
+		t.Fatalf("ReloadAllowList returned error: %v", err)
+	}
+
+	binary, err := ResolveCommand(models.Command{Command: "echo", Args: []string{"hi", "there"}, TargetOS: models.OSLinux})
+	if err != nil || binary != "/bin/echo" {
+		t.Errorf("Expected echo to resolve to /bin/echo, got %q, %v", binary, err)
+	}
+	if IsCommandWhitelisted(models.Command{Command: "echo", Args: []string{"a", "b", "c"}, TargetOS: models.OSLinux}) {
+		t.Error("Expected the per-rule max count to be enforced")
+	}
+	if IsCommandWhitelisted(models.Command{Command: "uptime", TargetOS: models.OSLinux}) {
+		t.Error("Expected the config file to replace the default allow-list")
+	}
+
+	// Patterns must match the whole argument, not just part of it
+	data = []byte(`commands:
+  - command: head
+    os: linux
+    path: /usr/bin/head
+    args:
+      - values: ["-n"]
+      - pattern: '[0-9]+'
+`)
+	if err := os.WriteFile(filepath.Join(config.ConfigPath, config.CommandsFilename), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ReloadAllowList(); err != nil {
+		t.Fatalf("ReloadAllowList returned error: %v", err)
+	}
+	if !IsCommandWhitelisted(models.Command{Command: "head", Args: []string{"-n", "10"}, TargetOS: models.OSLinux}) {
+		t.Error("Expected a fully matching argument to be allowed")
+	}
+	for _, arg := range []string{"-f1", "5;x", "--output=/etc/x1"} {
+		if IsCommandWhitelisted(models.Command{Command: "head", Args: []string{arg}, TargetOS: models.OSLinux}) {
+			t.Errorf("Expected %q to be rejected by a partial pattern match", arg)
+		}
+	}
+
+	if err := ValidateCommandsConfig([]byte("commands:\n  - command: ls\n    os: linux\n    path: ls\n")); err == nil {
+		t.Error("Expected a relative binary path to be rejected")
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"log"
 	"openshield-agent/internal/config"
+	"openshield-agent/internal/services"
 	"openshield-agent/internal/utils"
 	"openshield-agent/proto"
 	"os"
@@ -39,10 +40,10 @@ func DeleteAgentCredentials() error {
 
 // UnregisterAgentAsk handles the UnregisterAgentAsk RPC and deletes agent credentials.
 func (s *AgentServer) UnregisterAgentAsk(ctx context.Context, req *emptypb.Empty) (*emptypb.Empty, error) {
-	config := config.GlobalConfig
+	config := config.Get()
 
 	// Unregister the agent
-	client, err := NewManagerClient(config.MANAGER_ADDRESS)
+	client, err := NewManagerClient(config.Manager.Address)
 	if err != nil {
 		log.Print("[AGENT] Could not create client for manager")
 		return nil, err
@@ -141,19 +142,17 @@ func (c *ManagerClient) Heartbeat(ctx context.Context) (bool, error) {
 		return false, err
 	}
 
-	// Collect all services
-	services, err := utils.GetAllServices()
-	if err != nil {
-		return false, err
-	}
-
 	// Prepare a JSON message
 	msg := map[string]interface{}{
 		"id":        creds.AgentID,
 		"addresses": addresses,
-		"services":  services,
 		"os":        utils.GetDeviceOS(),
 	}
+	// The service inventory is collected in the background, so a slow init
+	// system cannot fail the heartbeat. It is left out until it is collected.
+	if inventory, ok := services.Inventory(); ok {
+		msg["services"] = inventory
+	}
 	jsonMsg, err := json.Marshal(msg)
 	if err != nil {
 		return false, err
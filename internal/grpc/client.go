@@ -3,9 +3,11 @@ package agentgrpc
 import (
 	"context"
 	"log"
+	"net"
 	"openshield-agent/internal/config"
 	"openshield-agent/internal/utils"
 	"openshield-agent/proto"
+	"strconv"
 	"time"
 
 	"google.golang.org/grpc"
@@ -21,7 +23,7 @@ type ManagerClient struct {
 
 func NewRegistrationClient(managerAddress string) (*ManagerClient, error) {
 	conn, err := grpc.NewClient(
-		managerAddress+":"+config.GlobalConfig.MANAGER_REGISTER_PORT,
+		net.JoinHostPort(managerAddress, strconv.Itoa(config.Get().Manager.RegisterPort)),
 		grpc.WithTransportCredentials(insecure.NewCredentials()), // Use TLS in production
 	)
 	if err != nil {
@@ -49,7 +51,7 @@ func NewManagerClient(managerAddress string) (*ManagerClient, error) {
 	if err != nil {
 		// Create a client without credentials only for registration
 		conn, err := grpc.NewClient(
-			managerAddress+":"+config.GlobalConfig.MANAGER_GRPC_PORT,
+			net.JoinHostPort(managerAddress, strconv.Itoa(config.Get().Manager.GRPCPort)),
 			grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)), // Use TLS in production
 		)
 		if err != nil {
@@ -75,7 +77,7 @@ func NewManagerClient(managerAddress string) (*ManagerClient, error) {
 	}
 
 	conn, err := grpc.NewClient(
-		managerAddress+":"+config.GlobalConfig.MANAGER_GRPC_PORT,
+		net.JoinHostPort(managerAddress, strconv.Itoa(config.Get().Manager.GRPCPort)),
 		grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)), // Use TLS in production
 		WithAgentToken(creds.AgentToken),                             // Inject the agent token
 	)
@@ -4,17 +4,42 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
+	"fmt"
 	"log"
 	"os"
 	"path/filepath"
 
 	"openshield-agent/internal/config"
+	"openshield-agent/internal/executor"
+	"openshield-agent/internal/tools"
+	"openshield-agent/internal/uploads"
 	"openshield-agent/proto"
 
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
 )
 
+// configValidators check pushed config files that have a known format.
+var configValidators = map[string]func([]byte) error{
+	config.ConfigFilename:   config.ValidateConfig,
+	config.CommandsFilename: executor.ValidateCommandsConfig,
+	config.ProfilesFilename: executor.ValidateProfilesConfig,
+	config.ToolsFilename:    tools.ValidateToolsConfig,
+}
+
 func (s *AgentServer) GetConfigChecksums(ctx context.Context, _ *emptypb.Empty) (*proto.ChecksumResponse, error) {
+	checksums, err := configChecksums()
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("[CONFIG SYNC] Returning checksums for %d configs", len(checksums))
+	return &proto.ChecksumResponse{Files: checksums}, nil
+}
+
+// configChecksums returns the checksum of every file in the config directory.
+func configChecksums() ([]*proto.Checksum, error) {
 	files, err := os.ReadDir(config.ConfigPath)
 	if err != nil {
 		log.Printf("[CONFIG SYNC] Failed to read configs directory: %v", err)
@@ -38,18 +63,74 @@ func (s *AgentServer) GetConfigChecksums(ctx context.Context, _ *emptypb.Empty)
 			Checksum: hex.EncodeToString(checksum[:]),
 		})
 	}
-
-	log.Printf("[CONFIG SYNC] Returning checksums for %d configs", len(checksums))
-	return &proto.ChecksumResponse{Files: checksums}, nil
+	return checksums, nil
 }
 
 func (s *AgentServer) SendConfigFile(ctx context.Context, file *proto.FileContent) (*proto.SyncStatus, error) {
+	if !validUploadName(proto.FileKind_CONFIG, file.Filename) {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid file name %q", file.Filename)
+	}
 	path := filepath.Join(config.ConfigPath, file.Filename)
+
+	// Reject an invalid file before it replaces the working one
+	if validate, ok := configValidators[file.Filename]; ok {
+		if err := validate(file.Content); err != nil {
+			log.Printf("[CONFIG SYNC] Rejected invalid config file %s: %v", file.Filename, err)
+			return &proto.SyncStatus{Success: false, Message: fmt.Sprintf("invalid %s: %v", file.Filename, err)}, nil
+		}
+	}
+
 	err := os.WriteFile(path, file.Content, 0755)
 	if err != nil {
 		log.Printf("[CONFIG SYNC] Failed to write config file %s: %v", path, err)
 		return &proto.SyncStatus{Success: false, Message: err.Error()}, nil
 	}
 	log.Printf("[CONFIG SYNC] Config file updated: %s", path)
+
+	if err := configUpdated(file.Filename); err != nil {
+		return &proto.SyncStatus{Success: false, Message: err.Error()}, nil
+	}
 	return &proto.SyncStatus{Success: true, Message: "File updated successfully"}, nil
 }
+
+// installConfigFile validates a config file received into src and moves it
+// in place of the working one.
+func installConfigFile(name, src string) error {
+	if validate, ok := configValidators[name]; ok {
+		content, err := os.ReadFile(src)
+		if err != nil {
+			return err
+		}
+		if err := validate(content); err != nil {
+			log.Printf("[CONFIG SYNC] Rejected invalid config file %s: %v", name, err)
+			return fmt.Errorf("invalid %s: %v", name, err)
+		}
+	}
+
+	path := filepath.Join(config.ConfigPath, name)
+	if err := uploads.MoveFile(src, path, 0755); err != nil {
+		log.Printf("[CONFIG SYNC] Failed to write config file %s: %v", path, err)
+		return err
+	}
+	log.Printf("[CONFIG SYNC] Config file updated: %s", path)
+	return configUpdated(name)
+}
+
+// configUpdated applies a config file that was replaced.
+func configUpdated(name string) error {
+	switch name {
+	case config.ConfigFilename:
+		return config.Reload()
+	case config.CommandsFilename:
+		if err := executor.ReloadAllowList(); err != nil {
+			log.Printf("[CONFIG SYNC] Failed to reload command allow-list: %v", err)
+			return err
+		}
+	case config.ToolsFilename:
+		if err := tools.RegisterToolsFromConfig(); err != nil {
+			log.Printf("[CONFIG SYNC] Failed to reload tools: %v", err)
+			return err
+		}
+	}
+	return nil
+}
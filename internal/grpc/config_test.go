@@ -0,0 +1,40 @@
+package agentgrpc
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"openshield-agent/internal/config"
+	"openshield-agent/proto"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestSendConfigFileName(t *testing.T) {
+	configPath := config.ConfigPath
+	dir := t.TempDir()
+	config.ConfigPath = filepath.Join(dir, "config")
+	t.Cleanup(func() { config.ConfigPath = configPath })
+	if err := os.Mkdir(config.ConfigPath, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	s := &AgentServer{}
+	for _, name := range []string{"../escape.yml", "sub/app.yml", ".hidden", ""} {
+		_, err := s.SendConfigFile(context.Background(), &proto.FileContent{Filename: name, Content: []byte("x: 1\n")})
+		if status.Code(err) != codes.InvalidArgument {
+			t.Errorf("SendConfigFile(%q) error = %v, want InvalidArgument", name, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "escape.yml")); !os.IsNotExist(err) {
+		t.Error("SendConfigFile() wrote outside the config directory")
+	}
+
+	res, err := s.SendConfigFile(context.Background(), &proto.FileContent{Filename: "app.yml", Content: []byte("x: 1\n")})
+	if err != nil || !res.Success {
+		t.Errorf("SendConfigFile(app.yml) = %v, %v, want success", res, err)
+	}
+}
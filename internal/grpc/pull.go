@@ -0,0 +1,207 @@
+package agentgrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"openshield-agent/internal/scripts"
+	"openshield-agent/internal/uploads"
+	"openshield-agent/internal/utils"
+	"openshield-agent/proto"
+)
+
+// pullSession keys partial downloads, so an interrupted download resumes on
+// the next sync instead of starting over.
+const pullSession = "pull"
+
+// SyncFromManager fetches the scripts and config files the manager wants the
+// agent to have, and downloads and applies the ones that differ. It is the
+// agent-initiated counterpart of the manager pushing them.
+func (c *ManagerClient) SyncFromManager(ctx context.Context) error {
+	creds, err := utils.GetAgentCredentials()
+	if err != nil {
+		return err
+	}
+
+	state, err := c.client.GetDesiredState(ctx, &proto.DesiredStateRequest{AgentId: creds.AgentID})
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	if state.Scripts != nil {
+		if err := c.syncScripts(ctx, creds.AgentID, state.Scripts); err != nil {
+			errs = append(errs, fmt.Errorf("script sync failed: %w", err))
+		}
+	}
+	if err := c.syncConfigs(ctx, creds.AgentID, state.Configs); err != nil {
+		errs = append(errs, fmt.Errorf("config sync failed: %w", err))
+	}
+	return errors.Join(errs...)
+}
+
+// syncScripts replaces the script set with the manifest's in a sync session,
+// unless the scripts already match it.
+func (c *ManagerClient) syncScripts(ctx context.Context, agentID string, manifest *proto.ScriptManifest) error {
+	current, err := scriptChecksums()
+	if err != nil {
+		return err
+	}
+	if scriptsMatch(current, manifest) {
+		return nil
+	}
+
+	session, needed, err := scripts.BeginSync(syncEntries(manifest))
+	if err != nil {
+		return err
+	}
+	checksums := make(map[string]string, len(manifest.Scripts))
+	for _, script := range manifest.Scripts {
+		checksums[script.Name] = script.Sha256
+	}
+	for _, name := range needed {
+		path, trailer, err := c.download(ctx, agentID, proto.FileKind_SCRIPT, name, checksums[name])
+		if err == nil {
+			if err = session.StageFile(name, path, trailer.Signature, trailer.Certificate); err != nil {
+				os.Remove(path)
+			}
+		}
+		if err != nil {
+			session.Abort()
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	if err := session.Commit(); err != nil {
+		session.Abort()
+		return err
+	}
+	log.Printf("[PULL SYNC] Synced %d scripts, %d downloaded", len(manifest.Scripts), len(needed))
+	return nil
+}
+
+// scriptsMatch reports whether the scripts on disk are exactly the ones in
+// the manifest, with the same content and version.
+func scriptsMatch(current []*proto.Checksum, manifest *proto.ScriptManifest) bool {
+	if len(current) != len(manifest.Scripts) {
+		return false
+	}
+	have := make(map[string]*proto.Checksum, len(current))
+	for _, c := range current {
+		have[c.Filename] = c
+	}
+	for _, want := range manifest.Scripts {
+		c, ok := have[want.Name]
+		if !ok || !strings.EqualFold(c.Checksum, want.Sha256) || c.Version != want.Version {
+			return false
+		}
+	}
+	return true
+}
+
+// syncConfigs downloads and installs the config files whose checksum differs.
+func (c *ManagerClient) syncConfigs(ctx context.Context, agentID string, configs []*proto.Checksum) error {
+	if len(configs) == 0 {
+		return nil
+	}
+	current, err := configChecksums()
+	if err != nil {
+		return err
+	}
+	have := make(map[string]string, len(current))
+	for _, c := range current {
+		have[c.Filename] = c.Checksum
+	}
+
+	var errs []error
+	for _, want := range configs {
+		name := want.Filename
+		if !validUploadName(proto.FileKind_CONFIG, name) {
+			errs = append(errs, fmt.Errorf("invalid config file name %q", name))
+			continue
+		}
+		if strings.EqualFold(have[name], want.Checksum) {
+			continue
+		}
+
+		path, _, err := c.download(ctx, agentID, proto.FileKind_CONFIG, name, want.Checksum)
+		if err == nil {
+			if err = installConfigFile(name, path); err != nil {
+				os.Remove(path)
+			}
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			continue
+		}
+		log.Printf("[PULL SYNC] Updated config file %s", name)
+	}
+	return errors.Join(errs...)
+}
+
+// download fetches a file from the manager into a partial upload, resuming a
+// previous attempt, and returns its path once the content matches the
+// trailer's checksum. The trailer's checksum must be the one the desired
+// state listed, so a file that changed on the manager since is not applied.
+func (c *ManagerClient) download(ctx context.Context, agentID string, kind proto.FileKind, name, checksum string) (string, *proto.UploadTrailer, error) {
+	key := uploadKey(kind, pullSession, name)
+	offset, err := uploads.Offset(key...)
+	if err != nil {
+		return "", nil, err
+	}
+	upload, err := uploads.Open(offset, key...)
+	if err != nil {
+		return "", nil, err
+	}
+
+	stream, err := c.client.DownloadFile(ctx, &proto.DownloadFileRequest{
+		AgentId:  agentID,
+		Kind:     kind,
+		Filename: name,
+		Offset:   uint64(offset),
+	})
+	if err != nil {
+		upload.Close()
+		return "", nil, err
+	}
+
+	for {
+		msg, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			upload.Close()
+			return "", nil, errors.New("download ended without a trailer")
+		}
+		if err != nil {
+			// Keep what was received for the next attempt
+			upload.Close()
+			return "", nil, err
+		}
+
+		switch payload := msg.Payload.(type) {
+		case *proto.UploadFileMessage_Chunk:
+			if _, err := upload.Write(payload.Chunk); err != nil {
+				upload.Close()
+				return "", nil, err
+			}
+		case *proto.UploadFileMessage_Trailer:
+			path, err := upload.Finish(payload.Trailer.Sha256)
+			if err != nil {
+				return "", nil, err
+			}
+			if !strings.EqualFold(payload.Trailer.Sha256, checksum) {
+				upload.Discard()
+				return "", nil, fmt.Errorf("downloaded checksum %s does not match the desired %s", payload.Trailer.Sha256, checksum)
+			}
+			// Only the pull loop downloads with this key, one file at a time
+			upload.Close()
+			return path, payload.Trailer, nil
+		default:
+			upload.Close()
+			return "", nil, errors.New("unexpected message in download")
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package agentgrpc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"openshield-agent/internal/config"
+	"openshield-agent/internal/uploads"
+	"openshield-agent/proto"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// fakeManager serves files from memory in small chunks.
+type fakeManager struct {
+	proto.UnimplementedManagerServiceServer
+	files map[string][]byte
+}
+
+func (m *fakeManager) DownloadFile(req *proto.DownloadFileRequest, stream proto.ManagerService_DownloadFileServer) error {
+	content := m.files[req.Filename][req.Offset:]
+	for len(content) > 0 {
+		n := min(4, len(content))
+		if err := stream.Send(&proto.UploadFileMessage{Payload: &proto.UploadFileMessage_Chunk{Chunk: content[:n]}}); err != nil {
+			return err
+		}
+		content = content[n:]
+	}
+	return stream.Send(&proto.UploadFileMessage{Payload: &proto.UploadFileMessage_Trailer{
+		Trailer: &proto.UploadTrailer{Sha256: checksumOf(m.files[req.Filename])},
+	}})
+}
+
+func checksumOf(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+func newFakeManagerClient(t *testing.T, m *fakeManager) *ManagerClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	proto.RegisterManagerServiceServer(server, m)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return &ManagerClient{conn: conn, client: proto.NewManagerServiceClient(conn)}
+}
+
+func TestSyncConfigs(t *testing.T) {
+	configPath, uploadsDir := config.ConfigPath, uploads.Dir
+	config.ConfigPath, uploads.Dir = t.TempDir(), t.TempDir()
+	t.Cleanup(func() { config.ConfigPath, uploads.Dir = configPath, uploadsDir })
+
+	unchanged := []byte("kept: true\n")
+	if err := os.WriteFile(filepath.Join(config.ConfigPath, "kept.yml"), unchanged, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	rules := []byte("rules: [a, b, c]\n")
+	m := &fakeManager{files: map[string][]byte{"rules.yml": rules}}
+	client := newFakeManagerClient(t, m)
+
+	// A previous download was interrupted after a few bytes
+	partial, err := uploads.Open(0, uploadKey(proto.FileKind_CONFIG, pullSession, "rules.yml")...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	partial.Write(rules[:5])
+	partial.Close()
+
+	err = client.syncConfigs(context.Background(), "agent", []*proto.Checksum{
+		{Filename: "kept.yml", Checksum: checksumOf(unchanged)},
+		{Filename: "rules.yml", Checksum: checksumOf(rules)},
+	})
+	if err != nil {
+		t.Fatalf("syncConfigs() error = %v", err)
+	}
+
+	got, err := os.ReadFile(filepath.Join(config.ConfigPath, "rules.yml"))
+	if err != nil || string(got) != string(rules) {
+		t.Errorf("rules.yml = %q, %v; want %q", got, err, rules)
+	}
+
+	// The file changed on the manager after the desired state was fetched
+	m.files["rules.yml"] = []byte("rules: [d]\n")
+	err = client.syncConfigs(context.Background(), "agent", []*proto.Checksum{{Filename: "rules.yml", Checksum: checksumOf([]byte("rules: [e]\n"))}})
+	if err == nil {
+		t.Error("syncConfigs() applied a file that does not match the desired checksum")
+	}
+	if got, _ := os.ReadFile(filepath.Join(config.ConfigPath, "rules.yml")); string(got) != string(rules) {
+		t.Errorf("rules.yml = %q, want it unchanged", got)
+	}
+
+	if err := client.syncConfigs(context.Background(), "agent", []*proto.Checksum{{Filename: "../escape.yml"}}); err == nil {
+		t.Error("syncConfigs() accepted a path outside the config directory")
+	}
+}
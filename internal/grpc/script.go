@@ -7,26 +7,47 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"openshield-agent/internal/config"
+	"openshield-agent/internal/scripts"
 	"openshield-agent/proto"
 
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
 	"google.golang.org/protobuf/types/known/emptypb"
 )
 
 func (s *AgentServer) GetScriptChecksums(ctx context.Context, _ *emptypb.Empty) (*proto.ChecksumResponse, error) {
-	files, err := os.ReadDir(config.ScriptsPath)
+	checksums, err := scriptChecksums()
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("[SCRIPT SYNC] Returning checksums for %d scripts", len(checksums))
+	return &proto.ChecksumResponse{Files: checksums}, nil
+}
+
+// scriptChecksums returns the checksum of every file in the scripts directory.
+// Files of script packages are listed by their path inside the directory.
+func scriptChecksums() ([]*proto.Checksum, error) {
+	files, err := scripts.ListFiles(config.ScriptsPath)
 	if err != nil {
 		log.Printf("[SCRIPT SYNC] Failed to read scripts directory: %v", err)
 		return nil, err
 	}
 
+	// Versions are only known for scripts synced with a manifest
+	manifest, err := scripts.LoadManifest()
+	if err != nil {
+		log.Printf("[SCRIPT SYNC] Failed to read script manifest: %v", err)
+		manifest = scripts.Manifest{}
+	}
+
 	var checksums []*proto.Checksum
-	for _, file := range files {
-		if file.IsDir() {
-			continue
-		}
-		path := filepath.Join(config.ScriptsPath, file.Name())
+	for _, name := range files {
+		path := filepath.Join(config.ScriptsPath, filepath.FromSlash(name))
 		content, err := os.ReadFile(path)
 		if err != nil {
 			log.Printf("[SCRIPT SYNC] Failed to read script file %s: %v", path, err)
@@ -34,40 +55,157 @@ func (s *AgentServer) GetScriptChecksums(ctx context.Context, _ *emptypb.Empty)
 		}
 		checksum := sha256.Sum256(content)
 		checksums = append(checksums, &proto.Checksum{
-			Filename: file.Name(),
+			Filename: name,
 			Checksum: hex.EncodeToString(checksum[:]),
+			Version:  manifest[name].Version,
 		})
 	}
-
-	log.Printf("[SCRIPT SYNC] Returning checksums for %d scripts", len(checksums))
-	return &proto.ChecksumResponse{Files: checksums}, nil
+	return checksums, nil
 }
 
 func (s *AgentServer) SendScriptFile(ctx context.Context, file *proto.FileContent) (*proto.SyncStatus, error) {
-	path := filepath.Join(config.ScriptsPath, file.Filename)
-	err := os.WriteFile(path, file.Content, 0755)
-	if err != nil {
-		log.Printf("[SCRIPT SYNC] Failed to write script file %s: %v", path, err)
-		return &proto.SyncStatus{Success: false, Message: err.Error()}, nil
+	name := file.Filename
+	if !scripts.ValidName(name) {
+		log.Printf("[SCRIPT SYNC] Rejected script with invalid name: %q", file.Filename)
+		return &proto.SyncStatus{Success: false, Message: "invalid script name"}, nil
+	}
+
+	// Only signed scripts are written; they are checked again before each run
+	if err := scripts.Save(name, file.Content, file.Signature, file.Certificate); err != nil {
+		log.Printf("[SCRIPT SYNC] Rejected script file %s: %v", name, err)
+		return &proto.SyncStatus{Success: false, Message: "script rejected: " + err.Error()}, nil
 	}
-	log.Printf("[SCRIPT SYNC] Script file updated: %s", path)
+	log.Printf("[SCRIPT SYNC] Script file updated: %s", name)
 	return &proto.SyncStatus{Success: true, Message: "File updated successfully"}, nil
 }
 
 func (s *AgentServer) DeleteScriptFile(ctx context.Context, req *proto.DeleteScriptRequest) (*proto.SyncStatus, error) {
-	path := filepath.Join("scripts", filepath.Clean(req.GetFilename()))
-	err := os.Remove(path)
+	name := req.GetFilename()
+	if !scripts.ValidName(name) {
+		return &proto.SyncStatus{Success: false, Message: "invalid script name"}, nil
+	}
+
+	err := scripts.Delete(name)
 	if err != nil {
-		log.Printf("[SCRIPT SYNC] Failed to delete script %s: %v", path, err)
+		log.Printf("[SCRIPT SYNC] Failed to delete script %s: %v", name, err)
 		return &proto.SyncStatus{
 			Success: false,
 			Message: err.Error(),
 		}, nil
 	}
 
-	log.Printf("[SCRIPT SYNC] Deleted script: %s", path)
+	log.Printf("[SCRIPT SYNC] Deleted script: %s", name)
 	return &proto.SyncStatus{
 		Success: true,
 		Message: "Script deleted successfully",
 	}, nil
 }
+
+// BeginScriptSync starts a sync of the complete script set and returns the
+// scripts the manager has to send before committing it
+func (s *AgentServer) BeginScriptSync(ctx context.Context, req *proto.ScriptManifest) (*proto.ScriptSyncSession, error) {
+	session, needed, err := scripts.BeginSync(syncEntries(req))
+	if err != nil {
+		log.Printf("[SCRIPT SYNC] Failed to start sync: %v", err)
+		return nil, status.Errorf(codes.FailedPrecondition, "failed to start script sync: %v", err)
+	}
+	return &proto.ScriptSyncSession{SessionId: session.ID, Needed: needed}, nil
+}
+
+// syncEntries converts a script manifest to the entries of a sync session
+func syncEntries(manifest *proto.ScriptManifest) []scripts.SyncEntry {
+	entries := make([]scripts.SyncEntry, 0, len(manifest.Scripts))
+	for _, e := range manifest.Scripts {
+		entries = append(entries, scripts.SyncEntry{
+			Name:    e.Name,
+			SHA256:  strings.ToLower(e.Sha256),
+			Version: e.Version,
+			Mode:    os.FileMode(e.Mode).Perm(),
+		})
+	}
+	return entries
+}
+
+// StageScriptFile receives a script of a sync session
+func (s *AgentServer) StageScriptFile(ctx context.Context, req *proto.StageScriptRequest) (*proto.SyncStatus, error) {
+	if req.File == nil {
+		return &proto.SyncStatus{Success: false, Message: "file is required"}, nil
+	}
+	session, err := scripts.GetSession(req.SessionId)
+	if err != nil {
+		return &proto.SyncStatus{Success: false, Message: err.Error()}, nil
+	}
+
+	if err := session.Stage(req.File.Filename, req.File.Content, req.File.Signature, req.File.Certificate); err != nil {
+		log.Printf("[SCRIPT SYNC] Rejected script file %s: %v", req.File.Filename, err)
+		return &proto.SyncStatus{Success: false, Message: "script rejected: " + err.Error()}, nil
+	}
+	log.Printf("[SCRIPT SYNC] Staged script file %s for sync %s", req.File.Filename, session.ID)
+	return &proto.SyncStatus{Success: true, Message: "File staged successfully"}, nil
+}
+
+// CommitScriptSync replaces the scripts with the staged set
+func (s *AgentServer) CommitScriptSync(ctx context.Context, req *proto.ScriptSyncRequest) (*proto.SyncStatus, error) {
+	session, err := scripts.GetSession(req.SessionId)
+	if err != nil {
+		return &proto.SyncStatus{Success: false, Message: err.Error()}, nil
+	}
+
+	if err := session.Commit(); err != nil {
+		log.Printf("[SCRIPT SYNC] Failed to commit sync %s: %v", session.ID, err)
+		return &proto.SyncStatus{Success: false, Message: err.Error()}, nil
+	}
+	return &proto.SyncStatus{Success: true, Message: "Scripts synced successfully"}, nil
+}
+
+// AbortScriptSync discards a sync session, leaving the scripts unchanged
+func (s *AgentServer) AbortScriptSync(ctx context.Context, req *proto.ScriptSyncRequest) (*proto.SyncStatus, error) {
+	session, err := scripts.GetSession(req.SessionId)
+	if err != nil {
+		return &proto.SyncStatus{Success: false, Message: err.Error()}, nil
+	}
+
+	if err := session.Abort(); err != nil {
+		return &proto.SyncStatus{Success: false, Message: err.Error()}, nil
+	}
+	return &proto.SyncStatus{Success: true, Message: "Script sync aborted"}, nil
+}
+
+// ListScripts returns the scripts and script packages with their metadata
+func (s *AgentServer) ListScripts(ctx context.Context, _ *emptypb.Empty) (*proto.ListScriptsResponse, error) {
+	list, err := scripts.List()
+	if err != nil {
+		log.Printf("[SCRIPT SYNC] Failed to list scripts: %v", err)
+		return nil, status.Errorf(codes.Internal, "failed to list scripts: %v", err)
+	}
+
+	resp := &proto.ListScriptsResponse{}
+	for _, l := range list {
+		info := &proto.ScriptInfo{
+			Name:           l.Name,
+			Package:        l.Package,
+			Description:    l.Metadata.Description,
+			Interpreter:    l.Metadata.Interpreter,
+			Os:             l.Metadata.OS,
+			ArgsRestricted: l.Metadata.Args != nil,
+			Privileges:     l.Metadata.Privileges,
+		}
+		for _, a := range l.Metadata.Args {
+			info.Args = append(info.Args, &proto.ScriptArg{
+				Name:        a.Name,
+				Description: a.Description,
+				Required:    a.Required,
+				Values:      a.Values,
+				Pattern:     a.Pattern,
+			})
+		}
+		if timeout, err := l.Metadata.TimeoutDuration(); err == nil && timeout > 0 {
+			info.Timeout = durationpb.New(timeout)
+		}
+		if l.Err != nil {
+			info.Error = l.Err.Error()
+		}
+		resp.Scripts = append(resp.Scripts, info)
+	}
+	return resp, nil
+}
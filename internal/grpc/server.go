@@ -4,7 +4,10 @@ import (
 	"fmt"
 	"log"
 	"net"
+	"path/filepath"
 
+	"openshield-agent/internal/config"
+	"openshield-agent/internal/tasks"
 	"openshield-agent/internal/utils"
 	"openshield-agent/proto"
 
@@ -15,6 +18,28 @@ import (
 // AgentServer implements proto.AgentServiceServer
 type AgentServer struct {
 	proto.UnimplementedAgentServiceServer
+
+	tasks *tasks.Registry
+	// toolRuns tracks tool executions by execution ID
+	toolRuns  *tasks.Registry
+	toolLocks toolLocks
+}
+
+// newRegistry creates a task registry backed by the named journal in the
+// state directory. If the journal cannot be used, tasks are only tracked in memory.
+func newRegistry(journalFilename string) *tasks.Registry {
+	registry := tasks.NewRegistry(maxConcurrentTasks())
+
+	journal, err := tasks.OpenJournal(filepath.Join(config.ConfigPath, "state", journalFilename))
+	if err != nil {
+		log.Printf("[AGENT] Failed to open %s: %v", journalFilename, err)
+		return registry
+	}
+	if err := registry.UseJournal(journal); err != nil {
+		log.Printf("[AGENT] Failed to restore %s: %v", journalFilename, err)
+		journal.Close()
+	}
+	return registry
 }
 
 func StartGRPCServer(port int) error {
@@ -33,7 +58,10 @@ func StartGRPCServer(port int) error {
 	grpcServer := grpc.NewServer(
 		grpc.Creds(credentials.NewTLS(tlsConfig)),
 	)
-	proto.RegisterAgentServiceServer(grpcServer, &AgentServer{})
+	proto.RegisterAgentServiceServer(grpcServer, &AgentServer{
+		tasks:    newRegistry(tasks.JournalFilename),
+		toolRuns: newRegistry(ToolJournalFilename),
+	})
 
 	log.Printf("[AGENT] gRPC server listening on port %d", port)
 	return grpcServer.Serve(lis)
@@ -3,83 +3,82 @@ package agentgrpc
 import (
 	"context"
 	"encoding/base64"
+	"fmt"
 	"log"
 	"strings"
-	"sync"
 	"time"
 
+	"openshield-agent/internal/config"
 	"openshield-agent/internal/executor"
 	"openshield-agent/internal/models"
+	"openshield-agent/internal/tasks"
 	"openshield-agent/internal/utils"
 	"openshield-agent/proto"
-)
 
-// In-memory task state for now
-var (
-	currentStatus proto.TaskStatus = proto.TaskStatus_PENDING
-	currentResult string           = ""
-	statusMu      sync.Mutex
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
+// maxConcurrentTasks returns the configured cap on concurrently running tasks.
+func maxConcurrentTasks() int {
+	return config.Get().Executor.MaxConcurrentTasks
+}
+
 // AssignTask handles a new task assignment from the manager
 func (s *AgentServer) AssignTask(ctx context.Context, req *proto.AssignTaskRequest) (*proto.AssignTaskResponse, error) {
+	if req.Task == nil || req.Job == nil {
+		return &proto.AssignTaskResponse{
+			Accepted: false,
+			Message:  "task and job are required",
+		}, nil
+	}
 	log.Printf("[AGENT] Received task: %s (%s)", req.Task.Id, req.Job.Name)
 
-	statusMu.Lock()
-	currentStatus = proto.TaskStatus_RUNNING
-	statusMu.Unlock()
+	taskCtx, err := s.tasks.Add(req.Task.Id, req.Job.Id)
+	if err != nil {
+		log.Printf("[AGENT] Rejected task %s: %v", req.Task.Id, err)
+		return &proto.AssignTaskResponse{
+			Accepted: false,
+			Message:  err.Error(),
+		}, nil
+	}
 
 	// Start a goroutine to execute the task and update the status
 	go func() {
-		var (
-			result string
-			err    error
-		)
-
-		switch strings.ToUpper(req.Job.Type) {
-		case "SCRIPT":
-			// Execute a script from the scripts directory
-			result, err = executor.ExecuteScript(req.Job.Target, []string{})
-		case "COMMAND":
-			// Execute the command directly
-			parts := strings.Fields(req.Job.Target)
-			command := models.Command{
-				Command:  parts[0],
-				Args:     parts[1:],
-				TargetOS: utils.GetDeviceOS(),
-			}
-			result, err = executor.ExecuteCommand(command)
+		// Wait for a free slot before running
+		if !s.tasks.Acquire(taskCtx, req.Task.Id) {
+			log.Printf("[AGENT] Task %s cancelled before it started", req.Task.Id)
+			return
+		}
+
+		opts := jobOptions(req.Job)
+		if out, ok := s.tasks.Output(req.Task.Id); ok {
+			opts.Stdout = out.Writer(tasks.Stdout)
+			opts.Stderr = out.Writer(tasks.Stderr)
 		}
 
+		result, err := safeRunJob(taskCtx, req.Job, opts)
 		if err != nil {
 			log.Printf("[AGENT] Command/script execution failed: %v", err)
-			statusMu.Lock()
-			currentStatus = proto.TaskStatus_FAILED
-			currentResult = err.Error()
-			statusMu.Unlock()
+			s.tasks.Fail(req.Task.Id, result, err)
 			log.Printf("[AGENT] Task %s failed", req.Task.Id)
 			return
 		}
 
-		// Set the result and status
-		statusMu.Lock()
-		currentStatus = proto.TaskStatus_COMPLETED
-		currentResult = result
-		statusMu.Unlock()
-
+		s.tasks.Complete(req.Task.Id, result)
 		log.Printf("[AGENT] Task %s completed", req.Task.Id)
 	}()
 
-	// Start a goroutine to report task status every second
+	// Start a goroutine to report task status every few seconds
 	go func() {
 		for {
-			statusMu.Lock()
-			if currentStatus == proto.TaskStatus_COMPLETED || currentStatus == proto.TaskStatus_FAILED {
-				statusMu.Unlock()
+			rec, ok := s.tasks.Get(req.Task.Id)
+			if !ok || rec.Finished() {
 				break
 			}
-			log.Printf("[AGENT] Task %s status: %v", req.Task.Id, currentStatus)
-			statusMu.Unlock()
+			log.Printf("[AGENT] Task %s status: %v", req.Task.Id, rec.Status)
 			time.Sleep(5 * time.Second)
 		}
 	}()
@@ -90,19 +89,183 @@ func (s *AgentServer) AssignTask(ctx context.Context, req *proto.AssignTaskReque
 	}, nil
 }
 
+// jobOptions builds the execution options requested by the job.
+func jobOptions(job *proto.Job) executor.Options {
+	opts := executor.Options{
+		Env:     job.Env,
+		Dir:     job.WorkingDir,
+		Stdin:   job.Stdin,
+		Profile: job.Profile,
+	}
+	if job.Timeout != nil {
+		opts.Timeout = job.Timeout.AsDuration()
+	}
+	return opts
+}
+
+// safeRunJob runs the job and turns a panic into an error, so a bad job can
+// never leave its task stuck in RUNNING.
+func safeRunJob(ctx context.Context, job *proto.Job, opts executor.Options) (result *executor.Result, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("[AGENT] Recovered from panic while running job %s: %v", job.Id, r)
+			result, err = nil, fmt.Errorf("internal error while running job: %v", r)
+		}
+	}()
+	return runJob(ctx, job, opts)
+}
+
+// runJob executes the script or command described by the job.
+func runJob(ctx context.Context, job *proto.Job, opts executor.Options) (*executor.Result, error) {
+	switch strings.ToUpper(job.Type) {
+	case "SCRIPT":
+		// Execute a script from the scripts directory
+		return executor.ExecuteScript(ctx, job.Target, job.Args, opts)
+	case "COMMAND":
+		// Execute the command directly, without a shell
+		parts, err := executor.SplitCommandLine(job.Target)
+		if err != nil {
+			return nil, fmt.Errorf("invalid command target: %w", err)
+		}
+		command := models.Command{
+			Command:  parts[0],
+			Args:     append(parts[1:], job.Args...),
+			TargetOS: utils.GetDeviceOS(),
+		}
+		return executor.ExecuteCommand(ctx, command, opts)
+	default:
+		return nil, fmt.Errorf("unsupported job type: %s", job.Type)
+	}
+}
+
+// CancelTask cancels a pending or running task and kills its processes
+func (s *AgentServer) CancelTask(ctx context.Context, req *proto.CancelTaskRequest) (*proto.CancelTaskResponse, error) {
+	log.Printf("[AGENT] Cancelling task %s", req.TaskId)
+
+	if err := s.tasks.Cancel(req.TaskId); err != nil {
+		log.Printf("[AGENT] Could not cancel task %s: %v", req.TaskId, err)
+		return &proto.CancelTaskResponse{
+			Cancelled: false,
+			Message:   err.Error(),
+		}, nil
+	}
+
+	return &proto.CancelTaskResponse{
+		Cancelled: true,
+		Message:   "Task cancelled",
+	}, nil
+}
+
 // ReportTaskStatus returns the current status and result of a task
 func (s *AgentServer) ReportTaskStatus(ctx context.Context, req *proto.JobStatusRequest) (*proto.JobStatusResponse, error) {
-	statusMu.Lock()
-	defer statusMu.Unlock()
-
 	log.Printf("[AGENT] Reporting status for job %s", req.JobId)
 
-	// Encode the result to base64 to ensure safe transmission
-	encodedResult := base64.StdEncoding.EncodeToString([]byte(currentResult))
+	rec, ok := s.tasks.Get(req.JobId)
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "no task found for %s", req.JobId)
+	}
 
-	return &proto.JobStatusResponse{
-		JobId:  req.JobId,
-		Status: currentStatus,
-		Result: encodedResult,
-	}, nil
+	return jobStatusResponse(rec), nil
+}
+
+// jobStatusResponse converts a task record to its status message
+func jobStatusResponse(rec tasks.Record) *proto.JobStatusResponse {
+	resp := &proto.JobStatusResponse{
+		JobId:  rec.JobID,
+		TaskId: rec.TaskID,
+		Status: rec.Status,
+		// Encode the result to base64 to ensure safe transmission
+		Result:    base64.StdEncoding.EncodeToString([]byte(rec.Result)),
+		Error:     rec.Error,
+		Execution: executionResult(rec.Execution),
+	}
+	if !rec.StartedAt.IsZero() {
+		resp.StartedAt = timestamppb.New(rec.StartedAt)
+	}
+	if !rec.EndedAt.IsZero() {
+		resp.FinishedAt = timestamppb.New(rec.EndedAt)
+	}
+	return resp
+}
+
+// executionResult converts an executor result to its proto message
+func executionResult(res *executor.Result) *proto.ExecutionResult {
+	if res == nil {
+		return nil
+	}
+	execution := &proto.ExecutionResult{
+		ExitCode:   int32(res.ExitCode),
+		Stdout:     []byte(res.Stdout),
+		Stderr:     []byte(res.Stderr),
+		Signal:     res.Signal,
+		StartedAt:  timestamppb.New(res.StartTime),
+		FinishedAt: timestamppb.New(res.EndTime),
+		Truncated:  res.Truncated,
+	}
+	if res.Resources != nil {
+		execution.Resources = &proto.ResourceUsage{
+			PeakMemoryBytes: res.Resources.PeakMemoryBytes,
+			CpuTime:         durationpb.New(res.Resources.CPUTime),
+			OomKilled:       res.Resources.OOMKilled,
+			Source:          res.Resources.Source,
+		}
+	}
+	return execution
+}
+
+// StreamTaskOutput streams the output of a task as it is produced, followed by its final status
+func (s *AgentServer) StreamTaskOutput(req *proto.TaskOutputRequest, stream proto.AgentService_StreamTaskOutputServer) error {
+	log.Printf("[AGENT] Streaming output for task %s", req.TaskId)
+
+	// The ID may be a job ID, which stands for the job's latest task
+	rec, ok := s.tasks.Get(req.TaskId)
+	if !ok {
+		return status.Errorf(codes.NotFound, "no task found for %s", req.TaskId)
+	}
+	taskID := rec.TaskID
+
+	// Tasks restored from the journal only have their final status
+	if out, ok := s.tasks.Output(taskID); ok {
+		next := req.FromSequence
+		for {
+			chunks, more, closed := out.Read(next)
+			for _, c := range chunks {
+				msg := &proto.TaskOutputMessage{
+					Payload: &proto.TaskOutputMessage_Chunk{Chunk: &proto.TaskOutputChunk{
+						Sequence:  c.Sequence,
+						Stream:    outputStream(c.Stream),
+						Data:      c.Data,
+						Timestamp: timestamppb.New(c.Time),
+						Dropped:   c.Dropped,
+					}},
+				}
+				if err := stream.Send(msg); err != nil {
+					return err
+				}
+				next = c.Sequence + 1
+			}
+			if closed {
+				break
+			}
+
+			select {
+			case <-more:
+			case <-stream.Context().Done():
+				return stream.Context().Err()
+			}
+		}
+	}
+
+	rec, _ = s.tasks.Get(taskID)
+	return stream.Send(&proto.TaskOutputMessage{
+		Payload: &proto.TaskOutputMessage_Status{Status: jobStatusResponse(rec)},
+	})
+}
+
+// outputStream converts a task output stream to its proto enum
+func outputStream(stream tasks.Stream) proto.OutputStream {
+	if stream == tasks.Stderr {
+		return proto.OutputStream_STDERR
+	}
+	return proto.OutputStream_STDOUT
 }
@@ -2,40 +2,104 @@ package agentgrpc
 
 import (
 	"context"
+	"crypto/rand"
 	"encoding/base64"
+	"encoding/hex"
 	"fmt"
 	"log"
+	"openshield-agent/internal/executor"
+	"openshield-agent/internal/tasks"
 	"openshield-agent/internal/tools"
 	"openshield-agent/proto"
+	"strings"
 	"sync"
-	"time"
 
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
-// In-memory tool action state for now
-var (
-	toolActionStatus   proto.TaskStatus = proto.TaskStatus_PENDING
-	toolActionResult   string           = ""
-	toolActionStatusMu sync.Mutex
-)
+// ToolJournalFilename is the name of the tool execution journal inside the state directory.
+const ToolJournalFilename = "tools.journal"
+
+// toolLocks tracks which execution holds each tool lock.
+type toolLocks struct {
+	mu   sync.Mutex
+	held map[string]string
+}
+
+// acquire takes the lock for the execution. If the lock is held, it returns
+// the execution holding it and false.
+func (l *toolLocks) acquire(lock, executionID string) (string, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if holder, ok := l.held[lock]; ok {
+		return holder, false
+	}
+	if l.held == nil {
+		l.held = make(map[string]string)
+	}
+	l.held[lock] = executionID
+	return executionID, true
+}
+
+// release frees the lock.
+func (l *toolLocks) release(lock string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.held, lock)
+}
+
+// toolJobID identifies a tool action in the execution registry, so its most
+// recent execution can be looked up.
+func toolJobID(name, action string) string {
+	return name + "/" + action
+}
+
+// newExecutionID returns a random ID for a tool execution.
+func newExecutionID() (string, error) {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(id), nil
+}
+
+// safeExecAction runs a tool action, turning a panic into an error so the
+// execution fails instead of the agent crashing.
+func safeExecAction(ctx context.Context, tool *tools.Tool, req *proto.ExecuteToolRequest) (result *executor.Result, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("[TOOL] Recovered from panic while running tool %s action %s: %v", req.Name, req.Action, r)
+			result, err = nil, fmt.Errorf("internal error while running tool action: %v", r)
+		}
+	}()
+	return tool.ExecAction(ctx, req.Action, req.Values, req.Options)
+}
 
 // GetTools handles the GetTools RPC.
 func (s *AgentServer) GetTools(ctx context.Context, req *emptypb.Empty) (*proto.GetToolsResponse, error) {
-	tools := tools.GetTools()
-
 	var toolList []*proto.Tool
-	for _, tool := range tools {
+	for _, tool := range tools.GetTools() {
 		toolProto := &proto.Tool{
 			Name:    tool.Name,
 			Actions: make([]*proto.ToolAction, len(tool.Actions)),
 			Os:      tool.OS,
 		}
 		for i, action := range tool.Actions {
-			toolProto.Actions[i] = &proto.ToolAction{
-				Name:    action.Name,
-				Options: action.Opts,
+			actionProto := &proto.ToolAction{Name: action.Name, Options: tools.LegacyValues(action.Options)}
+			for _, opt := range action.Options {
+				actionProto.Schema = append(actionProto.Schema, &proto.ToolOption{
+					Name:        opt.Name,
+					Type:        opt.Type,
+					Description: opt.Description,
+					Enum:        opt.Enum,
+					Default:     opt.Default,
+					Required:    opt.Required,
+				})
 			}
+			toolProto.Actions[i] = actionProto
 		}
 		toolList = append(toolList, toolProto)
 	}
@@ -45,94 +109,123 @@ func (s *AgentServer) GetTools(ctx context.Context, req *emptypb.Empty) (*proto.
 	}, nil
 }
 
-// ExecuteTool handles the ExecuteTool RPC.
+// ExecuteTool handles the ExecuteTool RPC. The action runs in the background
+// under the tool's lock; its status is reported by the returned execution ID.
 func (s *AgentServer) ExecuteTool(ctx context.Context, req *proto.ExecuteToolRequest) (*proto.ExecuteToolResponse, error) {
-	// TODO: Implement logic to execute the requested tool with given action and options
-	log.Printf("[AGENT] Received tool execution: %s (%s) [%v]", req.Name, req.Action, req.Options)
-
-	// Check if another action is currently executing
-	toolActionStatusMu.Lock()
-	if toolActionStatus == proto.TaskStatus_RUNNING {
-		toolActionStatusMu.Unlock()
-		return nil, fmt.Errorf("another tool action is currently executing")
+	log.Printf("[AGENT] Received tool execution: %s (%s) %v %v", req.Name, req.Action, req.Values, req.Options)
+
+	reject := func(message string) (*proto.ExecuteToolResponse, error) {
+		log.Printf("[TOOL] Rejected tool action %s %s: %s", req.Name, req.Action, message)
+		return &proto.ExecuteToolResponse{
+			Name:     req.Name,
+			Action:   req.Action,
+			Accepted: false,
+			Message:  message,
+		}, nil
 	}
-	toolActionStatusMu.Unlock()
-
-	// Set the status to running
-	toolActionStatusMu.Lock()
-	toolActionStatus = proto.TaskStatus_RUNNING
-	toolActionStatusMu.Unlock()
 
 	// Check if the tool exists
 	tool, exists := tools.GetTool(req.Name)
 	if !exists {
-		return nil, fmt.Errorf("tool %s not found", req.Name)
+		return reject(fmt.Sprintf("tool %s not found", req.Name))
+	}
+	// Reject invalid options before anything runs
+	if err := tool.ValidateAction(req.Action, req.Values, req.Options); err != nil {
+		return reject(err.Error())
+	}
+
+	executionID, err := newExecutionID()
+	if err != nil {
+		return nil, err
+	}
+	lock := tool.LockName()
+	if holder, ok := s.toolLocks.acquire(lock, executionID); !ok {
+		return reject(fmt.Sprintf("tool %s is busy with execution %s", req.Name, holder))
+	}
+	runCtx, err := s.toolRuns.Add(executionID, toolJobID(req.Name, req.Action))
+	if err != nil {
+		s.toolLocks.release(lock)
+		return reject(err.Error())
 	}
 
 	go func() {
-		var (
-			result string
-			err    error
-		)
+		defer s.toolLocks.release(lock)
+		if !s.toolRuns.Acquire(runCtx, executionID) {
+			return
+		}
 
 		// Execute the action
-		result, err = tool.ExecAction(req.Action, req.Options)
+		// Cancelling the execution in the registry cancels runCtx
+		result, err := safeExecAction(runCtx, &tool, req)
 		if err != nil {
 			log.Printf("[TOOL] Tool action execution failed: %v", err)
-			toolActionStatusMu.Lock()
-			toolActionStatus = proto.TaskStatus_FAILED
-			toolActionResult = err.Error()
-			toolActionStatusMu.Unlock()
-			log.Printf("[AGENT] Tool %s action %s failed", req.Name, req.Action)
+			s.toolRuns.Fail(executionID, result, err)
+			log.Printf("[AGENT] Tool %s action %s failed (%s)", req.Name, req.Action, executionID)
 			return
 		}
-
-		// Set the result and status
-		toolActionStatusMu.Lock()
-		toolActionStatus = proto.TaskStatus_COMPLETED
-		toolActionResult = result
-		toolActionStatusMu.Unlock()
-
-		log.Printf("[AGENT] Tool %s action %s completed", req.Name, req.Action)
-	}()
-
-	// Start a goroutine to report task status every second
-	go func() {
-		for {
-			toolActionStatusMu.Lock()
-			if toolActionStatus == proto.TaskStatus_COMPLETED || toolActionStatus == proto.TaskStatus_FAILED {
-				toolActionStatusMu.Unlock()
-				break
-			}
-			log.Printf("[AGENT] Tool %s action %s status: %v", req.Name, req.Action, toolActionStatus)
-			toolActionStatusMu.Unlock()
-			time.Sleep(5 * time.Second)
-		}
+		s.toolRuns.Complete(executionID, result)
+		log.Printf("[AGENT] Tool %s action %s completed (%s)", req.Name, req.Action, executionID)
 	}()
 
 	return &proto.ExecuteToolResponse{
-		Name:     req.Name,
-		Action:   req.Action,
-		Accepted: true,
-		Message:  "Tool execution started",
+		Name:        req.Name,
+		Action:      req.Action,
+		Accepted:    true,
+		Message:     "Tool execution started",
+		ExecutionId: executionID,
 	}, nil
 }
 
 // ReportToolExecutionStatus handles the ReportToolExecutionStatus RPC.
 func (s *AgentServer) ReportToolExecutionStatus(ctx context.Context, req *proto.ToolExecutionStatusRequest) (*proto.ToolExecutionStatusResponse, error) {
-	// TODO: Implement logic to report the status of a tool execution
-	toolActionStatusMu.Lock()
-	defer toolActionStatusMu.Unlock()
+	log.Printf("[AGENT] Reporting status for tool %s action %s %s", req.Name, req.Action, req.ExecutionId)
 
-	log.Printf("[AGENT] Reporting status for tool %s action %s", req.Name, req.Action)
+	id := req.ExecutionId
+	if id == "" {
+		id = toolJobID(req.Name, req.Action)
+	}
+	rec, ok := s.toolRuns.Get(id)
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "no execution found for tool %s action %s %s", req.Name, req.Action, req.ExecutionId)
+	}
+	return toolExecutionStatus(rec), nil
+}
 
-	// Encode the result to base64 to ensure safe transmission
-	encodedResult := base64.StdEncoding.EncodeToString([]byte(toolActionResult))
+// ListToolExecutions handles the ListToolExecutions RPC.
+func (s *AgentServer) ListToolExecutions(ctx context.Context, req *proto.ToolExecutionsRequest) (*proto.ToolExecutionsResponse, error) {
+	resp := &proto.ToolExecutionsResponse{}
+	for _, rec := range s.toolRuns.List() {
+		execution := toolExecutionStatus(rec)
+		if req.Name != "" && execution.Name != req.Name || req.Action != "" && execution.Action != req.Action {
+			continue
+		}
+		resp.Executions = append(resp.Executions, execution)
+	}
+	return resp, nil
+}
 
-	return &proto.ToolExecutionStatusResponse{
-		Name:   req.Name,
-		Action: req.Action,
-		Status: toolActionStatus,
-		Result: encodedResult,
-	}, nil
+// toolExecutionStatus converts a tool execution record to its status message
+func toolExecutionStatus(rec tasks.Record) *proto.ToolExecutionStatusResponse {
+	name, action, _ := strings.Cut(rec.JobID, "/")
+	result := rec.Result
+	if result == "" && rec.Status == proto.TaskStatus_FAILED {
+		result = rec.Error
+	}
+	resp := &proto.ToolExecutionStatusResponse{
+		Name:   name,
+		Action: action,
+		Status: rec.Status,
+		// Encode the result to base64 to ensure safe transmission
+		Result:      base64.StdEncoding.EncodeToString([]byte(result)),
+		Execution:   executionResult(rec.Execution),
+		ExecutionId: rec.TaskID,
+		Error:       rec.Error,
+	}
+	if !rec.StartedAt.IsZero() {
+		resp.StartedAt = timestamppb.New(rec.StartedAt)
+	}
+	if !rec.EndedAt.IsZero() {
+		resp.FinishedAt = timestamppb.New(rec.EndedAt)
+	}
+	return resp
 }
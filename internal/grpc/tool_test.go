@@ -0,0 +1,155 @@
+package agentgrpc
+
+import (
+	"context"
+	"encoding/base64"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"openshield-agent/internal/executor"
+	"openshield-agent/internal/tasks"
+	"openshield-agent/internal/tools"
+	"openshield-agent/proto"
+)
+
+func TestToolExecutions(t *testing.T) {
+	release := make(chan struct{})
+	run := func(_ context.Context, opts tools.Values, _ executor.Options) (*executor.Result, error) {
+		<-release
+		return &executor.Result{Output: "done"}, nil
+	}
+	tools.RegisterTool(tools.Tool{Name: "test-slow", OS: []string{runtime.GOOS}, Actions: []tools.Action{{Name: "run", Exec: run}}})
+	tools.RegisterTool(tools.Tool{Name: "test-other", OS: []string{runtime.GOOS}, Actions: []tools.Action{{Name: "run", Exec: run}}})
+
+	s := &AgentServer{toolRuns: tasks.NewRegistry(4)}
+	ctx := context.Background()
+	execute := func(name string) *proto.ExecuteToolResponse {
+		t.Helper()
+		resp, err := s.ExecuteTool(ctx, &proto.ExecuteToolRequest{Name: name, Action: "run"})
+		if err != nil {
+			t.Fatalf("ExecuteTool(%s) error = %v", name, err)
+		}
+		return resp
+	}
+
+	first := execute("test-slow")
+	if !first.Accepted || first.ExecutionId == "" {
+		t.Fatalf("ExecuteTool() = %+v, want an accepted execution", first)
+	}
+	// The same tool is locked, another tool is not
+	if busy := execute("test-slow"); busy.Accepted {
+		t.Error("ExecuteTool() accepted a second execution of a running tool")
+	}
+	other := execute("test-other")
+	if !other.Accepted {
+		t.Errorf("ExecuteTool() rejected another tool: %s", other.Message)
+	}
+	if missing := execute("test-missing"); missing.Accepted {
+		t.Error("ExecuteTool() accepted an unknown tool")
+	}
+
+	close(release)
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		st, err := s.ReportToolExecutionStatus(ctx, &proto.ToolExecutionStatusRequest{ExecutionId: first.ExecutionId})
+		if err != nil {
+			t.Fatalf("ReportToolExecutionStatus() error = %v", err)
+		}
+		if st.Status == proto.TaskStatus_COMPLETED {
+			if st.Name != "test-slow" || st.Action != "run" {
+				t.Errorf("status is for %s %s, want test-slow run", st.Name, st.Action)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("execution still %v", st.Status)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// The lock is released once the execution finished
+	for !execute("test-slow").Accepted {
+		if time.Now().After(deadline) {
+			t.Fatal("tool lock was not released")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	list, err := s.ListToolExecutions(ctx, &proto.ToolExecutionsRequest{Name: "test-slow"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Executions) != 2 || list.Executions[0].ExecutionId != first.ExecutionId {
+		t.Errorf("ListToolExecutions() = %v, want both executions of test-slow, oldest first", list.Executions)
+	}
+}
+
+func TestToolExecutionPanic(t *testing.T) {
+	run := func(_ context.Context, opts tools.Values, _ executor.Options) (*executor.Result, error) {
+		panic("broken action")
+	}
+	tools.RegisterTool(tools.Tool{Name: "test-panic", OS: []string{runtime.GOOS}, Actions: []tools.Action{{Name: "run", Exec: run}}})
+
+	s := &AgentServer{toolRuns: tasks.NewRegistry(4)}
+	ctx := context.Background()
+	resp, err := s.ExecuteTool(ctx, &proto.ExecuteToolRequest{Name: "test-panic", Action: "run"})
+	if err != nil || !resp.Accepted {
+		t.Fatalf("ExecuteTool() = %+v, %v", resp, err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		st, err := s.ReportToolExecutionStatus(ctx, &proto.ToolExecutionStatusRequest{ExecutionId: resp.ExecutionId})
+		if err != nil {
+			t.Fatalf("ReportToolExecutionStatus() error = %v", err)
+		}
+		if st.Status == proto.TaskStatus_FAILED {
+			// Results are base64 encoded like the agent always did
+			if result, _ := base64.StdEncoding.DecodeString(st.Result); !strings.Contains(string(result), "broken action") {
+				t.Errorf("Result = %q, want the panic message", result)
+			}
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("execution still %v", st.Status)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestToolExecutionCancel(t *testing.T) {
+	stopped := make(chan struct{})
+	run := func(ctx context.Context, opts tools.Values, _ executor.Options) (*executor.Result, error) {
+		<-ctx.Done()
+		close(stopped)
+		return nil, ctx.Err()
+	}
+	tools.RegisterTool(tools.Tool{Name: "test-cancel", OS: []string{runtime.GOOS}, Actions: []tools.Action{{Name: "run", Exec: run}}})
+
+	s := &AgentServer{toolRuns: tasks.NewRegistry(4)}
+	resp, err := s.ExecuteTool(context.Background(), &proto.ExecuteToolRequest{Name: "test-cancel", Action: "run"})
+	if err != nil || !resp.Accepted {
+		t.Fatalf("ExecuteTool() = %+v, %v", resp, err)
+	}
+	// Cancel once the action runs, not while it waits for its slot
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if rec, _ := s.toolRuns.Get(resp.ExecutionId); rec.Status == proto.TaskStatus_RUNNING {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("execution did not start")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err := s.toolRuns.Cancel(resp.ExecutionId); err != nil {
+		t.Fatalf("Cancel() error = %v", err)
+	}
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("action did not stop when its execution was cancelled")
+	}
+}
@@ -0,0 +1,167 @@
+package agentgrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+
+	"openshield-agent/internal/scripts"
+	"openshield-agent/internal/uploads"
+	"openshield-agent/proto"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// uploadKey identifies the partial file of an upload.
+func uploadKey(kind proto.FileKind, sessionID, filename string) []string {
+	return []string{kind.String(), sessionID, filename}
+}
+
+// validUploadName checks the file name of an upload. Scripts may be files of
+// a script package; config files are always directly in the config directory.
+func validUploadName(kind proto.FileKind, name string) bool {
+	if kind == proto.FileKind_CONFIG && strings.Contains(name, "/") {
+		return false
+	}
+	return scripts.ValidName(name)
+}
+
+// checkUploadSession rejects session IDs that cannot belong to a script sync
+// session, so they never become part of a partial file's name.
+func checkUploadSession(sessionID string) error {
+	if sessionID != "" && !scripts.ValidSessionID(sessionID) {
+		return status.Errorf(codes.InvalidArgument, "invalid session ID %q", sessionID)
+	}
+	return nil
+}
+
+// UploadFile receives a script or config file in chunks. The file is only
+// installed once the trailer's checksum matches; otherwise the received part
+// is kept so the upload can be resumed.
+func (s *AgentServer) UploadFile(stream proto.AgentService_UploadFileServer) error {
+	msg, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	header := msg.GetHeader()
+	if header == nil {
+		return status.Error(codes.InvalidArgument, "upload must start with a header")
+	}
+	if !validUploadName(header.Kind, header.Filename) {
+		return status.Errorf(codes.InvalidArgument, "invalid file name %q", header.Filename)
+	}
+	if err := checkUploadSession(header.SessionId); err != nil {
+		return err
+	}
+	log.Printf("[UPLOAD] Receiving %s file %s from offset %d", header.Kind, header.Filename, header.Offset)
+
+	upload, err := uploads.Open(int64(header.Offset), uploadKey(header.Kind, header.SessionId, header.Filename)...)
+	switch {
+	case errors.Is(err, uploads.ErrInProgress):
+		return status.Errorf(codes.Aborted, "failed to open upload: %v", err)
+	case errors.Is(err, uploads.ErrTooLarge):
+		return status.Errorf(codes.ResourceExhausted, "failed to open upload: %v", err)
+	case err != nil:
+		return status.Errorf(codes.FailedPrecondition, "failed to open upload: %v", err)
+	}
+	defer upload.Close()
+
+	for {
+		msg, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			// The sender stopped without a trailer, keep the part for a resume
+			return stream.SendAndClose(&proto.UploadFileResponse{
+				Success:  false,
+				Message:  "upload incomplete",
+				Received: uint64(upload.Size()),
+			})
+		}
+		if err != nil {
+			log.Printf("[UPLOAD] Upload of %s interrupted at %d bytes: %v", header.Filename, upload.Size(), err)
+			return err
+		}
+
+		switch payload := msg.Payload.(type) {
+		case *proto.UploadFileMessage_Chunk:
+			if _, err := upload.Write(payload.Chunk); err != nil {
+				if errors.Is(err, uploads.ErrTooLarge) {
+					upload.Discard()
+					return status.Errorf(codes.ResourceExhausted, "upload of %s exceeds %d bytes", header.Filename, uploads.MaxSize)
+				}
+				return status.Errorf(codes.Internal, "failed to write upload: %v", err)
+			}
+		case *proto.UploadFileMessage_Trailer:
+			received := uint64(upload.Size())
+			err := s.finishUpload(header, upload, payload.Trailer)
+			if err != nil {
+				log.Printf("[UPLOAD] Rejected %s: %v", header.Filename, err)
+				return stream.SendAndClose(&proto.UploadFileResponse{Success: false, Message: err.Error()})
+			}
+			log.Printf("[UPLOAD] Received %s (%d bytes)", header.Filename, received)
+			return stream.SendAndClose(&proto.UploadFileResponse{
+				Success:  true,
+				Message:  "File uploaded successfully",
+				Received: received,
+			})
+		default:
+			return status.Error(codes.InvalidArgument, "unexpected message in upload")
+		}
+	}
+}
+
+// finishUpload verifies a complete upload and installs it like the unary
+// SendScriptFile, StageScriptFile and SendConfigFile calls would.
+func (s *AgentServer) finishUpload(header *proto.UploadHeader, upload *uploads.Upload, trailer *proto.UploadTrailer) error {
+	path, err := upload.Finish(trailer.Sha256)
+	if err != nil {
+		return err
+	}
+
+	switch header.Kind {
+	case proto.FileKind_SCRIPT:
+		if header.SessionId != "" {
+			session, err := scripts.GetSession(header.SessionId)
+			if err == nil {
+				err = session.StageFile(header.Filename, path, trailer.Signature, trailer.Certificate)
+			}
+			if err != nil {
+				upload.Discard()
+				return fmt.Errorf("script rejected: %w", err)
+			}
+			return nil
+		}
+		if err := scripts.SaveFile(header.Filename, path, trailer.Signature, trailer.Certificate); err != nil {
+			upload.Discard()
+			return fmt.Errorf("script rejected: %w", err)
+		}
+		return nil
+	case proto.FileKind_CONFIG:
+		if err := installConfigFile(header.Filename, path); err != nil {
+			upload.Discard()
+			return err
+		}
+		return nil
+	default:
+		upload.Discard()
+		return fmt.Errorf("unsupported file kind %s", header.Kind)
+	}
+}
+
+// GetUploadOffset returns how much of an interrupted upload was received
+func (s *AgentServer) GetUploadOffset(ctx context.Context, req *proto.UploadOffsetRequest) (*proto.UploadOffsetResponse, error) {
+	if !validUploadName(req.Kind, req.Filename) {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid file name %q", req.Filename)
+	}
+	if err := checkUploadSession(req.SessionId); err != nil {
+		return nil, err
+	}
+	offset, err := uploads.Offset(uploadKey(req.Kind, req.SessionId, req.Filename)...)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to read upload: %v", err)
+	}
+	return &proto.UploadOffsetResponse{Offset: uint64(offset)}, nil
+}
@@ -0,0 +1,215 @@
+package scripts
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"openshield-agent/internal/config"
+	"openshield-agent/internal/uploads"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// ManifestFilename is the manifest of verified scripts inside the scripts directory.
+const ManifestFilename = ".manifest.json"
+
+// ManifestEntry records the checksum and signature a script was accepted with.
+type ManifestEntry struct {
+	SHA256      string      `json:"sha256"`
+	Version     string      `json:"version,omitempty"`
+	Mode        os.FileMode `json:"mode,omitempty"`
+	Signature   []byte      `json:"signature"`
+	Certificate []byte      `json:"certificate"`
+}
+
+// Manifest maps script names to the entry they were accepted with.
+type Manifest map[string]ManifestEntry
+
+// manifestMu serializes read-modify-write cycles of the manifest file.
+var manifestMu sync.Mutex
+
+// Checksum returns the hex encoded SHA-256 of the content.
+func Checksum(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// LoadManifest reads the manifest from the scripts directory. A missing
+// manifest is treated as empty.
+func LoadManifest() (Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(config.ScriptsPath, ManifestFilename))
+	if os.IsNotExist(err) {
+		return Manifest{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	manifest := Manifest{}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid script manifest: %w", err)
+	}
+	return manifest, nil
+}
+
+// ValidName reports whether name can be used for a file in the scripts
+// directory. Files of script packages are named by their slash separated
+// path, such as "collect-logs/run.sh".
+func ValidName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, part := range strings.Split(name, "/") {
+		if part == "" || strings.HasPrefix(part, ".") || strings.ContainsAny(part, `\:`) {
+			return false
+		}
+	}
+	return true
+}
+
+// scriptPath returns the path of a script file in the given directory.
+func scriptPath(dir, name string) string {
+	return filepath.Join(dir, filepath.FromSlash(name))
+}
+
+// save writes the manifest to the scripts directory.
+func (m Manifest) save() error {
+	return m.saveTo(config.ScriptsPath)
+}
+
+// saveTo writes the manifest atomically to the given directory.
+func (m Manifest) saveTo(dir string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(dir, ManifestFilename)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Save verifies a signed script and writes it and its manifest entry to the
+// scripts directory. Nothing is written if the signature is not valid.
+func Save(name string, content, signature, certPEM []byte) error {
+	if err := VerifySignature(content, signature, certPEM); err != nil {
+		return err
+	}
+	return install(name, Checksum(content), signature, certPEM, func(path string) error {
+		return writeScript(path, content, 0755)
+	})
+}
+
+// SaveFile is Save for a script that was received into the file src, which
+// is moved into the scripts directory.
+func SaveFile(name, src string, signature, certPEM []byte) error {
+	checksum, err := VerifyFile(src, signature, certPEM)
+	if err != nil {
+		return err
+	}
+	return install(name, checksum, signature, certPEM, func(path string) error {
+		return uploads.MoveFile(src, path, 0755)
+	})
+}
+
+// install writes a verified script with write and records it in the manifest.
+func install(name, checksum string, signature, certPEM []byte, write func(path string) error) error {
+	manifestMu.Lock()
+	defer manifestMu.Unlock()
+
+	manifest, err := LoadManifest()
+	if err != nil {
+		return err
+	}
+	path := scriptPath(config.ScriptsPath, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	if err := write(path); err != nil {
+		return err
+	}
+	manifest[name] = ManifestEntry{
+		SHA256:      checksum,
+		Mode:        0755,
+		Signature:   signature,
+		Certificate: certPEM,
+	}
+	return manifest.save()
+}
+
+// Delete removes a script and its manifest entry. Deleting a package removes
+// all of its files.
+func Delete(name string) error {
+	manifestMu.Lock()
+	defer manifestMu.Unlock()
+
+	manifest, err := LoadManifest()
+	if err != nil {
+		return err
+	}
+
+	if IsPackage(name) {
+		if err := os.RemoveAll(scriptPath(config.ScriptsPath, name)); err != nil {
+			return err
+		}
+		for file := range manifest {
+			if strings.HasPrefix(file, name+"/") {
+				delete(manifest, file)
+			}
+		}
+		return manifest.save()
+	}
+
+	path := scriptPath(config.ScriptsPath, name)
+	if err := os.Remove(path); err != nil {
+		return err
+	}
+	// Remove directories of a package left empty
+	for dir := filepath.Dir(path); dir != filepath.Clean(config.ScriptsPath); dir = filepath.Dir(dir) {
+		if os.Remove(dir) != nil {
+			break
+		}
+	}
+	delete(manifest, name)
+	return manifest.save()
+}
+
+// Verify checks that a script on disk is unchanged since it was accepted and
+// that its signature is still trusted.
+func Verify(name string) error {
+	manifest, err := LoadManifest()
+	if err != nil {
+		return err
+	}
+	return verifyEntry(manifest, name)
+}
+
+// verifyEntry checks a script against its entry in the manifest.
+func verifyEntry(manifest Manifest, name string) error {
+	_, err := readVerified(manifest, name)
+	return err
+}
+
+// readVerified reads a script and checks it against its entry in the manifest.
+func readVerified(manifest Manifest, name string) ([]byte, error) {
+	entry, ok := manifest[name]
+	if !ok {
+		return nil, fmt.Errorf("script %s is not in the signed manifest", name)
+	}
+
+	content, err := os.ReadFile(scriptPath(config.ScriptsPath, name))
+	if err != nil {
+		return nil, err
+	}
+	if Checksum(content) != entry.SHA256 {
+		return nil, fmt.Errorf("script %s was modified after it was synced", name)
+	}
+	if err := VerifySignature(content, entry.Signature, entry.Certificate); err != nil {
+		return nil, fmt.Errorf("script %s: %w", name, err)
+	}
+	return content, nil
+}
@@ -0,0 +1,145 @@
+package scripts
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"openshield-agent/internal/config"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newSigner writes a CA to the certs directory and returns a code signing
+// key and certificate issued by it.
+func newSigner(t *testing.T, usage x509.ExtKeyUsage) (ed25519.PrivateKey, []byte) {
+	t.Helper()
+
+	caPub, caKey, _ := ed25519.GenerateKey(rand.Reader)
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, caPub, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER})
+	if err := os.WriteFile(filepath.Join(config.CertsPath, "ca.crt"), caPEM, 0644); err != nil {
+		t.Fatal(err)
+	}
+	ca, _ := x509.ParseCertificate(caDER)
+
+	pub, key, _ := ed25519.GenerateKey(rand.Reader)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "script signer"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca, pub, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func setupDirs(t *testing.T) {
+	t.Helper()
+	scriptsPath, certsPath := config.ScriptsPath, config.CertsPath
+	config.ScriptsPath, config.CertsPath = t.TempDir(), t.TempDir()
+	t.Cleanup(func() { config.ScriptsPath, config.CertsPath = scriptsPath, certsPath })
+}
+
+func TestSaveAndVerify(t *testing.T) {
+	setupDirs(t)
+	key, cert := newSigner(t, x509.ExtKeyUsageCodeSigning)
+	content := []byte("#!/bin/bash\necho hello\n")
+
+	if err := Save("hello.sh", content, ed25519.Sign(key, content), cert); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if err := Verify("hello.sh"); err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+
+	// Modifying the script on disk must be detected
+	path := filepath.Join(config.ScriptsPath, "hello.sh")
+	if err := os.WriteFile(path, []byte("#!/bin/bash\nrm -rf /\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := Verify("hello.sh"); err == nil {
+		t.Error("Verify() accepted a modified script")
+	}
+
+	if err := Delete("hello.sh"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if err := Verify("hello.sh"); err == nil {
+		t.Error("Verify() accepted a deleted script")
+	}
+}
+
+func TestSaveRejects(t *testing.T) {
+	setupDirs(t)
+	key, cert := newSigner(t, x509.ExtKeyUsageCodeSigning)
+	content := []byte("#!/bin/bash\necho hello\n")
+	signature := ed25519.Sign(key, content)
+
+	tests := []struct {
+		name      string
+		content   []byte
+		signature []byte
+		cert      []byte
+	}{
+		{"unsigned", content, nil, cert},
+		{"tampered", []byte("#!/bin/bash\necho bye\n"), signature, cert},
+		{"no certificate", content, signature, nil},
+	}
+	for _, tt := range tests {
+		if err := Save("hello.sh", tt.content, tt.signature, tt.cert); err == nil {
+			t.Errorf("%s: Save() accepted the script", tt.name)
+		}
+	}
+
+	// A certificate from the CA that is not for code signing is not enough
+	key, cert = newSigner(t, x509.ExtKeyUsageClientAuth)
+	if err := Save("hello.sh", content, ed25519.Sign(key, content), cert); err == nil {
+		t.Error("Save() accepted a signer without the code signing usage")
+	}
+
+	if _, err := os.Stat(filepath.Join(config.ScriptsPath, "hello.sh")); !os.IsNotExist(err) {
+		t.Error("rejected script was written to disk")
+	}
+}
+
+func TestSaveFile(t *testing.T) {
+	setupDirs(t)
+	key, cert := newSigner(t, x509.ExtKeyUsageCodeSigning)
+	content := []byte("#!/bin/bash\necho uploaded\n")
+
+	src := filepath.Join(t.TempDir(), "upload.part")
+	if err := os.WriteFile(src, content, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveFile("uploaded.sh", src, ed25519.Sign(key, []byte("other")), cert); err == nil {
+		t.Fatal("SaveFile() accepted a wrong signature")
+	}
+	if err := SaveFile("uploaded.sh", src, ed25519.Sign(key, content), cert); err != nil {
+		t.Fatalf("SaveFile() error = %v", err)
+	}
+	if err := Verify("uploaded.sh"); err != nil {
+		t.Errorf("Verify() error = %v", err)
+	}
+}
@@ -0,0 +1,192 @@
+package scripts
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Interpreters scripts can declare.
+const (
+	Bash    = "bash"
+	Sh      = "sh"
+	Python3 = "python3"
+	Pwsh    = "pwsh"
+)
+
+// Privileges a script can require.
+const (
+	PrivilegesUser = "user"
+	PrivilegesRoot = "root" // administrator on Windows
+)
+
+// SidecarSuffix is appended to a script's file name for its metadata file,
+// for scripts that cannot carry front matter.
+const SidecarSuffix = ".yml"
+
+// ArgSpec describes a positional argument of a script. An argument with
+// Values or Pattern must be one of the values or match the pattern, which
+// has to match the whole argument.
+type ArgSpec struct {
+	Name        string   `yaml:"name"`
+	Description string   `yaml:"description"`
+	Required    bool     `yaml:"required"`
+	Values      []string `yaml:"values"`
+	Pattern     string   `yaml:"pattern"`
+
+	pattern *regexp.Regexp // compiled and anchored Pattern, set by validate
+}
+
+// Metadata is what a script declares about how it runs. All fields are
+// optional; a script without metadata runs with the OS's default shell.
+type Metadata struct {
+	Description string    `yaml:"description"`
+	Interpreter string    `yaml:"interpreter"` // bash, sh, python3 or pwsh
+	OS          []string  `yaml:"os"`          // supported OSes, all if empty
+	Args        []ArgSpec `yaml:"args"`        // nil accepts any arguments
+	Timeout     string    `yaml:"timeout"`     // default timeout, e.g. "5m"
+	Privileges  string    `yaml:"privileges"`  // user or root
+}
+
+// ParseMetadata parses and validates a metadata document.
+func ParseMetadata(data []byte) (*Metadata, error) {
+	var m Metadata
+	if err := yaml.UnmarshalStrict(data, &m); err != nil {
+		return nil, err
+	}
+	if err := m.validate(); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (m *Metadata) validate() error {
+	switch m.Interpreter {
+	case "", Bash, Sh, Python3, Pwsh:
+	default:
+		return fmt.Errorf("unsupported interpreter %q", m.Interpreter)
+	}
+	switch m.Privileges {
+	case "", PrivilegesUser, PrivilegesRoot:
+	default:
+		return fmt.Errorf("unsupported privileges %q", m.Privileges)
+	}
+	if _, err := m.TimeoutDuration(); err != nil {
+		return err
+	}
+	for i := range m.Args {
+		arg := &m.Args[i]
+		if arg.Pattern != "" {
+			// Compiling the pattern on its own first keeps it from closing
+			// the group that anchors it
+			if _, err := regexp.Compile(arg.Pattern); err != nil {
+				return fmt.Errorf("invalid pattern for argument %s: %w", arg.Name, err)
+			}
+			arg.pattern = regexp.MustCompile(`^(?:` + arg.Pattern + `)$`)
+		}
+	}
+	return nil
+}
+
+// TimeoutDuration returns the declared default timeout, or 0 if none.
+func (m *Metadata) TimeoutDuration() (time.Duration, error) {
+	if m.Timeout == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(m.Timeout)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid timeout %q", m.Timeout)
+	}
+	return d, nil
+}
+
+// SupportsOS reports whether the script can run on the given OS.
+func (m *Metadata) SupportsOS(goos string) bool {
+	if len(m.OS) == 0 {
+		return true
+	}
+	for _, os := range m.OS {
+		if os == goos {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateArgs checks job arguments against the declared argument schema.
+func (m *Metadata) ValidateArgs(args []string) error {
+	if m.Args == nil {
+		return nil
+	}
+	if len(args) > len(m.Args) {
+		return fmt.Errorf("script accepts at most %d arguments, got %d", len(m.Args), len(args))
+	}
+	for i, spec := range m.Args {
+		if i >= len(args) {
+			if spec.Required {
+				return fmt.Errorf("missing required argument %s", spec.Name)
+			}
+			continue
+		}
+		if !spec.matches(args[i]) {
+			return fmt.Errorf("invalid value %q for argument %s", args[i], spec.Name)
+		}
+	}
+	return nil
+}
+
+func (a ArgSpec) matches(arg string) bool {
+	if len(a.Values) == 0 && a.Pattern == "" {
+		return true
+	}
+	for _, v := range a.Values {
+		if arg == v {
+			return true
+		}
+	}
+	return a.pattern != nil && a.pattern.MatchString(arg)
+}
+
+// frontMatter extracts the metadata block at the top of a script. The block
+// is delimited by "# ---" lines, each line of it commented with "#", and may
+// follow a shebang line:
+//
+//	#!/bin/bash
+//	# ---
+//	# interpreter: bash
+//	# timeout: 5m
+//	# ---
+func frontMatter(content []byte) ([]byte, bool) {
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	var block bytes.Buffer
+	inBlock := false
+	for first := true; scanner.Scan(); first = false {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if first && strings.HasPrefix(line, "#!") {
+			continue
+		}
+		if strings.TrimSpace(line) == "# ---" {
+			if inBlock {
+				return block.Bytes(), true
+			}
+			inBlock = true
+			continue
+		}
+		if !inBlock {
+			return nil, false
+		}
+		if !strings.HasPrefix(line, "#") {
+			return nil, false
+		}
+		// Strip the comment marker and the space after it, keeping the indentation
+		line = strings.TrimPrefix(strings.TrimPrefix(line, "#"), " ")
+		block.WriteString(line)
+		block.WriteByte('\n')
+	}
+	return nil, false
+}
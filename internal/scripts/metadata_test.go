@@ -0,0 +1,131 @@
+package scripts
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"openshield-agent/internal/config"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFrontMatter(t *testing.T) {
+	content := []byte(`#!/bin/bash
+# ---
+# interpreter: sh
+# os: [linux]
+# timeout: 5m
+# args:
+#   - name: level
+#     required: true
+#     values: [quick, full]
+# ---
+echo scanning
+`)
+	block, ok := frontMatter(content)
+	if !ok {
+		t.Fatal("front matter not found")
+	}
+	meta, err := ParseMetadata(block)
+	if err != nil {
+		t.Fatalf("ParseMetadata() error = %v", err)
+	}
+	if meta.Interpreter != Sh || !meta.SupportsOS("linux") || meta.SupportsOS("windows") {
+		t.Errorf("unexpected metadata %+v", meta)
+	}
+	if d, _ := meta.TimeoutDuration(); d != 5*time.Minute {
+		t.Errorf("TimeoutDuration() = %v, want 5m", d)
+	}
+
+	if err := meta.ValidateArgs([]string{"full"}); err != nil {
+		t.Errorf("ValidateArgs(full) error = %v", err)
+	}
+	for _, args := range [][]string{nil, {"deep"}, {"full", "extra"}} {
+		if err := meta.ValidateArgs(args); err == nil {
+			t.Errorf("ValidateArgs(%q) accepted invalid arguments", args)
+		}
+	}
+
+	if _, ok := frontMatter([]byte("#!/bin/bash\necho no metadata\n")); ok {
+		t.Error("front matter found in a script without one")
+	}
+}
+
+func TestArgPatternAnchored(t *testing.T) {
+	meta, err := ParseMetadata([]byte("args:\n  - name: port\n    pattern: '[0-9]+'\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := meta.ValidateArgs([]string{"8080"}); err != nil {
+		t.Errorf("ValidateArgs(8080) error = %v", err)
+	}
+	for _, arg := range []string{"80; rm -rf /", "x80", ""} {
+		if err := meta.ValidateArgs([]string{arg}); err == nil {
+			t.Errorf("ValidateArgs(%q) accepted a partial match", arg)
+		}
+	}
+	if _, err := ParseMetadata([]byte("args:\n  - name: x\n    pattern: 'a)|(.*'\n")); err == nil {
+		t.Error("ParseMetadata() accepted a pattern that escapes its anchors")
+	}
+}
+
+func TestParseMetadataRejects(t *testing.T) {
+	for _, doc := range []string{
+		"interpreter: perl",
+		"privileges: admin",
+		"timeout: soon",
+		"unknown: field",
+	} {
+		if _, err := ParseMetadata([]byte(doc)); err == nil {
+			t.Errorf("ParseMetadata(%q) accepted invalid metadata", doc)
+		}
+	}
+
+	meta, err := ParseMetadata([]byte("args: []"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := meta.ValidateArgs([]string{"x"}); err == nil {
+		t.Error("empty argument schema accepted an argument")
+	}
+}
+
+func TestLookupSidecar(t *testing.T) {
+	setupDirs(t)
+	key, cert := newSigner(t, x509.ExtKeyUsageCodeSigning)
+
+	script := []byte("import sys\nprint(sys.argv)\n")
+	sidecar := []byte("interpreter: python3\nprivileges: root\n")
+	if err := Save("report.py", script, ed25519.Sign(key, script), cert); err != nil {
+		t.Fatal(err)
+	}
+	if err := Save("report.py.yml", sidecar, ed25519.Sign(key, sidecar), cert); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := Lookup("report.py")
+	if err != nil {
+		t.Fatalf("Lookup() error = %v", err)
+	}
+	if s.Metadata.Interpreter != Python3 || s.Metadata.Privileges != PrivilegesRoot {
+		t.Errorf("unexpected metadata %+v", s.Metadata)
+	}
+
+	// A sidecar changed on disk must not change how the script runs
+	path := filepath.Join(config.ScriptsPath, "report.py.yml")
+	if err := os.WriteFile(path, []byte("interpreter: sh\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Lookup("report.py"); err == nil {
+		t.Error("Lookup() accepted a modified sidecar")
+	}
+
+	// Nor can removing it fall back to the script's front matter
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Lookup("report.py"); err == nil {
+		t.Error("Lookup() accepted a missing sidecar")
+	}
+}
@@ -0,0 +1,148 @@
+package scripts
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"openshield-agent/internal/config"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// PackageFilename is the metadata file that makes a directory in the scripts
+// directory a script package.
+const PackageFilename = "package.yml"
+
+// Package is a directory of files run through its entrypoint. Its metadata
+// file declares the entrypoint and the same fields as a script's metadata.
+type Package struct {
+	Name       string   `yaml:"-"`
+	Entrypoint string   `yaml:"entrypoint"` // path of the script to run, relative to the package
+	Metadata   Metadata `yaml:",inline"`
+}
+
+// Dir returns the directory of the package.
+func (p *Package) Dir() string {
+	return filepath.Join(config.ScriptsPath, p.Name)
+}
+
+// EntrypointPath returns the path of the package's entrypoint.
+func (p *Package) EntrypointPath() string {
+	return filepath.Join(p.Dir(), filepath.FromSlash(p.Entrypoint))
+}
+
+// IsPackage reports whether name is a directory in the scripts directory.
+func IsPackage(name string) bool {
+	if !ValidName(name) || strings.Contains(name, "/") {
+		return false
+	}
+	info, err := os.Stat(filepath.Join(config.ScriptsPath, name))
+	return err == nil && info.IsDir()
+}
+
+// LoadPackage reads the metadata of a script package.
+func LoadPackage(name string) (*Package, error) {
+	if !IsPackage(name) {
+		return nil, fmt.Errorf("script package %s not found", name)
+	}
+	data, err := os.ReadFile(filepath.Join(config.ScriptsPath, name, PackageFilename))
+	if err != nil {
+		return nil, fmt.Errorf("script package %s has no %s: %w", name, PackageFilename, err)
+	}
+	return parsePackage(name, data)
+}
+
+// parsePackage parses the metadata file of a script package.
+func parsePackage(name string, data []byte) (*Package, error) {
+	pkg := &Package{Name: name}
+	if err := yaml.UnmarshalStrict(data, pkg); err != nil {
+		return nil, fmt.Errorf("invalid %s in script package %s: %w", PackageFilename, name, err)
+	}
+	if !ValidName(pkg.Entrypoint) {
+		return nil, fmt.Errorf("script package %s has an invalid entrypoint %q", name, pkg.Entrypoint)
+	}
+	if err := pkg.Metadata.validate(); err != nil {
+		return nil, fmt.Errorf("invalid %s in script package %s: %w", PackageFilename, name, err)
+	}
+	return pkg, nil
+}
+
+// VerifyPackage checks every file of a package against the signed manifest.
+// Files that are not in the manifest are refused as well, so nothing can be
+// added to a package on disk.
+func VerifyPackage(name string) error {
+	manifest, err := LoadManifest()
+	if err != nil {
+		return err
+	}
+	return verifyPackage(manifest, name)
+}
+
+func verifyPackage(manifest Manifest, name string) error {
+	prefix := name + "/"
+	expected := map[string]bool{}
+	for file := range manifest {
+		if strings.HasPrefix(file, prefix) {
+			expected[file] = true
+		}
+	}
+	if !expected[path.Join(name, PackageFilename)] {
+		return fmt.Errorf("script package %s is not in the signed manifest", name)
+	}
+
+	files, err := ListFiles(filepath.Join(config.ScriptsPath, name))
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		file = path.Join(name, file)
+		if !expected[file] {
+			return fmt.Errorf("file %s is not in the signed manifest", file)
+		}
+		delete(expected, file)
+		if err := verifyEntry(manifest, file); err != nil {
+			return err
+		}
+	}
+	for file := range expected {
+		return fmt.Errorf("file %s of script package %s is missing", file, name)
+	}
+	return nil
+}
+
+// ListFiles returns the files below dir as slash separated paths relative to
+// it. Hidden files and directories are agent state and are skipped.
+func ListFiles(dir string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if p == dir {
+			return nil
+		}
+		if strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return errors.New("script directory contains a file that is not regular: " + p)
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	return files, err
+}
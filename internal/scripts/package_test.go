@@ -0,0 +1,77 @@
+package scripts
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"openshield-agent/internal/config"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPackage(t *testing.T) {
+	setupDirs(t)
+	key, cert := newSigner(t, x509.ExtKeyUsageCodeSigning)
+
+	files := map[string][]byte{
+		"collect/package.yml":   []byte("entrypoint: bin/run.sh\ndescription: Collect logs\n"),
+		"collect/bin/run.sh":    []byte("#!/bin/bash\n. ./lib/common.sh\n"),
+		"collect/lib/common.sh": []byte("echo common\n"),
+	}
+	for name, content := range files {
+		if err := Save(name, content, ed25519.Sign(key, content), cert); err != nil {
+			t.Fatalf("Save(%s) error = %v", name, err)
+		}
+	}
+
+	pkg, err := LoadPackage("collect")
+	if err != nil {
+		t.Fatalf("LoadPackage() error = %v", err)
+	}
+	if want := filepath.Join(config.ScriptsPath, "collect", "bin", "run.sh"); pkg.EntrypointPath() != want {
+		t.Errorf("EntrypointPath() = %s, want %s", pkg.EntrypointPath(), want)
+	}
+	if err := VerifyPackage("collect"); err != nil {
+		t.Fatalf("VerifyPackage() error = %v", err)
+	}
+
+	// A file added to the package on disk is refused
+	extra := filepath.Join(config.ScriptsPath, "collect", "lib", "extra.sh")
+	if err := os.WriteFile(extra, []byte("echo extra\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyPackage("collect"); err == nil {
+		t.Error("VerifyPackage() accepted a file missing from the manifest")
+	}
+	os.Remove(extra)
+
+	if err := Delete("collect"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if IsPackage("collect") {
+		t.Error("package directory was not removed")
+	}
+	manifest, _ := LoadManifest()
+	if len(manifest) != 0 {
+		t.Errorf("manifest still has %d entries", len(manifest))
+	}
+}
+
+func TestValidName(t *testing.T) {
+	tests := map[string]bool{
+		"run.sh":           true,
+		"pkg/bin/run.sh":   true,
+		"":                 false,
+		"../run.sh":        false,
+		"pkg//run.sh":      false,
+		"/etc/passwd":      false,
+		".manifest.json":   false,
+		`pkg\..\run.sh`:    false,
+		"C:/Windows/x.ps1": false,
+	}
+	for name, want := range tests {
+		if got := ValidName(name); got != want {
+			t.Errorf("ValidName(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
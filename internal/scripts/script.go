@@ -0,0 +1,139 @@
+package scripts
+
+import (
+	"fmt"
+	"openshield-agent/internal/config"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+)
+
+var (
+	scriptNamePattern  = regexp.MustCompile(`^[a-zA-Z0-9_\-]+\.(sh|ps.*|py)$`)
+	packageNamePattern = regexp.MustCompile(`^[a-zA-Z0-9_\-]+$`)
+)
+
+// Script is a runnable script file or script package.
+type Script struct {
+	Name     string
+	Path     string // file to run
+	Dir      string // package directory, empty for a script file
+	Metadata Metadata
+}
+
+// Lookup finds the script file or package with the given name, checks it
+// against the signed manifest and reads its metadata.
+func Lookup(name string) (*Script, error) {
+	manifest, err := LoadManifest()
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case scriptNamePattern.MatchString(name):
+		// Refuse scripts that were modified or whose signer is no longer trusted
+		content, err := readVerified(manifest, name)
+		if err != nil {
+			return nil, err
+		}
+		meta, err := scriptMetadata(name, content, manifest, func(sidecar string) ([]byte, error) {
+			return readVerified(manifest, sidecar)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("invalid metadata for script %s: %w", name, err)
+		}
+		return &Script{Name: name, Path: scriptPath(config.ScriptsPath, name), Metadata: *meta}, nil
+	case packageNamePattern.MatchString(name):
+		if !IsPackage(name) {
+			return nil, fmt.Errorf("script package %s not found", name)
+		}
+		if err := verifyPackage(manifest, name); err != nil {
+			return nil, err
+		}
+		data, err := readVerified(manifest, path.Join(name, PackageFilename))
+		if err != nil {
+			return nil, err
+		}
+		pkg, err := parsePackage(name, data)
+		if err != nil {
+			return nil, err
+		}
+		return &Script{Name: name, Path: pkg.EntrypointPath(), Dir: pkg.Dir(), Metadata: pkg.Metadata}, nil
+	default:
+		return nil, fmt.Errorf("invalid script name")
+	}
+}
+
+// scriptMetadata returns the metadata of a script file from its sidecar file
+// if the manifest lists one, otherwise from its front matter. A listed
+// sidecar must be readable, so removing it cannot change how a script runs.
+func scriptMetadata(name string, content []byte, manifest Manifest, read func(sidecar string) ([]byte, error)) (*Metadata, error) {
+	sidecar := name + SidecarSuffix
+	if _, ok := manifest[sidecar]; ok {
+		data, err := read(sidecar)
+		if err != nil {
+			return nil, err
+		}
+		return ParseMetadata(data)
+	}
+	if block, ok := frontMatter(content); ok {
+		return ParseMetadata(block)
+	}
+	return &Metadata{}, nil
+}
+
+// Listing is a script file or package in the scripts directory. Err is set if
+// its metadata cannot be read.
+type Listing struct {
+	Name     string
+	Package  bool
+	Metadata Metadata
+	Err      error
+}
+
+// List returns the script files and packages in the scripts directory with
+// their metadata. Unlike Lookup it does not verify files against the signed
+// manifest, it only uses it to find sidecar files.
+func List() ([]Listing, error) {
+	entries, err := os.ReadDir(config.ScriptsPath)
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := LoadManifest()
+	if err != nil {
+		return nil, err
+	}
+
+	var list []Listing
+	for _, entry := range entries {
+		name := entry.Name()
+		switch {
+		case entry.IsDir() && packageNamePattern.MatchString(name):
+			l := Listing{Name: name, Package: true}
+			if pkg, err := LoadPackage(name); err != nil {
+				l.Err = err
+			} else {
+				l.Metadata = pkg.Metadata
+			}
+			list = append(list, l)
+		case entry.Type().IsRegular() && scriptNamePattern.MatchString(name):
+			l := Listing{Name: name}
+			content, err := os.ReadFile(filepath.Join(config.ScriptsPath, name))
+			if err == nil {
+				var meta *Metadata
+				meta, err = scriptMetadata(name, content, manifest, func(sidecar string) ([]byte, error) {
+					return os.ReadFile(scriptPath(config.ScriptsPath, sidecar))
+				})
+				if meta != nil {
+					l.Metadata = *meta
+				}
+			}
+			l.Err = err
+			list = append(list, l)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list, nil
+}
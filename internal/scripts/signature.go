@@ -0,0 +1,139 @@
+package scripts
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"openshield-agent/internal/config"
+	"os"
+	"path/filepath"
+)
+
+// VerifySignature checks that signature is a valid signature of content made
+// by the key of the PEM encoded certificate, and that the certificate was
+// issued for code signing by the agent's CA.
+//
+// RSA keys sign with PKCS #1 v1.5 over SHA-256, ECDSA keys sign the SHA-256
+// digest, and Ed25519 keys sign the content itself.
+func VerifySignature(content, signature, certPEM []byte) error {
+	cert, err := trustedSigner(signature, certPEM)
+	if err != nil {
+		return err
+	}
+
+	var algorithm x509.SignatureAlgorithm
+	switch cert.PublicKey.(type) {
+	case *rsa.PublicKey:
+		algorithm = x509.SHA256WithRSA
+	case *ecdsa.PublicKey:
+		algorithm = x509.ECDSAWithSHA256
+	case ed25519.PublicKey:
+		algorithm = x509.PureEd25519
+	default:
+		return errors.New("unsupported signer key type")
+	}
+	if err := cert.CheckSignature(algorithm, content, signature); err != nil {
+		return fmt.Errorf("signature does not match script content: %w", err)
+	}
+	return nil
+}
+
+// VerifyFile is VerifySignature for a file on disk, and returns the file's
+// checksum. RSA and ECDSA signatures are checked against a streamed digest;
+// Ed25519 signatures need the whole file in memory.
+func VerifyFile(path string, signature, certPEM []byte) (string, error) {
+	cert, err := trustedSigner(signature, certPEM)
+	if err != nil {
+		return "", err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	if pub, ok := cert.PublicKey.(ed25519.PublicKey); ok {
+		content, err := io.ReadAll(f)
+		if err != nil {
+			return "", err
+		}
+		if !ed25519.Verify(pub, content, signature) {
+			return "", errors.New("signature does not match script content")
+		}
+		return Checksum(content), nil
+	}
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	digest := h.Sum(nil)
+
+	switch pub := cert.PublicKey.(type) {
+	case *rsa.PublicKey:
+		err = rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest, signature)
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(pub, digest, signature) {
+			err = errors.New("invalid ECDSA signature")
+		}
+	default:
+		return "", errors.New("unsupported signer key type")
+	}
+	if err != nil {
+		return "", fmt.Errorf("signature does not match script content: %w", err)
+	}
+	return hex.EncodeToString(digest), nil
+}
+
+// trustedSigner parses the signer certificate and checks it was issued for
+// code signing by the agent's CA.
+func trustedSigner(signature, certPEM []byte) (*x509.Certificate, error) {
+	if len(signature) == 0 {
+		return nil, errors.New("script is not signed")
+	}
+
+	block, _ := pem.Decode(certPEM)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, errors.New("signer certificate is not a PEM encoded certificate")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signer certificate: %w", err)
+	}
+
+	roots, err := loadCA()
+	if err != nil {
+		return nil, err
+	}
+	_, err = cert.Verify(x509.VerifyOptions{
+		Roots:     roots,
+		KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("signer certificate not trusted: %w", err)
+	}
+	return cert, nil
+}
+
+// loadCA reads the agent's CA certificate.
+func loadCA() (*x509.CertPool, error) {
+	path := config.Get().TLS.CAPath()
+	caCert, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA certificate: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caCert) {
+		return nil, fmt.Errorf("no certificates found in %s", filepath.Base(path))
+	}
+	return pool, nil
+}
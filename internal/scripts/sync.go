@@ -0,0 +1,335 @@
+package scripts
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"openshield-agent/internal/config"
+	"openshield-agent/internal/uploads"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	// sessionTimeout is how long an unfinished sync session can take. After
+	// it, the session is discarded and no longer blocks new ones.
+	sessionTimeout = 10 * time.Minute
+	// sessionIDBytes is the number of random bytes in a session ID, which is
+	// hex encoded.
+	sessionIDBytes = 8
+)
+
+// SyncEntry describes one script of the set the manager wants on the agent.
+type SyncEntry struct {
+	Name    string
+	SHA256  string
+	Version string
+	Mode    os.FileMode
+}
+
+// Session stages a new script set next to the scripts directory until it is
+// committed as a whole.
+type Session struct {
+	ID string
+
+	dir     string
+	started time.Time
+	target  Manifest
+	pending map[string]bool
+}
+
+var (
+	sessionMu sync.Mutex
+	session   *Session
+)
+
+// stagingPrefix and backupDir are siblings of the scripts directory, so the
+// swap is a rename on the same filesystem.
+func stagingPrefix() string { return filepath.Clean(config.ScriptsPath) + ".staging-" }
+func backupDir() string     { return filepath.Clean(config.ScriptsPath) + ".old" }
+
+// BeginSync starts a sync session for the given script set. Scripts that are
+// unchanged are copied to the staging directory; the names of the scripts the
+// manager has to send are returned.
+func BeginSync(entries []SyncEntry) (*Session, []string, error) {
+	sessionMu.Lock()
+	defer sessionMu.Unlock()
+
+	if session != nil && session.active() == nil {
+		return nil, nil, fmt.Errorf("script sync %s is already in progress", session.ID)
+	}
+
+	current, err := LoadManifest()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	id := make([]byte, sessionIDBytes)
+	if _, err := rand.Read(id); err != nil {
+		return nil, nil, err
+	}
+	s := &Session{
+		ID:      hex.EncodeToString(id),
+		started: time.Now(),
+		target:  Manifest{},
+		pending: map[string]bool{},
+	}
+	s.dir = stagingPrefix() + s.ID
+	if err := os.Mkdir(s.dir, 0755); err != nil {
+		return nil, nil, err
+	}
+
+	var needed []string
+	for _, e := range entries {
+		if !ValidName(e.Name) {
+			os.RemoveAll(s.dir)
+			return nil, nil, fmt.Errorf("invalid script name %q", e.Name)
+		}
+		if _, dup := s.target[e.Name]; dup || s.pending[e.Name] {
+			os.RemoveAll(s.dir)
+			return nil, nil, fmt.Errorf("script %s is listed twice", e.Name)
+		}
+
+		entry := ManifestEntry{SHA256: e.SHA256, Version: e.Version, Mode: e.Mode}
+		if old, ok := current[e.Name]; ok && old.SHA256 == e.SHA256 && s.copyCurrent(e.Name, e.SHA256, e.Mode) == nil {
+			entry.Signature, entry.Certificate = old.Signature, old.Certificate
+			s.target[e.Name] = entry
+			continue
+		}
+		s.target[e.Name] = entry
+		s.pending[e.Name] = true
+		needed = append(needed, e.Name)
+	}
+
+	session = s
+	log.Printf("[SCRIPT SYNC] Started sync session %s: %d scripts, %d to send", s.ID, len(entries), len(needed))
+	return s, needed, nil
+}
+
+// copyCurrent copies an unchanged script from the scripts directory to the
+// staging directory, checking it was not modified on disk.
+func (s *Session) copyCurrent(name, checksum string, mode os.FileMode) error {
+	content, err := os.ReadFile(scriptPath(config.ScriptsPath, name))
+	if err != nil {
+		return err
+	}
+	if Checksum(content) != checksum {
+		return errors.New("script changed on disk")
+	}
+	return writeScript(scriptPath(s.dir, name), content, mode)
+}
+
+// active checks that s is the current session and has not expired. An
+// expired session is discarded along with its staging directory. It must be
+// called with sessionMu held.
+func (s *Session) active() error {
+	if session != s {
+		return fmt.Errorf("script sync session %s is no longer active", s.ID)
+	}
+	if time.Since(s.started) > sessionTimeout {
+		log.Printf("[SCRIPT SYNC] Discarding expired sync session %s", s.ID)
+		os.RemoveAll(s.dir)
+		session = nil
+		return fmt.Errorf("script sync session %s expired", s.ID)
+	}
+	return nil
+}
+
+// ValidSessionID reports whether id has the format of a sync session ID.
+func ValidSessionID(id string) bool {
+	if len(id) != 2*sessionIDBytes {
+		return false
+	}
+	for _, c := range id {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// GetSession returns the sync session with the given ID.
+func GetSession(id string) (*Session, error) {
+	sessionMu.Lock()
+	defer sessionMu.Unlock()
+
+	if session == nil || session.ID != id {
+		return nil, fmt.Errorf("no script sync session %s", id)
+	}
+	if err := session.active(); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// Stage verifies a script sent for the session and writes it to the staging directory.
+func (s *Session) Stage(name string, content, signature, certPEM []byte) error {
+	if err := VerifySignature(content, signature, certPEM); err != nil {
+		return err
+	}
+	return s.stage(name, Checksum(content), signature, certPEM, func(path string, mode os.FileMode) error {
+		return writeScript(path, content, mode)
+	})
+}
+
+// StageFile is Stage for a script that was received into the file src, which
+// is moved into the staging directory.
+func (s *Session) StageFile(name, src string, signature, certPEM []byte) error {
+	checksum, err := VerifyFile(src, signature, certPEM)
+	if err != nil {
+		return err
+	}
+	return s.stage(name, checksum, signature, certPEM, func(path string, mode os.FileMode) error {
+		if mode == 0 {
+			mode = 0755
+		}
+		return uploads.MoveFile(src, path, mode)
+	})
+}
+
+// stage writes a verified script of the session with write.
+func (s *Session) stage(name, checksum string, signature, certPEM []byte, write func(path string, mode os.FileMode) error) error {
+	sessionMu.Lock()
+	defer sessionMu.Unlock()
+
+	if err := s.active(); err != nil {
+		return err
+	}
+	entry, ok := s.target[name]
+	if !ok {
+		return fmt.Errorf("script %s is not part of the sync", name)
+	}
+	if checksum != entry.SHA256 {
+		return fmt.Errorf("script %s does not match its checksum in the manifest", name)
+	}
+	path := scriptPath(s.dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	if err := write(path, entry.Mode); err != nil {
+		return err
+	}
+
+	entry.Signature, entry.Certificate = signature, certPEM
+	s.target[name] = entry
+	delete(s.pending, name)
+	return nil
+}
+
+// Commit swaps the staged set in place of the scripts directory. It fails if
+// any script of the set was not sent.
+func (s *Session) Commit() error {
+	sessionMu.Lock()
+	defer sessionMu.Unlock()
+
+	if err := s.active(); err != nil {
+		return err
+	}
+	if len(s.pending) > 0 {
+		return fmt.Errorf("%d scripts of the sync were not sent", len(s.pending))
+	}
+	if err := s.target.saveTo(s.dir); err != nil {
+		return err
+	}
+
+	manifestMu.Lock()
+	defer manifestMu.Unlock()
+
+	if err := swapDirs(s.dir, config.ScriptsPath); err != nil {
+		return err
+	}
+	session = nil
+	log.Printf("[SCRIPT SYNC] Committed sync session %s with %d scripts", s.ID, len(s.target))
+	return nil
+}
+
+// Abort discards the session and its staging directory.
+func (s *Session) Abort() error {
+	sessionMu.Lock()
+	defer sessionMu.Unlock()
+
+	if session != s {
+		return fmt.Errorf("script sync session %s is no longer active", s.ID)
+	}
+	session = nil
+	log.Printf("[SCRIPT SYNC] Aborted sync session %s", s.ID)
+	return os.RemoveAll(s.dir)
+}
+
+// swapDirs replaces dir with staging. Where the two can be exchanged
+// atomically, the old set ends up in the staging directory and is removed.
+// Otherwise the old directory is moved aside first and restored if the
+// staging directory cannot be moved in.
+func swapDirs(staging, dir string) error {
+	err := exchangeDirs(staging, dir)
+	if err == nil {
+		if err := os.RemoveAll(staging); err != nil {
+			log.Printf("[SCRIPT SYNC] Failed to remove old scripts directory: %v", err)
+		}
+		return nil
+	}
+	// A missing scripts directory is simply renamed into place below
+	if !errors.Is(err, errors.ErrUnsupported) && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to swap in staged scripts: %w", err)
+	}
+
+	backup := backupDir()
+	if err := os.RemoveAll(backup); err != nil {
+		return err
+	}
+	if err := os.Rename(dir, backup); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to move scripts directory aside: %w", err)
+	}
+	if err := os.Rename(staging, dir); err != nil {
+		if rerr := os.Rename(backup, dir); rerr != nil {
+			log.Printf("[SCRIPT SYNC] Failed to restore scripts directory: %v", rerr)
+		}
+		return fmt.Errorf("failed to move staged scripts in place: %w", err)
+	}
+	if err := os.RemoveAll(backup); err != nil {
+		log.Printf("[SCRIPT SYNC] Failed to remove old scripts directory: %v", err)
+	}
+	return nil
+}
+
+// RecoverSync restores the scripts directory after the agent stopped in the
+// middle of a sync, and removes leftover staging directories.
+func RecoverSync() {
+	backup := backupDir()
+	if _, err := os.Stat(backup); err == nil {
+		if _, err := os.Stat(config.ScriptsPath); os.IsNotExist(err) {
+			// Stopped between the two renames; the old set is still complete
+			log.Printf("[SCRIPT SYNC] Restoring scripts directory from interrupted sync")
+			if err := os.Rename(backup, config.ScriptsPath); err != nil {
+				log.Printf("[SCRIPT SYNC] Failed to restore scripts directory: %v", err)
+			}
+		} else {
+			os.RemoveAll(backup)
+		}
+	}
+
+	staged, _ := filepath.Glob(stagingPrefix() + "*")
+	for _, dir := range staged {
+		log.Printf("[SCRIPT SYNC] Removing leftover staging directory %s", dir)
+		os.RemoveAll(dir)
+	}
+}
+
+// writeScript writes a script with the given mode, defaulting to executable.
+func writeScript(path string, content []byte, mode os.FileMode) error {
+	if mode == 0 {
+		mode = 0755
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, content, mode); err != nil {
+		return err
+	}
+	// WriteFile does not change the mode of existing files and applies the umask
+	return os.Chmod(path, mode)
+}
@@ -0,0 +1,19 @@
+//go:build linux
+
+package scripts
+
+import (
+	"errors"
+
+	"golang.org/x/sys/unix"
+)
+
+// exchangeDirs atomically swaps two directories with renameat2. Filesystems
+// and kernels without RENAME_EXCHANGE report errors.ErrUnsupported.
+func exchangeDirs(a, b string) error {
+	err := unix.Renameat2(unix.AT_FDCWD, a, unix.AT_FDCWD, b, unix.RENAME_EXCHANGE)
+	if errors.Is(err, unix.EINVAL) || errors.Is(err, unix.ENOSYS) {
+		return errors.ErrUnsupported
+	}
+	return err
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+
+package scripts
+
+import "errors"
+
+// exchangeDirs is only supported on Linux.
+func exchangeDirs(a, b string) error {
+	return errors.ErrUnsupported
+}
@@ -0,0 +1,120 @@
+package scripts
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"openshield-agent/internal/config"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSyncSession(t *testing.T) {
+	setupDirs(t)
+	key, cert := newSigner(t, x509.ExtKeyUsageCodeSigning)
+
+	keep := []byte("#!/bin/bash\necho keep\n")
+	old := []byte("#!/bin/bash\necho old\n")
+	if err := Save("keep.sh", keep, ed25519.Sign(key, keep), cert); err != nil {
+		t.Fatal(err)
+	}
+	if err := Save("old.sh", old, ed25519.Sign(key, old), cert); err != nil {
+		t.Fatal(err)
+	}
+
+	added := []byte("#!/bin/bash\necho new\n")
+	session, needed, err := BeginSync([]SyncEntry{
+		{Name: "keep.sh", SHA256: Checksum(keep), Version: "2", Mode: 0750},
+		{Name: "new.sh", SHA256: Checksum(added), Version: "1"},
+	})
+	if err != nil {
+		t.Fatalf("BeginSync() error = %v", err)
+	}
+	if len(needed) != 1 || needed[0] != "new.sh" {
+		t.Fatalf("needed = %v, want [new.sh]", needed)
+	}
+	if _, _, err := BeginSync(nil); err == nil {
+		t.Error("BeginSync() started a second session")
+	}
+
+	if err := session.Commit(); err == nil {
+		t.Fatal("Commit() succeeded with scripts missing")
+	}
+	if err := session.Stage("new.sh", old, ed25519.Sign(key, old), cert); err == nil {
+		t.Error("Stage() accepted content that does not match the manifest")
+	}
+	if err := session.Stage("new.sh", added, ed25519.Sign(key, added), cert); err != nil {
+		t.Fatalf("Stage() error = %v", err)
+	}
+
+	// Nothing changes until the session is committed
+	if _, err := os.Stat(filepath.Join(config.ScriptsPath, "new.sh")); !os.IsNotExist(err) {
+		t.Error("staged script is visible before commit")
+	}
+	if err := session.Commit(); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+
+	for _, name := range []string{"keep.sh", "new.sh"} {
+		if err := Verify(name); err != nil {
+			t.Errorf("Verify(%s) error = %v", name, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(config.ScriptsPath, "old.sh")); !os.IsNotExist(err) {
+		t.Error("script missing from the manifest was not removed")
+	}
+	manifest, _ := LoadManifest()
+	if manifest["keep.sh"].Version != "2" {
+		t.Errorf("keep.sh version = %q, want 2", manifest["keep.sh"].Version)
+	}
+}
+
+func TestSyncSessionExpired(t *testing.T) {
+	setupDirs(t)
+	key, cert := newSigner(t, x509.ExtKeyUsageCodeSigning)
+
+	content := []byte("#!/bin/bash\necho late\n")
+	session, _, err := BeginSync([]SyncEntry{{Name: "late.sh", SHA256: Checksum(content)}})
+	if err != nil {
+		t.Fatalf("BeginSync() error = %v", err)
+	}
+	session.started = time.Now().Add(-sessionTimeout - time.Minute)
+
+	if err := session.Stage("late.sh", content, ed25519.Sign(key, content), cert); err == nil {
+		t.Error("Stage() accepted a script for an expired session")
+	}
+	if err := session.Commit(); err == nil {
+		t.Error("Commit() succeeded for an expired session")
+	}
+	if _, err := os.Stat(session.dir); !os.IsNotExist(err) {
+		t.Error("staging directory of the expired session was not removed")
+	}
+	if _, err := GetSession(session.ID); err == nil {
+		t.Error("GetSession() returned the expired session")
+	}
+}
+
+func TestRecoverSync(t *testing.T) {
+	setupDirs(t)
+	if err := os.WriteFile(filepath.Join(config.ScriptsPath, "a.sh"), []byte("echo a"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	// Simulate a stop between moving the scripts aside and moving the staged set in
+	if err := os.Rename(config.ScriptsPath, backupDir()); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(stagingPrefix()+"abc", 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	RecoverSync()
+
+	if _, err := os.Stat(filepath.Join(config.ScriptsPath, "a.sh")); err != nil {
+		t.Errorf("scripts were not restored: %v", err)
+	}
+	if _, err := os.Stat(stagingPrefix() + "abc"); !os.IsNotExist(err) {
+		t.Error("staging directory was not removed")
+	}
+}
@@ -12,9 +12,9 @@ import (
 )
 
 func registerAgent() error {
-	log.Printf("[AGENT] Registering agent with manager at %s", config.GlobalConfig.MANAGER_ADDRESS)
+	log.Printf("[AGENT] Registering agent with manager at %s", config.Get().Manager.Address)
 	// Create client for registration
-	client, err := agentgrpc.NewRegistrationClient(config.GlobalConfig.MANAGER_ADDRESS)
+	client, err := agentgrpc.NewRegistrationClient(config.Get().Manager.Address)
 	if err != nil {
 		log.Printf("[AGENT] Could not create client for manager: %v", err)
 		return err
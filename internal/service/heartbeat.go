@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"log"
+	"reflect"
 	"time"
 
 	"openshield-agent/internal/config"
@@ -10,14 +11,28 @@ import (
 	"strings"
 )
 
+// HeartbeatInterval returns the configured interval between heartbeats.
+func HeartbeatInterval() time.Duration {
+	return config.Get().Heartbeat.Interval.Std()
+}
+
+// managerChanged reports whether a config change requires a new connection to the manager.
+func managerChanged(old, updated *config.Config) bool {
+	return old.Manager != updated.Manager || !reflect.DeepEqual(old.TLS, updated.TLS)
+}
+
 // StartHeartbeatGRPC starts a goroutine that sends heartbeats to the manager over gRPC at the given interval.
 // managerAddr is the address of the manager's gRPC server (e.g., "localhost:50051").
 // agentID is the unique identifier for this agent.
+// When the configuration is reloaded, it reconnects if the manager's address
+// changed and picks up the new heartbeat.interval. A successful heartbeat
+// marks the configuration as known good.
 func ManagerHeartbeatMonitor(interval time.Duration, stopCh <-chan struct{}) {
-	config := config.GlobalConfig
+	changes := config.Subscribe()
+	cfg := config.Get()
 
 	go func() {
-		client, err := agentgrpc.NewManagerClient(config.MANAGER_ADDRESS)
+		client, err := agentgrpc.NewManagerClient(cfg.Manager.Address)
 		if err != nil {
 			log.Printf("[HEARTBEAT SYNC] Could not create client for manager: %v", err)
 			EnrollAgent()
@@ -31,6 +46,29 @@ func ManagerHeartbeatMonitor(interval time.Duration, stopCh <-chan struct{}) {
 			case <-stopCh:
 				log.Print("[HEARTBEAT] Stopping heartbeat monitor")
 				return
+			case updated := <-changes:
+				if managerChanged(cfg, updated) {
+					log.Printf("[HEARTBEAT] Manager changed, reconnecting to %s", updated.Manager.Address)
+					newClient, err := agentgrpc.NewManagerClient(updated.Manager.Address)
+					if err != nil {
+						// Keep the old connection; the reloaded config is rolled back
+						// if it never reaches the manager
+						log.Printf("[HEARTBEAT] Could not create client for manager: %v", err)
+					} else {
+						if client != nil {
+							client.Close()
+						}
+						client = newClient
+						cfg = updated
+					}
+				} else {
+					cfg = updated
+				}
+				if d := HeartbeatInterval(); d != interval {
+					log.Printf("[HEARTBEAT] Heartbeat interval changed to %s", d)
+					interval = d
+					ticker.Reset(interval)
+				}
 			case <-ticker.C:
 				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 				_, err = client.Heartbeat(ctx)
@@ -48,6 +86,8 @@ func ManagerHeartbeatMonitor(interval time.Duration, stopCh <-chan struct{}) {
 					log.Printf("[HEARTBEAT] Heartbeat failed: %v", err)
 				} else {
 					log.Printf("[HEARTBEAT] Heartbeat sent to manager")
+					// The config the connection was made with works
+					config.MarkKnownGood(cfg)
 				}
 			}
 		}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"openshield-agent/internal/config"
+	agentgrpc "openshield-agent/internal/grpc"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// syncTimeout bounds a single pull, including its downloads.
+const syncTimeout = 10 * time.Minute
+
+// SyncInterval returns the configured interval between pulls from the
+// manager, or 0 if pulling is disabled.
+func SyncInterval() time.Duration {
+	return config.Get().Sync.Interval.Std()
+}
+
+// ManagerSyncMonitor starts a goroutine that pulls the desired scripts and
+// config files from the manager at the given interval, for agents the manager
+// cannot reach to push them. An interval of 0 disables pulling until the
+// configuration is reloaded with a new sync.interval.
+func ManagerSyncMonitor(interval time.Duration, stopCh <-chan struct{}) {
+	changes := config.Subscribe()
+	cfg := config.Get()
+
+	go func() {
+		var client *agentgrpc.ManagerClient
+		defer func() {
+			if client != nil {
+				client.Close()
+			}
+		}()
+
+		// Pull right away, then at every interval
+		timer := time.NewTimer(0)
+		defer timer.Stop()
+		if interval <= 0 {
+			log.Print("[PULL SYNC] Pulling from the manager is disabled")
+			timer.Stop()
+		}
+
+		for {
+			select {
+			case <-stopCh:
+				log.Print("[PULL SYNC] Stopping sync monitor")
+				return
+			case updated := <-changes:
+				if managerChanged(cfg, updated) && client != nil {
+					client.Close()
+					client = nil
+				}
+				cfg = updated
+				if d := SyncInterval(); d != interval {
+					log.Printf("[PULL SYNC] Sync interval changed to %s", d)
+					interval = d
+					timer.Stop()
+					if interval > 0 {
+						timer.Reset(interval)
+					}
+				}
+				continue
+			case <-timer.C:
+			}
+
+			if client == nil {
+				var err error
+				client, err = agentgrpc.NewManagerClient(cfg.Manager.Address)
+				if err != nil {
+					log.Printf("[PULL SYNC] Could not create client for manager: %v", err)
+					timer.Reset(interval)
+					continue
+				}
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), syncTimeout)
+			err := client.SyncFromManager(ctx)
+			cancel()
+			if status.Code(err) == codes.Unimplemented {
+				log.Print("[PULL SYNC] Manager does not support pulling, stopping sync monitor")
+				return
+			}
+			if err != nil {
+				log.Printf("[PULL SYNC] Sync failed: %v", err)
+			}
+			timer.Reset(interval)
+		}
+	}()
+}
@@ -0,0 +1,29 @@
+package services
+
+import (
+	"os"
+	"os/exec"
+)
+
+// detect picks the init system the way its tools do: systemd creates
+// /run/systemd/system when it boots the system, and OpenRC /run/openrc.
+func detect() (Manager, error) {
+	if isDir("/run/systemd/system") {
+		return systemd{}, nil
+	}
+	if isDir("/run/openrc") {
+		return openrc{}, nil
+	}
+	if _, err := exec.LookPath("rc-service"); err == nil {
+		return openrc{}, nil
+	}
+	if isDir(initDir) {
+		return sysv{}, nil
+	}
+	return nil, ErrNoServiceManager
+}
+
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
@@ -0,0 +1,8 @@
+//go:build !linux && !darwin && !windows
+
+package services
+
+// detect fails, as there is no service manager for this OS.
+func detect() (Manager, error) {
+	return nil, ErrNoServiceManager
+}
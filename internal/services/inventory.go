@@ -0,0 +1,57 @@
+package services
+
+import (
+	"context"
+	"log"
+	"openshield-agent/internal/models"
+	"sync"
+	"time"
+)
+
+const (
+	// inventoryTTL is how long a collected service inventory is reused.
+	inventoryTTL = 5 * time.Minute
+	// inventoryTimeout bounds one collection of the inventory.
+	inventoryTimeout = time.Minute
+)
+
+var inventory struct {
+	mu         sync.Mutex
+	services   []models.Service
+	collected  time.Time
+	collecting bool
+}
+
+// Inventory returns the services of the system as last collected, and false
+// if no collection succeeded yet. A new collection starts in the background
+// once the inventory is older than inventoryTTL, so a slow init system never
+// holds up the caller.
+func Inventory() ([]models.Service, bool) {
+	inventory.mu.Lock()
+	defer inventory.mu.Unlock()
+
+	if !inventory.collecting && time.Since(inventory.collected) > inventoryTTL {
+		inventory.collecting = true
+		go collectInventory()
+	}
+	return inventory.services, !inventory.collected.IsZero()
+}
+
+// collectInventory lists the services and stores them in the inventory. A
+// failed collection keeps the previous inventory and is retried on the next
+// call to Inventory.
+func collectInventory() {
+	ctx, cancel := context.WithTimeout(context.Background(), inventoryTimeout)
+	defer cancel()
+	services, err := List(ctx)
+
+	inventory.mu.Lock()
+	defer inventory.mu.Unlock()
+	inventory.collecting = false
+	if err != nil {
+		log.Printf("[SERVICES] Failed to collect the service inventory: %v", err)
+		return
+	}
+	inventory.services = services
+	inventory.collected = time.Now()
+}
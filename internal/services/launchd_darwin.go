@@ -0,0 +1,131 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"openshield-agent/internal/models"
+	"strings"
+)
+
+// launchdDomain is the domain of system-wide services.
+const launchdDomain = "system"
+
+// launchd controls services with launchctl.
+type launchd struct{}
+
+// detect returns launchd, the init system of macOS.
+func detect() (Manager, error) {
+	return launchd{}, nil
+}
+
+func (launchd) Name() string { return "launchd" }
+
+// launchdState derives a service state from the PID and last exit status
+// launchctl reports for it.
+func launchdState(pid, status string) string {
+	switch {
+	case pid != "" && pid != "-":
+		return StateRunning
+	case status == "" || status == "0" || status == "-":
+		return StateStopped
+	}
+	return StateFailed
+}
+
+func (launchd) List(ctx context.Context) ([]models.Service, error) {
+	output, code, err := command(ctx, "launchctl", "list")
+	if err != nil {
+		return nil, err
+	}
+	if code != 0 {
+		return nil, fmt.Errorf("launchctl list exited with code %d", code)
+	}
+
+	// PID, last exit status and label, separated by tabs, after a header line
+	var services []models.Service
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) != 3 || fields[0] == "PID" {
+			continue
+		}
+		services = append(services, models.Service{Name: fields[2], State: launchdState(fields[0], fields[1])})
+	}
+	return services, nil
+}
+
+func (launchd) Status(ctx context.Context, name string) (Status, error) {
+	if err := checkName(name); err != nil {
+		return Status{}, err
+	}
+	// launchctl list <label> prints a dictionary with "PID" = 123; and
+	// "LastExitStatus" = 0; entries, and fails for unknown labels
+	output, code, err := command(ctx, "launchctl", "list", name)
+	if err != nil {
+		return Status{}, err
+	}
+	if code != 0 {
+		return Status{}, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	var pid, status string
+	for _, line := range strings.Split(output, "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), " = ")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSuffix(value, ";")
+		switch key {
+		case `"PID"`:
+			pid = value
+		case `"LastExitStatus"`:
+			status = value
+		}
+	}
+
+	// print-disabled lists services as "label" => disabled, or => true on
+	// older releases
+	disabled, _, err := command(ctx, "launchctl", "print-disabled", launchdDomain)
+	if err != nil {
+		return Status{}, err
+	}
+	enabled := true
+	for _, line := range strings.Split(disabled, "\n") {
+		label, value, ok := strings.Cut(strings.TrimSpace(line), " => ")
+		if ok && strings.Trim(label, `"`) == name {
+			enabled = value != "disabled" && value != "true"
+		}
+	}
+	return Status{Name: name, State: launchdState(pid, status), Enabled: enabled}, nil
+}
+
+func (launchd) Start(ctx context.Context, name string) error {
+	return launchctl(ctx, name, "kickstart")
+}
+
+func (l launchd) Stop(ctx context.Context, name string) error {
+	// kill fails for services that are not running
+	status, err := l.Status(ctx, name)
+	if err != nil || status.State != StateRunning {
+		return err
+	}
+	return launchctl(ctx, name, "kill", "SIGTERM")
+}
+
+func (launchd) Restart(ctx context.Context, name string) error {
+	return launchctl(ctx, name, "kickstart", "-k")
+}
+
+func (launchd) Enable(ctx context.Context, name string) error {
+	return launchctl(ctx, name, "enable")
+}
+
+func (launchd) Disable(ctx context.Context, name string) error {
+	return launchctl(ctx, name, "disable")
+}
+
+// launchctl runs a launchctl subcommand for a service in the system domain.
+func launchctl(ctx context.Context, name string, args ...string) error {
+	if err := checkName(name); err != nil {
+		return err
+	}
+	return control(ctx, "launchctl", append(args, launchdDomain+"/"+name)...)
+}
@@ -0,0 +1,159 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"openshield-agent/internal/models"
+	"sort"
+	"strings"
+)
+
+// openrcRunlevel is the runlevel services are enabled in.
+const openrcRunlevel = "default"
+
+// openrc controls services with OpenRC's rc-service, rc-status and rc-update.
+type openrc struct{}
+
+func (openrc) Name() string { return "openrc" }
+
+// openrcState maps an OpenRC service state to a service state.
+func openrcState(state string) string {
+	switch state {
+	case "started":
+		return StateRunning
+	case "stopped", "inactive":
+		return StateStopped
+	case "crashed", "failed":
+		return StateFailed
+	case "starting":
+		return StateStarting
+	case "stopping":
+		return StateStopping
+	}
+	return StateUnknown
+}
+
+// parseRCStatus reads the states from the output of rc-status --all, where
+// each runlevel lists its services as "name [ state ]".
+func parseRCStatus(output string) map[string]string {
+	states := make(map[string]string)
+	for _, line := range strings.Split(output, "\n") {
+		name, rest, ok := strings.Cut(strings.TrimSpace(line), " ")
+		if !ok || strings.Contains(line, "Runlevel:") {
+			continue
+		}
+		start, end := strings.Index(rest, "["), strings.LastIndex(rest, "]")
+		if start < 0 || end < start {
+			continue
+		}
+		// The state can be followed by the uptime, as in "[ started 01:02:03 (0) ]"
+		if fields := strings.Fields(rest[start+1 : end]); len(fields) > 0 {
+			states[name] = openrcState(fields[0])
+		}
+	}
+	return states
+}
+
+// parseRCUpdate reads the runlevels of services from the output of
+// rc-update show, with lines as "name | runlevel ...".
+func parseRCUpdate(output string) map[string][]string {
+	runlevels := make(map[string][]string)
+	for _, line := range strings.Split(output, "\n") {
+		name, levels, ok := strings.Cut(line, "|")
+		if name = strings.TrimSpace(name); !ok || name == "" {
+			continue
+		}
+		runlevels[name] = append(runlevels[name], strings.Fields(levels)...)
+	}
+	return runlevels
+}
+
+func (openrc) List(ctx context.Context) ([]models.Service, error) {
+	names, _, err := command(ctx, "rc-service", "--list")
+	if err != nil {
+		return nil, err
+	}
+	status, _, err := command(ctx, "rc-status", "--all", "--nocolor")
+	if err != nil {
+		return nil, err
+	}
+	states := parseRCStatus(status)
+
+	// Services that are in no runlevel and were never started are missing
+	// from rc-status
+	var services []models.Service
+	for _, name := range strings.Fields(names) {
+		state, ok := states[name]
+		if !ok {
+			state = StateStopped
+		}
+		services = append(services, models.Service{Name: name, State: state})
+	}
+	sort.Slice(services, func(i, j int) bool { return services[i].Name < services[j].Name })
+	return services, nil
+}
+
+func (openrc) Status(ctx context.Context, name string) (Status, error) {
+	if err := checkName(name); err != nil {
+		return Status{}, err
+	}
+	if _, code, err := command(ctx, "rc-service", "--exists", name); err != nil {
+		return Status{}, err
+	} else if code != 0 {
+		return Status{}, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+
+	// rc-service prints " * status: started" and exits with an error
+	// unless the service is started
+	output, _, err := command(ctx, "rc-service", name, "status")
+	if err != nil {
+		return Status{}, err
+	}
+	state := StateUnknown
+	if _, after, ok := strings.Cut(output, "status:"); ok {
+		if fields := strings.Fields(after); len(fields) > 0 {
+			state = openrcState(fields[0])
+		}
+	}
+
+	runlevels, _, err := command(ctx, "rc-update", "show")
+	if err != nil {
+		return Status{}, err
+	}
+	return Status{Name: name, State: state, Enabled: len(parseRCUpdate(runlevels)[name]) > 0}, nil
+}
+
+func (openrc) Start(ctx context.Context, name string) error {
+	return openrcService(ctx, name, "start")
+}
+
+func (openrc) Stop(ctx context.Context, name string) error {
+	return openrcService(ctx, name, "stop")
+}
+
+func (openrc) Restart(ctx context.Context, name string) error {
+	return openrcService(ctx, name, "restart")
+}
+
+func (openrc) Enable(ctx context.Context, name string) error {
+	if err := checkName(name); err != nil {
+		return err
+	}
+	return control(ctx, "rc-update", "add", name, openrcRunlevel)
+}
+
+func (openrc) Disable(ctx context.Context, name string) error {
+	if err := checkName(name); err != nil {
+		return err
+	}
+	return control(ctx, "rc-update", "del", name, openrcRunlevel)
+}
+
+// openrcService runs an rc-service action. OpenRC does nothing for a
+// service that is already in the wanted state.
+func openrcService(ctx context.Context, name, action string) error {
+	if err := checkName(name); err != nil {
+		return err
+	}
+	return control(ctx, "rc-service", name, action)
+}
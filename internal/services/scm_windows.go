@@ -0,0 +1,237 @@
+//go:build windows
+
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"openshield-agent/internal/executor"
+	"openshield-agent/internal/models"
+	"syscall"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/mgr"
+)
+
+// scmPollInterval is how often a service is queried while waiting for it to
+// start or stop.
+const scmPollInterval = 250 * time.Millisecond
+
+// scm controls services through the Windows service control manager.
+type scm struct{}
+
+// detect returns the service control manager, which all Windows versions have.
+func detect() (Manager, error) {
+	return scm{}, nil
+}
+
+func (scm) Name() string { return "windows" }
+
+// scmState maps the state of a Windows service to a service state.
+func scmState(state svc.State) string {
+	switch state {
+	case svc.Running:
+		return StateRunning
+	case svc.Stopped:
+		return StateStopped
+	case svc.StartPending, svc.ContinuePending:
+		return StateStarting
+	case svc.StopPending, svc.PausePending:
+		return StateStopping
+	}
+	return StateUnknown
+}
+
+// connect opens the service control manager with only the access needed,
+// so listing and querying services works without administrator rights.
+func connect(access uint32) (*mgr.Mgr, error) {
+	h, err := windows.OpenSCManager(nil, nil, access)
+	if err != nil {
+		return nil, fmt.Errorf("could not connect to the service control manager: %w", err)
+	}
+	return &mgr.Mgr{Handle: h}, nil
+}
+
+// open opens a service with the given access rights.
+func open(name string, access uint32) (*mgr.Service, func(), error) {
+	if err := checkName(name); err != nil {
+		return nil, nil, err
+	}
+	m, err := connect(windows.SC_MANAGER_CONNECT)
+	if err != nil {
+		return nil, nil, err
+	}
+	namePtr, err := windows.UTF16PtrFromString(name)
+	if err != nil {
+		m.Disconnect()
+		return nil, nil, err
+	}
+	h, err := windows.OpenService(m.Handle, namePtr, access)
+	if err != nil {
+		m.Disconnect()
+		if errors.Is(err, windows.ERROR_SERVICE_DOES_NOT_EXIST) {
+			return nil, nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+		}
+		if errors.Is(err, windows.ERROR_ACCESS_DENIED) {
+			return nil, nil, fmt.Errorf("%w to control %s: %v", executor.ErrInsufficientPrivileges, name, err)
+		}
+		return nil, nil, fmt.Errorf("%s: %w", name, err)
+	}
+	s := &mgr.Service{Name: name, Handle: h}
+	return s, func() { s.Close(); m.Disconnect() }, nil
+}
+
+func (scm) List(ctx context.Context) ([]models.Service, error) {
+	m, err := connect(windows.SC_MANAGER_ENUMERATE_SERVICE)
+	if err != nil {
+		return nil, err
+	}
+	defer m.Disconnect()
+
+	var needed, returned uint32
+	var buf []byte
+	for {
+		var p *byte
+		if len(buf) > 0 {
+			p = &buf[0]
+		}
+		err := windows.EnumServicesStatusEx(m.Handle, windows.SC_ENUM_PROCESS_INFO,
+			windows.SERVICE_WIN32, windows.SERVICE_STATE_ALL,
+			p, uint32(len(buf)), &needed, &returned, nil, nil)
+		if err == nil {
+			break
+		}
+		if err != syscall.ERROR_MORE_DATA || needed <= uint32(len(buf)) {
+			return nil, fmt.Errorf("failed to list services: %w", err)
+		}
+		buf = make([]byte, needed)
+	}
+	if returned == 0 {
+		return nil, nil
+	}
+
+	entries := unsafe.Slice((*windows.ENUM_SERVICE_STATUS_PROCESS)(unsafe.Pointer(&buf[0])), int(returned))
+	services := make([]models.Service, 0, len(entries))
+	for _, entry := range entries {
+		services = append(services, models.Service{
+			Name:  windows.UTF16PtrToString(entry.ServiceName),
+			State: scmState(svc.State(entry.ServiceStatusProcess.CurrentState)),
+		})
+	}
+	return services, nil
+}
+
+func (scm) Status(ctx context.Context, name string) (Status, error) {
+	s, done, err := open(name, windows.SERVICE_QUERY_STATUS|windows.SERVICE_QUERY_CONFIG)
+	if err != nil {
+		return Status{}, err
+	}
+	defer done()
+
+	status, err := s.Query()
+	if err != nil {
+		return Status{}, fmt.Errorf("failed to query %s: %w", name, err)
+	}
+	cfg, err := s.Config()
+	if err != nil {
+		return Status{}, fmt.Errorf("failed to query %s: %w", name, err)
+	}
+	return Status{Name: name, State: scmState(status.State), Enabled: cfg.StartType == mgr.StartAutomatic}, nil
+}
+
+func (scm) Start(ctx context.Context, name string) error {
+	s, done, err := open(name, windows.SERVICE_START|windows.SERVICE_QUERY_STATUS)
+	if err != nil {
+		return err
+	}
+	defer done()
+	return start(ctx, s)
+}
+
+func (scm) Stop(ctx context.Context, name string) error {
+	s, done, err := open(name, windows.SERVICE_STOP|windows.SERVICE_QUERY_STATUS)
+	if err != nil {
+		return err
+	}
+	defer done()
+	return stop(ctx, s)
+}
+
+func (scm) Restart(ctx context.Context, name string) error {
+	s, done, err := open(name, windows.SERVICE_START|windows.SERVICE_STOP|windows.SERVICE_QUERY_STATUS)
+	if err != nil {
+		return err
+	}
+	defer done()
+	if err := stop(ctx, s); err != nil {
+		return err
+	}
+	return start(ctx, s)
+}
+
+func (scm) Enable(ctx context.Context, name string) error {
+	return setStartType(name, windows.SERVICE_AUTO_START)
+}
+
+func (scm) Disable(ctx context.Context, name string) error {
+	return setStartType(name, windows.SERVICE_DISABLED)
+}
+
+// start starts a service and waits until it runs.
+func start(ctx context.Context, s *mgr.Service) error {
+	if err := s.Start(); err != nil && !errors.Is(err, windows.ERROR_SERVICE_ALREADY_RUNNING) {
+		return fmt.Errorf("failed to start %s: %w", s.Name, err)
+	}
+	return waitState(ctx, s, svc.Running)
+}
+
+// stop stops a service and waits until it stopped.
+func stop(ctx context.Context, s *mgr.Service) error {
+	if _, err := s.Control(svc.Stop); err != nil && !errors.Is(err, windows.ERROR_SERVICE_NOT_ACTIVE) {
+		return fmt.Errorf("failed to stop %s: %w", s.Name, err)
+	}
+	return waitState(ctx, s, svc.Stopped)
+}
+
+// waitState polls a service until it reaches the state.
+func waitState(ctx context.Context, s *mgr.Service, want svc.State) error {
+	ticker := time.NewTicker(scmPollInterval)
+	defer ticker.Stop()
+	for {
+		status, err := s.Query()
+		if err != nil {
+			return fmt.Errorf("failed to query %s: %w", s.Name, err)
+		}
+		if status.State == want {
+			return nil
+		}
+		// A service that stops while starting failed to start
+		if want == svc.Running && status.State == svc.Stopped {
+			return fmt.Errorf("%s stopped while starting (exit code %d)", s.Name, status.Win32ExitCode)
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%s: %w", s.Name, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// setStartType changes only the start type of a service.
+func setStartType(name string, startType uint32) error {
+	s, done, err := open(name, windows.SERVICE_CHANGE_CONFIG)
+	if err != nil {
+		return err
+	}
+	defer done()
+	err = windows.ChangeServiceConfig(s.Handle, windows.SERVICE_NO_CHANGE, startType, windows.SERVICE_NO_CHANGE,
+		nil, nil, nil, nil, nil, nil, nil)
+	if err != nil {
+		return fmt.Errorf("failed to configure %s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+// Package services lists and controls the system's services through its init
+// system: systemd, OpenRC or SysV init on Linux, launchd on macOS and the
+// service control manager on Windows.
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"openshield-agent/internal/executor"
+	"openshield-agent/internal/models"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// Service states, common to all init systems
+const (
+	StateRunning  = "running"
+	StateStopped  = "stopped"
+	StateStarting = "starting"
+	StateStopping = "stopping"
+	StateFailed   = "failed"
+	StateUnknown  = "unknown"
+)
+
+var (
+	// ErrNotFound is returned for services the init system does not know.
+	ErrNotFound = errors.New("service not found")
+	// ErrNoServiceManager is returned when no supported init system is found.
+	ErrNoServiceManager = errors.New("no supported service manager found")
+)
+
+// Status describes a service.
+type Status struct {
+	Name    string
+	State   string
+	Enabled bool // started at boot
+}
+
+// Manager lists and controls services. Names are the ones the init system
+// uses; systemd names may leave out the ".service" suffix.
+type Manager interface {
+	// Name identifies the init system, such as "systemd" or "openrc".
+	Name() string
+	List(ctx context.Context) ([]models.Service, error)
+	Status(ctx context.Context, name string) (Status, error)
+	// Start and Stop succeed if the service is already in the wanted state.
+	Start(ctx context.Context, name string) error
+	Stop(ctx context.Context, name string) error
+	// Restart starts the service if it is not running.
+	Restart(ctx context.Context, name string) error
+	Enable(ctx context.Context, name string) error
+	Disable(ctx context.Context, name string) error
+}
+
+var (
+	detectOnce sync.Once
+	detected   Manager
+	detectErr  error
+)
+
+// Detect returns the manager of the running init system.
+func Detect() (Manager, error) {
+	detectOnce.Do(func() {
+		detected, detectErr = detect()
+	})
+	return detected, detectErr
+}
+
+// List returns all services of the system and their states.
+func List(ctx context.Context) ([]models.Service, error) {
+	m, err := Detect()
+	if err != nil {
+		return nil, err
+	}
+	return m.List(ctx)
+}
+
+// checkName rejects names that could be mistaken for paths or options by
+// the init system's tools.
+func checkName(name string) error {
+	if name == "" || strings.ContainsAny(name, "/\\") || strings.HasPrefix(name, "-") || strings.HasPrefix(name, ".") {
+		return fmt.Errorf("invalid service name %q", name)
+	}
+	return nil
+}
+
+// command runs a command that queries the init system and returns its
+// standard output and exit code. Only failing to run it is an error, as
+// status commands report through their exit code.
+func command(ctx context.Context, name string, args ...string) (string, int, error) {
+	out, err := exec.CommandContext(ctx, name, args...).Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) || exitErr.ExitCode() < 0 {
+			return "", -1, fmt.Errorf("%s failed: %w", name, err)
+		}
+		return string(out), exitErr.ExitCode(), nil
+	}
+	return string(out), 0, nil
+}
+
+// control runs a command that changes a service, as root through the
+// privilege helper unless the agent is root. Its output becomes part of the
+// error if it fails.
+func control(ctx context.Context, name string, args ...string) error {
+	res, err := executor.RunElevated(ctx, executor.Options{}, name, args...)
+	if err != nil {
+		cmd := strings.Join(append([]string{name}, args...), " ")
+		if errors.Is(err, executor.ErrInsufficientPrivileges) || res == nil || strings.TrimSpace(res.Output) == "" {
+			return fmt.Errorf("%s: %w", cmd, err)
+		}
+		return fmt.Errorf("%s: %w: %s", cmd, err, strings.TrimSpace(res.Output))
+	}
+	return nil
+}
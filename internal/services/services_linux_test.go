@@ -0,0 +1,95 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseRCStatus(t *testing.T) {
+	states := parseRCStatus(`Runlevel: default
+ sshd                                         [  started 2 day(s) 01:02:03 (0) ]
+ crond                                        [  started  ]
+ fail2ban                                     [  crashed  ]
+Dynamic Runlevel: hotplugged
+Dynamic Runlevel: manual
+ nginx                                        [  stopped  ]
+`)
+	for name, want := range map[string]string{
+		"sshd":     StateRunning,
+		"crond":    StateRunning,
+		"fail2ban": StateFailed,
+		"nginx":    StateStopped,
+	} {
+		if got := states[name]; got != want {
+			t.Errorf("state of %s = %q, want %q", name, got, want)
+		}
+	}
+	if len(states) != 4 {
+		t.Errorf("parseRCStatus() = %v, want 4 services", states)
+	}
+
+	runlevels := parseRCUpdate("             crond | default\n              sshd | boot default\n")
+	if len(runlevels["sshd"]) != 2 || len(runlevels["crond"]) != 1 || len(runlevels["nginx"]) != 0 {
+		t.Errorf("parseRCUpdate() = %v", runlevels)
+	}
+}
+
+func TestSysV(t *testing.T) {
+	dir := t.TempDir()
+	old := initDir
+	initDir = dir
+	defer func() { initDir = old }()
+
+	// Init scripts report their state with LSB exit codes
+	for name, code := range map[string]string{"running": "0", "stopped": "3", "dead": "1"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\nexit "+code+"\n"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "README"), []byte("not a service\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := sysv{}.List(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"running": StateRunning, "stopped": StateStopped, "dead": StateFailed}
+	if len(list) != len(want) {
+		t.Fatalf("List() = %v, want %d services", list, len(want))
+	}
+	for _, service := range list {
+		if service.State != want[service.Name] {
+			t.Errorf("state of %s = %q, want %q", service.Name, service.State, want[service.Name])
+		}
+	}
+
+	if _, err := (sysv{}).Status(context.Background(), "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Status(missing) error = %v, want ErrNotFound", err)
+	}
+	if _, err := (sysv{}).Status(context.Background(), "../running"); err == nil {
+		t.Error("Status() accepted a path")
+	}
+}
+
+func TestInvalidNames(t *testing.T) {
+	ctx := context.Background()
+	for _, m := range []Manager{systemd{}, openrc{}, sysv{}} {
+		for _, name := range []string{"", "--now", "-H host", "../foo", "foo/bar", ".hidden"} {
+			if _, err := m.Status(ctx, name); err == nil || errors.Is(err, ErrNotFound) {
+				t.Errorf("%s: Status(%q) error = %v, want an invalid name", m.Name(), name, err)
+			}
+			for op, control := range map[string]func(context.Context, string) error{
+				"Start": m.Start, "Stop": m.Stop, "Restart": m.Restart, "Enable": m.Enable, "Disable": m.Disable,
+			} {
+				if err := control(ctx, name); err == nil || !strings.Contains(err.Error(), "invalid service name") {
+					t.Errorf("%s: %s(%q) error = %v, want an invalid name", m.Name(), op, name, err)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,276 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"openshield-agent/internal/executor"
+	"openshield-agent/internal/models"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	systemdDest       = "org.freedesktop.systemd1"
+	systemdPath       = dbus.ObjectPath("/org/freedesktop/systemd1")
+	systemdManager    = "org.freedesktop.systemd1.Manager"
+	systemdUnit       = "org.freedesktop.systemd1.Unit"
+	systemdNoSuchUnit = "org.freedesktop.systemd1.NoSuchUnit"
+	// systemdPrivateSocket is systemd's own D-Bus socket, which root can
+	// use when there is no system bus.
+	systemdPrivateSocket = "unix:path=/run/systemd/private"
+)
+
+// unitFileChange is a change systemd made to enable or disable a unit, as
+// returned by EnableUnitFiles and DisableUnitFiles.
+type unitFileChange struct {
+	Type, Filename, Destination string
+}
+
+// systemd controls services through systemd's D-Bus API.
+type systemd struct{}
+
+func (systemd) Name() string { return "systemd" }
+
+// unitName adds the ".service" suffix systemd's tools let users leave out.
+func unitName(name string) string {
+	if strings.HasSuffix(name, ".service") {
+		return name
+	}
+	return name + ".service"
+}
+
+// systemdState maps a unit's ActiveState to a service state.
+func systemdState(activeState string) string {
+	switch activeState {
+	case "active", "reloading":
+		return StateRunning
+	case "inactive":
+		return StateStopped
+	case "failed":
+		return StateFailed
+	case "activating":
+		return StateStarting
+	case "deactivating":
+		return StateStopping
+	}
+	return StateUnknown
+}
+
+// connect opens a connection to systemd over the system bus, or over its
+// private socket if the bus is not running. The second result reports
+// whether the connection goes through the bus.
+func (systemd) connect() (*dbus.Conn, bool, error) {
+	conn, err := dbus.ConnectSystemBus()
+	if err == nil {
+		return conn, true, nil
+	}
+	conn, privErr := dbus.Dial(systemdPrivateSocket)
+	if privErr != nil {
+		return nil, false, fmt.Errorf("could not connect to systemd: %w", err)
+	}
+	if privErr = conn.Auth([]dbus.Auth{dbus.AuthExternal(strconv.Itoa(os.Getuid()))}); privErr != nil {
+		conn.Close()
+		return nil, false, fmt.Errorf("could not connect to systemd: %w", err)
+	}
+	return conn, false, nil
+}
+
+// systemdError turns D-Bus errors for unknown units into ErrNotFound.
+func systemdError(unit string, err error) error {
+	var dbusErr dbus.Error
+	if errors.As(err, &dbusErr) && dbusErr.Name == systemdNoSuchUnit {
+		return fmt.Errorf("%w: %s", ErrNotFound, unit)
+	}
+	return fmt.Errorf("%s: %w", unit, err)
+}
+
+func (s systemd) List(ctx context.Context) ([]models.Service, error) {
+	conn, _, err := s.connect()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	// ListUnits returns a(ssssssouso): name, description, load state,
+	// active state, sub state, followed unit, unit path, job ID, job type
+	// and job path
+	var units []struct {
+		Name, Description, LoadState, ActiveState, SubState, Followed string
+		Path                                                          dbus.ObjectPath
+		JobID                                                         uint32
+		JobType                                                       string
+		JobPath                                                       dbus.ObjectPath
+	}
+	err = conn.Object(systemdDest, systemdPath).CallWithContext(ctx, systemdManager+".ListUnits", 0).Store(&units)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list systemd units: %w", err)
+	}
+
+	var services []models.Service
+	for _, unit := range units {
+		if !strings.HasSuffix(unit.Name, ".service") || unit.LoadState == "not-found" {
+			continue
+		}
+		services = append(services, models.Service{Name: unit.Name, State: systemdState(unit.ActiveState)})
+	}
+	return services, nil
+}
+
+func (s systemd) Status(ctx context.Context, name string) (Status, error) {
+	if err := checkName(name); err != nil {
+		return Status{}, err
+	}
+	unit := unitName(name)
+	conn, _, err := s.connect()
+	if err != nil {
+		return Status{}, err
+	}
+	defer conn.Close()
+
+	var path dbus.ObjectPath
+	if err := conn.Object(systemdDest, systemdPath).CallWithContext(ctx, systemdManager+".LoadUnit", 0, unit).Store(&path); err != nil {
+		return Status{}, systemdError(unit, err)
+	}
+	var props map[string]dbus.Variant
+	if err := conn.Object(systemdDest, path).CallWithContext(ctx, "org.freedesktop.DBus.Properties.GetAll", 0, systemdUnit).Store(&props); err != nil {
+		return Status{}, systemdError(unit, err)
+	}
+	property := func(key string) string {
+		value, _ := props[key].Value().(string)
+		return value
+	}
+	if property("LoadState") == "not-found" {
+		return Status{}, fmt.Errorf("%w: %s", ErrNotFound, unit)
+	}
+	fileState := property("UnitFileState")
+	return Status{
+		Name:    unit,
+		State:   systemdState(property("ActiveState")),
+		Enabled: fileState == "enabled" || fileState == "enabled-runtime",
+	}, nil
+}
+
+func (s systemd) Start(ctx context.Context, name string) error {
+	return s.runJob(ctx, "StartUnit", name)
+}
+
+func (s systemd) Stop(ctx context.Context, name string) error {
+	return s.runJob(ctx, "StopUnit", name)
+}
+
+func (s systemd) Restart(ctx context.Context, name string) error {
+	return s.runJob(ctx, "RestartUnit", name)
+}
+
+// runJob queues a start, stop or restart job for a service and waits until
+// systemd reports it as done. Unless the agent is root, systemctl runs the
+// job through the privilege helper instead.
+func (s systemd) runJob(ctx context.Context, method, name string) error {
+	if err := checkName(name); err != nil {
+		return err
+	}
+	unit := unitName(name)
+	verb := strings.ToLower(strings.TrimSuffix(method, "Unit"))
+	if !executor.IsElevated() {
+		// polkit denies unprivileged D-Bus clients, so use the privilege helper
+		return control(ctx, "systemctl", verb, unit)
+	}
+	conn, bus, err := s.connect()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// Subscribe to JobRemoved before queueing the job, so its end is not missed
+	signals := make(chan *dbus.Signal, 16)
+	conn.Signal(signals)
+	if bus {
+		err := conn.AddMatchSignalContext(ctx,
+			dbus.WithMatchObjectPath(systemdPath),
+			dbus.WithMatchInterface(systemdManager),
+			dbus.WithMatchMember("JobRemoved"))
+		if err != nil {
+			return fmt.Errorf("failed to watch systemd jobs: %w", err)
+		}
+	}
+	manager := conn.Object(systemdDest, systemdPath)
+	if err := manager.CallWithContext(ctx, systemdManager+".Subscribe", 0).Err; err != nil {
+		return fmt.Errorf("failed to watch systemd jobs: %w", err)
+	}
+
+	var job dbus.ObjectPath
+	if err := manager.CallWithContext(ctx, systemdManager+"."+method, 0, unit, "replace").Store(&job); err != nil {
+		return systemdError(unit, err)
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%s: %w", unit, ctx.Err())
+		case signal, ok := <-signals:
+			if !ok {
+				return fmt.Errorf("%s: connection to systemd closed", unit)
+			}
+			// JobRemoved(u id, o job, s unit, s result)
+			if signal.Name != systemdManager+".JobRemoved" || len(signal.Body) != 4 {
+				continue
+			}
+			if path, _ := signal.Body[1].(dbus.ObjectPath); path != job {
+				continue
+			}
+			if result, _ := signal.Body[3].(string); result != "done" {
+				return fmt.Errorf("failed to %s %s: job %s", verb, unit, result)
+			}
+			return nil
+		}
+	}
+}
+
+func (s systemd) Enable(ctx context.Context, name string) error {
+	if err := checkName(name); err != nil {
+		return err
+	}
+	unit := unitName(name)
+	if !executor.IsElevated() {
+		return control(ctx, "systemctl", "enable", unit)
+	}
+	conn, _, err := s.connect()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	var carriesInstallInfo bool
+	var changes []unitFileChange
+	manager := conn.Object(systemdDest, systemdPath)
+	err = manager.CallWithContext(ctx, systemdManager+".EnableUnitFiles", 0, []string{unit}, false, false).Store(&carriesInstallInfo, &changes)
+	if err != nil {
+		return systemdError(unit, err)
+	}
+	return manager.CallWithContext(ctx, systemdManager+".Reload", 0).Err
+}
+
+func (s systemd) Disable(ctx context.Context, name string) error {
+	if err := checkName(name); err != nil {
+		return err
+	}
+	unit := unitName(name)
+	if !executor.IsElevated() {
+		return control(ctx, "systemctl", "disable", unit)
+	}
+	conn, _, err := s.connect()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	var changes []unitFileChange
+	manager := conn.Object(systemdDest, systemdPath)
+	if err := manager.CallWithContext(ctx, systemdManager+".DisableUnitFiles", 0, []string{unit}, false).Store(&changes); err != nil {
+		return systemdError(unit, err)
+	}
+	return manager.CallWithContext(ctx, systemdManager+".Reload", 0).Err
+}
@@ -0,0 +1,162 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"openshield-agent/internal/models"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+)
+
+// initDir holds the init scripts of SysV init.
+var initDir = "/etc/init.d"
+
+// sysvStatusTimeout bounds the status action of each init script when
+// listing services.
+const sysvStatusTimeout = 5 * time.Second
+
+// sysvSkip are files in the init script directory that are not services.
+var sysvSkip = map[string]bool{
+	"README":    true,
+	"functions": true,
+	"halt":      true,
+	"killall":   true,
+	"rc":        true,
+	"rcS":       true,
+	"reboot":    true,
+	"single":    true,
+	"skeleton":  true,
+}
+
+// sysv controls services through their init scripts, and enables them with
+// update-rc.d or chkconfig, whichever the distribution has.
+type sysv struct{}
+
+func (sysv) Name() string { return "sysv" }
+
+// lsbState maps the exit code of an init script's status action, as defined
+// by the LSB, to a service state.
+func lsbState(code int) string {
+	switch code {
+	case 0:
+		return StateRunning
+	case 1, 2:
+		// Dead, but the pid or lock file exists
+		return StateFailed
+	case 3:
+		return StateStopped
+	}
+	return StateUnknown
+}
+
+// script returns the path of a service's init script.
+func (sysv) script(name string) (string, error) {
+	if err := checkName(name); err != nil {
+		return "", err
+	}
+	path := filepath.Join(initDir, name)
+	if info, err := os.Stat(path); err != nil || info.IsDir() || sysvSkip[name] {
+		return "", fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	return path, nil
+}
+
+// state runs the status action of an init script.
+func (sysv) state(ctx context.Context, script string) (string, error) {
+	_, code, err := command(ctx, script, "status")
+	if err != nil {
+		return "", err
+	}
+	return lsbState(code), nil
+}
+
+func (s sysv) List(ctx context.Context) ([]models.Service, error) {
+	entries, err := os.ReadDir(initDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list init scripts: %w", err)
+	}
+	var services []models.Service
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || !info.Mode().IsRegular() || info.Mode()&0o111 == 0 || sysvSkip[entry.Name()] {
+			continue
+		}
+		// A hanging script must not keep the others from being queried
+		scriptCtx, cancel := context.WithTimeout(ctx, sysvStatusTimeout)
+		state, err := s.state(scriptCtx, filepath.Join(initDir, entry.Name()))
+		cancel()
+		if err != nil {
+			state = StateUnknown
+		}
+		services = append(services, models.Service{Name: entry.Name(), State: state})
+	}
+	return services, nil
+}
+
+func (s sysv) Status(ctx context.Context, name string) (Status, error) {
+	script, err := s.script(name)
+	if err != nil {
+		return Status{}, err
+	}
+	state, err := s.state(ctx, script)
+	if err != nil {
+		return Status{}, err
+	}
+	// Enabled services have a start link in a multi-user runlevel
+	links, _ := filepath.Glob("/etc/rc[2345].d/S[0-9][0-9]" + name)
+	if len(links) == 0 {
+		links, _ = filepath.Glob("/etc/rc.d/rc[2345].d/S[0-9][0-9]" + name)
+	}
+	return Status{Name: name, State: state, Enabled: len(links) > 0}, nil
+}
+
+func (s sysv) Start(ctx context.Context, name string) error {
+	return s.run(ctx, name, "start")
+}
+
+func (s sysv) Stop(ctx context.Context, name string) error {
+	return s.run(ctx, name, "stop")
+}
+
+func (s sysv) Restart(ctx context.Context, name string) error {
+	return s.run(ctx, name, "restart")
+}
+
+// run runs an action of a service's init script.
+func (s sysv) run(ctx context.Context, name, action string) error {
+	script, err := s.script(name)
+	if err != nil {
+		return err
+	}
+	return control(ctx, script, action)
+}
+
+func (s sysv) Enable(ctx context.Context, name string) error {
+	if _, err := s.script(name); err != nil {
+		return err
+	}
+	if _, err := exec.LookPath("update-rc.d"); err == nil {
+		// defaults adds the links if there are none, enable turns them
+		// back on if the service was disabled
+		if err := control(ctx, "update-rc.d", name, "defaults"); err != nil {
+			return err
+		}
+		return control(ctx, "update-rc.d", name, "enable")
+	}
+	if err := control(ctx, "chkconfig", "--add", name); err != nil {
+		return err
+	}
+	return control(ctx, "chkconfig", name, "on")
+}
+
+func (s sysv) Disable(ctx context.Context, name string) error {
+	if _, err := s.script(name); err != nil {
+		return err
+	}
+	if _, err := exec.LookPath("update-rc.d"); err == nil {
+		return control(ctx, "update-rc.d", name, "disable")
+	}
+	return control(ctx, "chkconfig", name, "off")
+}
@@ -0,0 +1,278 @@
+package tasks
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"openshield-agent/internal/executor"
+	"openshield-agent/proto"
+)
+
+// JournalFilename is the name of the task journal inside the state directory.
+const JournalFilename = "tasks.journal"
+
+// journalQueueSize is how many writes can wait for the journal writer before
+// appending blocks.
+const journalQueueSize = 256
+
+// Journal is an append-only file of task record snapshots. The last snapshot
+// written for a task ID is its current state. A single goroutine writes the
+// file, so callers never wait for disk I/O while holding their own locks.
+type Journal struct {
+	path  string
+	file  *os.File // only used by the writer goroutine
+	queue chan journalOp
+	done  chan struct{}
+
+	mu       sync.Mutex // guards closed and sends on queue
+	closed   bool
+	closeErr error
+}
+
+// journalOp is a snapshot for the writer to append, or the records to
+// rewrite the journal with if record is nil.
+type journalOp struct {
+	record  *Record
+	compact []Record
+	result  chan error // receives the outcome of a compaction, if not nil
+}
+
+// journalEntry is the on-disk representation of a Record.
+type journalEntry struct {
+	TaskID     string           `json:"task_id"`
+	JobID      string           `json:"job_id"`
+	Status     string           `json:"status"`
+	Result     string           `json:"result,omitempty"`
+	Execution  *executor.Result `json:"execution,omitempty"`
+	Error      string           `json:"error,omitempty"`
+	AssignedAt time.Time        `json:"assigned_at"`
+	StartedAt  time.Time        `json:"started_at,omitempty"`
+	EndedAt    time.Time        `json:"ended_at,omitempty"`
+}
+
+// OpenJournal opens (or creates) the journal at the given path and starts
+// its writer.
+func OpenJournal(path string) (*Journal, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+	j := &Journal{
+		path:  path,
+		file:  file,
+		queue: make(chan journalOp, journalQueueSize),
+		done:  make(chan struct{}),
+	}
+	go j.run()
+	return j, nil
+}
+
+// Load replays the journal and returns the latest record for each task. It
+// is meant to be called before anything is appended.
+func (j *Journal) Load() ([]Record, error) {
+	file, err := os.Open(j.path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	latest := make(map[string]Record)
+	var order []string
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		var entry journalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			// A torn write at the end of the file is expected after a crash
+			log.Printf("[TASKS] Skipping corrupt journal entry: %v", err)
+			continue
+		}
+		if _, seen := latest[entry.TaskID]; !seen {
+			order = append(order, entry.TaskID)
+		}
+		latest[entry.TaskID] = entry.record()
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	records := make([]Record, 0, len(order))
+	for _, id := range order {
+		records = append(records, latest[id])
+	}
+	return records, nil
+}
+
+// Append queues a snapshot of the record to be written to the journal.
+// Snapshots are written in the order they are appended.
+func (j *Journal) Append(rec Record) {
+	j.send(journalOp{record: &rec})
+}
+
+// Compact rewrites the journal so it only contains the given records, after
+// all snapshots appended before.
+func (j *Journal) Compact(records []Record) error {
+	result := make(chan error, 1)
+	if !j.send(journalOp{compact: records, result: result}) {
+		return errors.New("journal is closed")
+	}
+	return <-result
+}
+
+// compactLater queues a compaction without waiting for it.
+func (j *Journal) compactLater(records []Record) {
+	j.send(journalOp{compact: records})
+}
+
+// send queues an operation for the writer. It reports false if the journal
+// is closed.
+func (j *Journal) send(op journalOp) bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.closed {
+		return false
+	}
+	j.queue <- op
+	return true
+}
+
+// Close writes the queued snapshots and closes the journal file.
+func (j *Journal) Close() error {
+	j.mu.Lock()
+	if !j.closed {
+		j.closed = true
+		close(j.queue)
+	}
+	j.mu.Unlock()
+
+	<-j.done
+	return j.closeErr
+}
+
+// run writes queued operations until the journal is closed. Appended
+// snapshots are synced to disk once the queue is empty, so a burst of
+// transitions costs a single fsync.
+func (j *Journal) run() {
+	defer close(j.done)
+	for op := range j.queue {
+		if op.record != nil {
+			if err := j.write(*op.record); err != nil {
+				log.Printf("[TASKS] Failed to journal task %s: %v", op.record.TaskID, err)
+			}
+			continue
+		}
+		err := j.rewrite(op.compact)
+		if op.result != nil {
+			op.result <- err
+		} else if err != nil {
+			log.Printf("[TASKS] Failed to compact journal: %v", err)
+		}
+	}
+	j.closeErr = j.file.Close()
+}
+
+// write appends a snapshot, and syncs the file if no other write is queued.
+func (j *Journal) write(rec Record) error {
+	data, err := json.Marshal(newJournalEntry(rec))
+	if err != nil {
+		return err
+	}
+	if _, err := j.file.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	if len(j.queue) > 0 {
+		return nil
+	}
+	return j.file.Sync()
+}
+
+// rewrite replaces the journal with one that only contains the records.
+func (j *Journal) rewrite(records []Record) error {
+	tmpPath := j.path + ".tmp"
+	tmp, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(tmp)
+	for _, rec := range records {
+		data, err := json.Marshal(newJournalEntry(rec))
+		if err != nil {
+			tmp.Close()
+			return err
+		}
+		w.Write(append(data, '\n'))
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	tmp.Close()
+
+	if err := os.Rename(tmpPath, j.path); err != nil {
+		return fmt.Errorf("failed to replace journal: %w", err)
+	}
+
+	// Reopen so further appends go to the compacted file
+	j.file.Close()
+	file, err := os.OpenFile(j.path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	j.file = file
+	return nil
+}
+
+// newJournalEntry converts a record for the journal. The output of a finished
+// process is only stored as its stdout and stderr; the combined output is
+// rebuilt from them when the journal is read.
+func newJournalEntry(rec Record) journalEntry {
+	result := rec.Result
+	if rec.Execution != nil && result == rec.Execution.Output {
+		result = ""
+	}
+	return journalEntry{
+		TaskID:     rec.TaskID,
+		JobID:      rec.JobID,
+		Status:     rec.Status.String(),
+		Result:     result,
+		Execution:  rec.Execution,
+		Error:      rec.Error,
+		AssignedAt: rec.AssignedAt,
+		StartedAt:  rec.StartedAt,
+		EndedAt:    rec.EndedAt,
+	}
+}
+
+func (e journalEntry) record() Record {
+	if e.Execution != nil && e.Result == "" {
+		// The interleaving of the two streams is not kept
+		e.Execution.Output = e.Execution.Stdout + e.Execution.Stderr
+		e.Result = e.Execution.Output
+	}
+	return Record{
+		TaskID:     e.TaskID,
+		JobID:      e.JobID,
+		Status:     proto.TaskStatus(proto.TaskStatus_value[e.Status]),
+		Result:     e.Result,
+		Execution:  e.Execution,
+		Error:      e.Error,
+		AssignedAt: e.AssignedAt,
+		StartedAt:  e.StartedAt,
+		EndedAt:    e.EndedAt,
+	}
+}
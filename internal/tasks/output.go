@@ -0,0 +1,122 @@
+package tasks
+
+import (
+	"sync"
+	"time"
+)
+
+// maxBufferedOutput is how many bytes of output are kept per task for replay.
+// Older chunks are dropped once the limit is reached, and readers that ask
+// for them are told how many they missed.
+const maxBufferedOutput = 4 * 1024 * 1024
+
+// Stream identifies where a chunk of output came from.
+type Stream int
+
+const (
+	Stdout Stream = iota
+	Stderr
+)
+
+// Chunk is a piece of output produced by a task.
+type Chunk struct {
+	Sequence uint64
+	Stream   Stream
+	Data     []byte
+	Time     time.Time
+	// Dropped is how many chunks right before this one were dropped from
+	// the buffer. It is only set on the first chunk Read returns.
+	Dropped uint64
+}
+
+// Output buffers the live output of a task and wakes up readers when more arrives.
+type Output struct {
+	mu      sync.Mutex
+	chunks  []Chunk
+	size    int
+	nextSeq uint64
+	closed  bool
+	notify  chan struct{}
+}
+
+func newOutput() *Output {
+	return &Output{notify: make(chan struct{})}
+}
+
+// Writer returns a writer that appends to the given stream.
+func (o *Output) Writer(stream Stream) *StreamWriter {
+	return &StreamWriter{output: o, stream: stream}
+}
+
+// Read returns the buffered chunks with a sequence number of at least from,
+// a channel that is closed when more output arrives, and whether the output
+// is complete. If chunks from on were dropped, the first chunk's Dropped
+// says how many.
+func (o *Output) Read(from uint64) ([]Chunk, <-chan struct{}, bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	var chunks []Chunk
+	for _, c := range o.chunks {
+		if c.Sequence >= from {
+			chunks = append(chunks, c)
+		}
+	}
+	if len(chunks) > 0 && chunks[0].Sequence > from {
+		chunks[0].Dropped = chunks[0].Sequence - from
+	}
+	return chunks, o.notify, o.closed
+}
+
+// Close marks the output as complete and wakes up all readers.
+func (o *Output) Close() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.closed {
+		return
+	}
+	o.closed = true
+	close(o.notify)
+}
+
+func (o *Output) append(stream Stream, data []byte) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.closed {
+		return
+	}
+
+	o.chunks = append(o.chunks, Chunk{
+		Sequence: o.nextSeq,
+		Stream:   stream,
+		Data:     append([]byte(nil), data...),
+		Time:     time.Now(),
+	})
+	o.nextSeq++
+	o.size += len(data)
+
+	// Drop the oldest chunks to stay within the buffer limit
+	for o.size > maxBufferedOutput && len(o.chunks) > 1 {
+		o.size -= len(o.chunks[0].Data)
+		o.chunks = o.chunks[1:]
+	}
+
+	// Wake up readers waiting for more output
+	close(o.notify)
+	o.notify = make(chan struct{})
+}
+
+// StreamWriter writes into one stream of an Output.
+type StreamWriter struct {
+	output *Output
+	stream Stream
+}
+
+func (w *StreamWriter) Write(p []byte) (int, error) {
+	if len(p) > 0 {
+		w.output.append(w.stream, p)
+	}
+	return len(p), nil
+}
@@ -0,0 +1,319 @@
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"openshield-agent/internal/executor"
+	"openshield-agent/proto"
+)
+
+// retention is how long finished records are kept before being pruned.
+const retention = 24 * time.Hour
+
+// The journal is compacted once it holds compactFactor times as many entries
+// as there are records, and at least minCompactEntries.
+const (
+	compactFactor     = 4
+	minCompactEntries = 1000
+)
+
+// Record holds the state of a single task assignment.
+type Record struct {
+	TaskID     string
+	JobID      string
+	Status     proto.TaskStatus
+	Result     string // combined output
+	Execution  *executor.Result
+	Error      string
+	AssignedAt time.Time
+	StartedAt  time.Time
+	EndedAt    time.Time
+}
+
+// Finished reports whether the task has reached a terminal status.
+func (r Record) Finished() bool {
+	switch r.Status {
+	case proto.TaskStatus_COMPLETED, proto.TaskStatus_FAILED, proto.TaskStatus_CANCELLED:
+		return true
+	}
+	return false
+}
+
+// interruptedReason is recorded for tasks that were active when the agent stopped.
+const interruptedReason = "task interrupted by agent restart"
+
+// Registry tracks task assignments by task ID and limits how many run at once.
+type Registry struct {
+	mu      sync.Mutex
+	records map[string]*Record
+	cancels map[string]context.CancelFunc
+	outputs map[string]*Output
+	slots   chan struct{}
+	journal *Journal
+	// journalEntries counts the entries in the journal since it was compacted
+	journalEntries int
+}
+
+// NewRegistry creates a registry that allows at most maxConcurrent tasks to run at once.
+func NewRegistry(maxConcurrent int) *Registry {
+	if maxConcurrent < 1 {
+		maxConcurrent = 1
+	}
+	return &Registry{
+		records: make(map[string]*Record),
+		cancels: make(map[string]context.CancelFunc),
+		outputs: make(map[string]*Output),
+		slots:   make(chan struct{}, maxConcurrent),
+	}
+}
+
+// UseJournal restores records from the journal and persists all further
+// transitions to it. Tasks that were still active when the journal was last
+// written are marked as FAILED, since their process did not survive the restart.
+func (r *Registry) UseJournal(j *Journal) error {
+	records, err := j.Load()
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for i := range records {
+		rec := records[i]
+		if !rec.Finished() {
+			log.Printf("[TASKS] Marking interrupted task %s as failed", rec.TaskID)
+			rec.Status = proto.TaskStatus_FAILED
+			rec.Error = interruptedReason
+			rec.EndedAt = now
+		}
+		r.records[rec.TaskID] = &rec
+	}
+	r.prune()
+
+	// Rewrite the journal with only the retained records
+	retained := make([]Record, 0, len(r.records))
+	for _, rec := range r.records {
+		retained = append(retained, *rec)
+	}
+	if err := j.Compact(retained); err != nil {
+		return err
+	}
+
+	r.journal = j
+	r.journalEntries = len(retained)
+	return nil
+}
+
+// persist queues a snapshot of the record for the journal, and a compaction
+// once the journal has grown well past the live records. Callers must hold
+// r.mu; the journal is written in the background.
+func (r *Registry) persist(rec *Record) {
+	if r.journal == nil {
+		return
+	}
+	r.journal.Append(*rec)
+	r.journalEntries++
+
+	if r.journalEntries < minCompactEntries || r.journalEntries < compactFactor*len(r.records) {
+		return
+	}
+	retained := make([]Record, 0, len(r.records))
+	for _, rec := range r.records {
+		retained = append(retained, *rec)
+	}
+	r.journal.compactLater(retained)
+	r.journalEntries = len(retained)
+}
+
+// Add registers a new PENDING task and returns the context it should run with.
+// The context is cancelled when the task is cancelled. Add fails if a task with
+// the same ID is still active.
+func (r *Registry) Add(taskID, jobID string) (context.Context, error) {
+	if taskID == "" {
+		return nil, fmt.Errorf("task ID cannot be empty")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if existing, ok := r.records[taskID]; ok && !existing.Finished() {
+		return nil, fmt.Errorf("task %s is already %s", taskID, existing.Status)
+	}
+	r.prune()
+
+	rec := &Record{
+		TaskID:     taskID,
+		JobID:      jobID,
+		Status:     proto.TaskStatus_PENDING,
+		AssignedAt: time.Now(),
+	}
+	r.records[taskID] = rec
+	r.outputs[taskID] = newOutput()
+	r.persist(rec)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancels[taskID] = cancel
+	return ctx, nil
+}
+
+// Acquire blocks until a run slot is free and marks the task as RUNNING.
+// It returns false without taking a slot if the task is cancelled while waiting.
+func (r *Registry) Acquire(ctx context.Context, taskID string) bool {
+	select {
+	case r.slots <- struct{}{}:
+	case <-ctx.Done():
+		return false
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rec, ok := r.records[taskID]
+	if !ok || rec.Finished() {
+		// Cancelled just as the slot became free
+		<-r.slots
+		return false
+	}
+	rec.Status = proto.TaskStatus_RUNNING
+	rec.StartedAt = time.Now()
+	r.persist(rec)
+	return true
+}
+
+// Cancel marks an active task as CANCELLED and cancels its context, which
+// stops a pending task from starting and kills a running one.
+func (r *Registry) Cancel(taskID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rec, ok := r.records[taskID]
+	if !ok {
+		return fmt.Errorf("task %s not found", taskID)
+	}
+	if rec.Finished() {
+		return fmt.Errorf("task %s has already finished with status %s", taskID, rec.Status)
+	}
+
+	wasPending := rec.Status == proto.TaskStatus_PENDING
+	rec.Status = proto.TaskStatus_CANCELLED
+	rec.Error = "task cancelled"
+	rec.EndedAt = time.Now()
+	r.persist(rec)
+
+	// A running task closes its output once its process has exited
+	if out, ok := r.outputs[taskID]; ok && wasPending {
+		out.Close()
+	}
+
+	if cancel, ok := r.cancels[taskID]; ok {
+		cancel()
+		delete(r.cancels, taskID)
+	}
+	return nil
+}
+
+// Complete marks the task as COMPLETED with the given result and frees its run slot.
+// Complete and Fail must only be called after a successful Acquire.
+func (r *Registry) Complete(taskID string, result *executor.Result) {
+	r.finish(taskID, proto.TaskStatus_COMPLETED, result, "")
+}
+
+// Fail marks the task as FAILED with the given error and frees its run slot.
+// The result is nil if the process could not be started.
+func (r *Registry) Fail(taskID string, result *executor.Result, err error) {
+	r.finish(taskID, proto.TaskStatus_FAILED, result, err.Error())
+}
+
+func (r *Registry) finish(taskID string, status proto.TaskStatus, result *executor.Result, errMsg string) {
+	r.mu.Lock()
+	if rec, ok := r.records[taskID]; ok {
+		rec.Execution = result
+		if result != nil {
+			rec.Result = result.Output
+		}
+		// A cancelled task keeps its status, but holds on to any output produced before it
+		if rec.Status != proto.TaskStatus_CANCELLED {
+			rec.Status = status
+			rec.Error = errMsg
+			rec.EndedAt = time.Now()
+		}
+		r.persist(rec)
+	}
+	if out, ok := r.outputs[taskID]; ok {
+		out.Close()
+	}
+	if cancel, ok := r.cancels[taskID]; ok {
+		cancel()
+		delete(r.cancels, taskID)
+	}
+	r.mu.Unlock()
+
+	<-r.slots
+}
+
+// Get returns a copy of the record for the given ID. The ID is matched against
+// task IDs first and then against job IDs, returning the most recent assignment of the job.
+func (r *Registry) Get(id string) (Record, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if rec, ok := r.records[id]; ok {
+		return *rec, true
+	}
+
+	var latest *Record
+	for _, rec := range r.records {
+		if rec.JobID != id {
+			continue
+		}
+		if latest == nil || rec.AssignedAt.After(latest.AssignedAt) {
+			latest = rec
+		}
+	}
+	if latest == nil {
+		return Record{}, false
+	}
+	return *latest, true
+}
+
+// List returns copies of all records, oldest assignment first.
+func (r *Registry) List() []Record {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	records := make([]Record, 0, len(r.records))
+	for _, rec := range r.records {
+		records = append(records, *rec)
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].AssignedAt.Before(records[j].AssignedAt)
+	})
+	return records
+}
+
+// Output returns the live output buffer of a task. Tasks restored from the
+// journal have no output buffer.
+func (r *Registry) Output(taskID string) (*Output, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	out, ok := r.outputs[taskID]
+	return out, ok
+}
+
+// prune removes finished records older than the retention period. Callers must hold r.mu.
+func (r *Registry) prune() {
+	cutoff := time.Now().Add(-retention)
+	for id, rec := range r.records {
+		if rec.Finished() && rec.EndedAt.Before(cutoff) {
+			delete(r.records, id)
+			delete(r.outputs, id)
+		}
+	}
+}
@@ -0,0 +1,251 @@
+package tasks
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"openshield-agent/internal/executor"
+	"openshield-agent/proto"
+)
+
+func TestRegistryLifecycle(t *testing.T) {
+	r := NewRegistry(1)
+
+	ctx, err := r.Add("task-1", "job-1")
+	if err != nil {
+		t.Fatalf("Add returned error: %v", err)
+	}
+	if _, err := r.Add("task-1", "job-1"); err == nil {
+		t.Error("Expected duplicate active task to be rejected")
+	}
+
+	r.Acquire(ctx, "task-1")
+	rec, ok := r.Get("task-1")
+	if !ok || rec.Status != proto.TaskStatus_RUNNING {
+		t.Fatalf("Expected task-1 to be RUNNING, got %v", rec.Status)
+	}
+
+	r.Fail("task-1", &executor.Result{ExitCode: 1, Output: "partial output"}, errors.New("boom"))
+	rec, _ = r.Get("task-1")
+	if rec.Status != proto.TaskStatus_FAILED || rec.Error != "boom" || rec.Result != "partial output" {
+		t.Errorf("Unexpected record after failure: %+v", rec)
+	}
+	if rec.EndedAt.IsZero() {
+		t.Error("Expected EndedAt to be set")
+	}
+	if rec.Execution == nil || rec.Execution.ExitCode != 1 {
+		t.Errorf("Expected execution result to be kept, got %+v", rec.Execution)
+	}
+}
+
+func TestRegistryGetByJobID(t *testing.T) {
+	r := NewRegistry(2)
+	_, _ = r.Add("task-1", "job-1")
+	_, _ = r.Add("task-2", "job-2")
+
+	rec, ok := r.Get("job-2")
+	if !ok || rec.TaskID != "task-2" {
+		t.Errorf("Expected lookup by job ID to return task-2, got %+v", rec)
+	}
+	if _, ok := r.Get("missing"); ok {
+		t.Error("Expected lookup of unknown ID to fail")
+	}
+}
+
+func TestRegistryConcurrencyCap(t *testing.T) {
+	r := NewRegistry(1)
+	ctx1, _ := r.Add("task-1", "job")
+	ctx2, _ := r.Add("task-2", "job")
+
+	r.Acquire(ctx1, "task-1")
+	acquired := make(chan struct{})
+	go func() {
+		r.Acquire(ctx2, "task-2")
+		close(acquired)
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("Expected task-2 to wait for a free slot")
+	default:
+	}
+
+	r.Complete("task-1", &executor.Result{})
+	<-acquired
+	rec, _ := r.Get("task-2")
+	if rec.Status != proto.TaskStatus_RUNNING {
+		t.Errorf("Expected task-2 to be RUNNING, got %v", rec.Status)
+	}
+}
+
+func TestRegistryCancel(t *testing.T) {
+	r := NewRegistry(1)
+	ctx1, _ := r.Add("running", "job")
+	ctx2, _ := r.Add("pending", "job")
+	r.Acquire(ctx1, "running")
+
+	// Cancelling a pending task stops it from ever starting
+	if err := r.Cancel("pending"); err != nil {
+		t.Fatalf("Cancel returned error: %v", err)
+	}
+	if r.Acquire(ctx2, "pending") {
+		t.Error("Expected cancelled task not to acquire a slot")
+	}
+
+	// Cancelling a running task cancels its context and survives the final result
+	if err := r.Cancel("running"); err != nil {
+		t.Fatalf("Cancel returned error: %v", err)
+	}
+	if ctx1.Err() == nil {
+		t.Error("Expected running task context to be cancelled")
+	}
+	r.Fail("running", &executor.Result{Output: "partial", Signal: "killed"}, errors.New("signal: killed"))
+	rec, _ := r.Get("running")
+	if rec.Status != proto.TaskStatus_CANCELLED || rec.Result != "partial" {
+		t.Errorf("Expected task to stay CANCELLED with its output, got %+v", rec)
+	}
+
+	if err := r.Cancel("running"); err == nil {
+		t.Error("Expected cancelling a finished task to fail")
+	}
+}
+
+func TestRegistryJournalRestore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), JournalFilename)
+
+	journal, err := OpenJournal(path)
+	if err != nil {
+		t.Fatalf("OpenJournal returned error: %v", err)
+	}
+	r := NewRegistry(2)
+	if err := r.UseJournal(journal); err != nil {
+		t.Fatalf("UseJournal returned error: %v", err)
+	}
+	ctx, _ := r.Add("done", "job-1")
+	r.Acquire(ctx, "done")
+	r.Complete("done", &executor.Result{Output: "ok", Stdout: "ok"})
+	ctx, _ = r.Add("running", "job-2")
+	r.Acquire(ctx, "running")
+	journal.Close()
+
+	// The output is only stored as stdout and stderr
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(data, []byte(`"result"`)) {
+		t.Errorf("Expected the combined output to be left out of the journal:\n%s", data)
+	}
+
+	// Simulate an agent restart
+	journal, err = OpenJournal(path)
+	if err != nil {
+		t.Fatalf("OpenJournal returned error: %v", err)
+	}
+	defer journal.Close()
+	restored := NewRegistry(2)
+	if err := restored.UseJournal(journal); err != nil {
+		t.Fatalf("UseJournal returned error: %v", err)
+	}
+
+	rec, ok := restored.Get("done")
+	if !ok || rec.Status != proto.TaskStatus_COMPLETED || rec.Result != "ok" || rec.Execution.Stdout != "ok" {
+		t.Errorf("Expected completed task to be restored, got %+v", rec)
+	}
+	rec, ok = restored.Get("running")
+	if !ok || rec.Status != proto.TaskStatus_FAILED || rec.Error != interruptedReason {
+		t.Errorf("Expected interrupted task to be marked failed, got %+v", rec)
+	}
+}
+
+func TestRegistryJournalCompaction(t *testing.T) {
+	path := filepath.Join(t.TempDir(), JournalFilename)
+
+	journal, err := OpenJournal(path)
+	if err != nil {
+		t.Fatalf("OpenJournal returned error: %v", err)
+	}
+	r := NewRegistry(1)
+	if err := r.UseJournal(journal); err != nil {
+		t.Fatalf("UseJournal returned error: %v", err)
+	}
+	// Reusing one task ID keeps a single record while the journal grows
+	for i := 0; i < 600; i++ {
+		ctx, _ := r.Add("task", "job")
+		r.Acquire(ctx, "task")
+		r.Complete("task", &executor.Result{Output: "ok"})
+	}
+	journal.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := bytes.Count(data, []byte("\n")); lines >= minCompactEntries {
+		t.Errorf("Expected the journal to be compacted, it has %d entries", lines)
+	}
+
+	journal, err = OpenJournal(path)
+	if err != nil {
+		t.Fatalf("OpenJournal returned error: %v", err)
+	}
+	defer journal.Close()
+	records, err := journal.Load()
+	if err != nil || len(records) != 1 || records[0].Status != proto.TaskStatus_COMPLETED {
+		t.Errorf("Expected the compacted journal to hold the completed task, got %+v, %v", records, err)
+	}
+}
+
+func TestRegistryOutput(t *testing.T) {
+	r := NewRegistry(1)
+	ctx, _ := r.Add("task-1", "job")
+	r.Acquire(ctx, "task-1")
+
+	out, ok := r.Output("task-1")
+	if !ok {
+		t.Fatal("Expected task to have an output buffer")
+	}
+	_, more, _ := out.Read(0)
+	out.Writer(Stdout).Write([]byte("hello "))
+	out.Writer(Stderr).Write([]byte("oops"))
+
+	select {
+	case <-more:
+	default:
+		t.Error("Expected readers to be notified of new output")
+	}
+
+	chunks, _, closed := out.Read(1)
+	if closed || len(chunks) != 1 || chunks[0].Stream != Stderr || string(chunks[0].Data) != "oops" {
+		t.Errorf("Unexpected chunks from sequence 1: %+v", chunks)
+	}
+
+	r.Complete("task-1", &executor.Result{Output: "hello oops"})
+	if _, _, closed := out.Read(0); !closed {
+		t.Error("Expected output to be closed when the task finishes")
+	}
+}
+
+func TestOutputDropped(t *testing.T) {
+	out := newOutput()
+	chunk := make([]byte, 1024*1024)
+	for i := 0; i < 6; i++ {
+		out.Writer(Stdout).Write(chunk)
+	}
+
+	// Only the newest 4MB are kept
+	chunks, _, _ := out.Read(1)
+	if len(chunks) != 4 || chunks[0].Sequence != 2 || chunks[0].Dropped != 1 {
+		t.Fatalf("Expected chunks 2 to 5 with one dropped chunk reported, got %d chunks from %d, %d dropped",
+			len(chunks), chunks[0].Sequence, chunks[0].Dropped)
+	}
+	if chunks[1].Dropped != 0 {
+		t.Errorf("Expected only the first chunk to report dropped chunks, got %d", chunks[1].Dropped)
+	}
+	if chunks, _, _ := out.Read(3); len(chunks) != 3 || chunks[0].Dropped != 0 {
+		t.Errorf("Expected no dropped chunks reading from a buffered sequence, got %+v", chunks[0].Dropped)
+	}
+}
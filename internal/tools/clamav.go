@@ -1,34 +1,61 @@
 package tools
 
-import "openshield-agent/internal/executor"
+import (
+	"context"
+	"fmt"
+	"openshield-agent/internal/executor"
+	"runtime"
+)
 
 type ClamAVTool struct {
 	*ScriptTool
 }
 
+// clamavPackages are the ClamAV packages of each package manager, including
+// the scanner daemon and the signature updater.
+var clamavPackages = map[string][]string{
+	Apt:    {"clamav", "clamav-daemon"},
+	Dnf:    {"clamav", "clamd", "clamav-update"},
+	Yum:    {"clamav", "clamd", "clamav-update"},
+	Zypper: {"clamav"},
+	Apk:    {"clamav", "clamav-daemon"},
+	Pacman: {"clamav"},
+}
+
 // Action Exec functions for ClamAV
-func install(opts []string) (string, error) {
-	output, err := executor.ExecuteScript(ClamAV.script, []string{"install"})
+func install(ctx context.Context, opts Values, run executor.Options) (*executor.Result, error) {
+	if runtime.GOOS != "linux" {
+		return executor.ExecuteScript(ctx, ClamAV.script, []string{"install"}, run)
+	}
+	pm, err := DetectPackageManager()
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	return output, nil
+	if epel := pm.EPELPackage(); epel != "" {
+		if res, err := pm.Install(ctx, run, epel); err != nil {
+			return res, fmt.Errorf("failed to enable EPEL: %w", err)
+		}
+	}
+	return pm.Install(ctx, run, clamavPackages[pm.Backend]...)
 }
 
-func scan(opts []string) (string, error) {
-	output, err := executor.ExecuteScript(ClamAV.script, []string{"scan"})
-	if err != nil {
-		return "", err
+func scan(ctx context.Context, opts Values, run executor.Options) (*executor.Result, error) {
+	args := []string{"scan"}
+	if path := opts.Get("path"); path != "" {
+		args = append(args, path)
 	}
-	return output, nil
+	return executor.ExecuteScript(ctx, ClamAV.script, args, run)
 }
 
-func uninstall(opts []string) (string, error) {
-	output, err := executor.ExecuteScript(ClamAV.script, []string{"uninstall"})
+func uninstall(ctx context.Context, opts Values, run executor.Options) (*executor.Result, error) {
+	if runtime.GOOS != "linux" {
+		return executor.ExecuteScript(ctx, ClamAV.script, []string{"uninstall"}, run)
+	}
+	pm, err := DetectPackageManager()
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	return output, nil
+	return pm.Remove(ctx, run, clamavPackages[pm.Backend]...)
 }
 
 var ClamAV = &ClamAVTool{}
@@ -40,17 +67,17 @@ func init() {
 			Actions: []Action{
 				{
 					Name: "install",
-					Opts: []string{},
 					Exec: install,
 				},
 				{
 					Name: "scan",
-					Opts: []string{},
+					Options: []Option{
+						{Name: "path", Type: OptionPath, Description: "File or directory to scan, the script's default if not set"},
+					},
 					Exec: scan,
 				},
 				{
 					Name: "uninstall",
-					Opts: []string{},
 					Exec: uninstall,
 				},
 			},
@@ -0,0 +1,228 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"openshield-agent/internal/config"
+	"openshield-agent/internal/executor"
+	"openshield-agent/internal/models"
+	"openshield-agent/internal/scripts"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// placeholderPattern matches the option placeholders in the arguments of a
+// declarative action.
+var placeholderPattern = regexp.MustCompile(`\{([a-z][a-z0-9_]*)\}`)
+
+// RegisterToolsFromConfig loads the tool definitions from tools.yml in the
+// config directory, replacing the ones loaded before. Without the file only
+// the built-in tools are available. If the file is invalid, the previously
+// loaded tools stay registered.
+func RegisterToolsFromConfig() error {
+	cfg, err := config.LoadToolsConfig(config.ConfigPath)
+	if os.IsNotExist(err) {
+		cfg, err = &config.ToolsConfig{}, nil
+	}
+	if err != nil {
+		return err
+	}
+	tools, err := compileTools(cfg.Tools)
+	if err != nil {
+		return err
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	configuredTools = tools
+	log.Printf("[TOOL] Loaded %d tools from %s", len(tools), config.ToolsFilename)
+	return nil
+}
+
+// ValidateToolsConfig checks the contents of a tools.yml file without applying it.
+func ValidateToolsConfig(data []byte) error {
+	cfg, err := config.ParseToolsConfig(data)
+	if err != nil {
+		return err
+	}
+	_, err = compileTools(cfg.Tools)
+	return err
+}
+
+// compileTools validates tool definitions and turns them into tools.
+func compileTools(defs []config.ToolConfig) (map[string]Tool, error) {
+	tools := make(map[string]Tool, len(defs))
+	for _, def := range defs {
+		if def.Name == "" {
+			return nil, errors.New("tool without a name")
+		}
+		if strings.Contains(def.Name, "/") {
+			return nil, fmt.Errorf("invalid tool name %q", def.Name)
+		}
+		if _, exists := tools[def.Name]; exists {
+			return nil, fmt.Errorf("tool %s is defined twice", def.Name)
+		}
+		if len(def.OS) == 0 {
+			return nil, fmt.Errorf("tool %s has no os", def.Name)
+		}
+		for _, os := range def.OS {
+			if os != models.OSLinux && os != models.OSWindows && os != models.OSDarwin {
+				return nil, fmt.Errorf("tool %s: unknown os %q", def.Name, os)
+			}
+		}
+		if len(def.Actions) == 0 {
+			return nil, fmt.Errorf("tool %s has no actions", def.Name)
+		}
+
+		tool := Tool{Name: def.Name, OS: def.OS, Lock: def.Lock}
+		for _, actionDef := range def.Actions {
+			action, err := compileAction(def, actionDef)
+			if err != nil {
+				return nil, fmt.Errorf("tool %s: %w", def.Name, err)
+			}
+			if tool.isActionSupported(action.Name) {
+				return nil, fmt.Errorf("tool %s: action %s is defined twice", def.Name, action.Name)
+			}
+			tool.Actions = append(tool.Actions, action)
+		}
+		tools[def.Name] = tool
+	}
+	return tools, nil
+}
+
+// compileAction turns an action definition into an action that runs its
+// script or command.
+func compileAction(tool config.ToolConfig, def config.ToolActionConfig) (Action, error) {
+	if def.Name == "" {
+		return Action{}, errors.New("action without a name")
+	}
+	if def.Install != nil || def.Remove != nil {
+		return compilePackageAction(def)
+	}
+	script := def.Script
+	if script == "" && len(def.Command) == 0 {
+		script = tool.Script
+	}
+	if script != "" && len(def.Command) > 0 {
+		return Action{}, fmt.Errorf("action %s has both a script and a command", def.Name)
+	}
+	if script == "" && len(def.Command) == 0 {
+		return Action{}, fmt.Errorf("action %s has neither a script nor a command", def.Name)
+	}
+	if script != "" && !scripts.ValidName(script) {
+		return Action{}, fmt.Errorf("action %s: invalid script name %q", def.Name, script)
+	}
+	if len(def.Command) > 0 && placeholderPattern.MatchString(def.Command[0]) {
+		return Action{}, fmt.Errorf("action %s: the command cannot be an option", def.Name)
+	}
+
+	options := make([]Option, len(def.Options))
+	for i, opt := range def.Options {
+		options[i] = Option(opt)
+	}
+	if err := validateSchema(options); err != nil {
+		return Action{}, fmt.Errorf("action %s: %w", def.Name, err)
+	}
+
+	template := def.Command
+	if script != "" {
+		template = def.Args
+		if len(template) == 0 {
+			template = []string{def.Name}
+		}
+	}
+	for _, arg := range template {
+		for _, match := range placeholderPattern.FindAllStringSubmatch(arg, -1) {
+			if !slices.ContainsFunc(options, func(o Option) bool { return o.Name == match[1] }) {
+				return Action{}, fmt.Errorf("action %s: placeholder %s is not an option", def.Name, match[0])
+			}
+		}
+	}
+
+	return Action{
+		Name:    def.Name,
+		Options: options,
+		Profile: def.Profile,
+		Exec: func(ctx context.Context, opts Values, run executor.Options) (*executor.Result, error) {
+			args := expandTemplate(template, options, opts)
+			if script != "" {
+				return executor.ExecuteScript(ctx, script, args, run)
+			}
+			return executor.Run(ctx, run, args[0], args[1:]...)
+		},
+	}, nil
+}
+
+// compilePackageAction turns an action that installs or removes packages
+// into an action that runs the package manager.
+func compilePackageAction(def config.ToolActionConfig) (Action, error) {
+	if def.Install != nil && def.Remove != nil {
+		return Action{}, fmt.Errorf("action %s both installs and removes packages", def.Name)
+	}
+	if def.Script != "" || len(def.Command) > 0 || len(def.Args) > 0 || len(def.Options) > 0 {
+		return Action{}, fmt.Errorf("action %s: a package action takes no script, command, args or options", def.Name)
+	}
+	packages, install := def.Install, true
+	if packages == nil {
+		packages, install = def.Remove, false
+	}
+	for backend := range packages {
+		if backend != "default" && !slices.Contains(packageBackends, backend) {
+			return Action{}, fmt.Errorf("action %s: unknown package manager %q", def.Name, backend)
+		}
+	}
+
+	return Action{
+		Name:    def.Name,
+		Profile: def.Profile,
+		Exec: func(ctx context.Context, opts Values, run executor.Options) (*executor.Result, error) {
+			pm, err := DetectPackageManager()
+			if err != nil {
+				return nil, err
+			}
+			names := packages.For(pm.Backend)
+			if len(names) == 0 {
+				return nil, fmt.Errorf("action %s has no packages for %s", def.Name, pm.Backend)
+			}
+			if install {
+				return pm.Install(ctx, run, names...)
+			}
+			return pm.Remove(ctx, run, names...)
+		},
+	}, nil
+}
+
+// expandTemplate replaces the option placeholders in an argument template.
+func expandTemplate(template []string, schema []Option, opts Values) []string {
+	args := make([]string, 0, len(template))
+	for _, arg := range template {
+		matches := placeholderPattern.FindAllStringSubmatch(arg, -1)
+		if len(matches) == 0 {
+			args = append(args, arg)
+			continue
+		}
+		// A list option on its own becomes one argument per item
+		if len(matches) == 1 && matches[0][0] == arg {
+			i := slices.IndexFunc(schema, func(o Option) bool { return o.Name == matches[0][1] })
+			if schema[i].optionType() == OptionList {
+				args = append(args, opts.List(matches[0][1])...)
+				continue
+			}
+		}
+
+		unset := false
+		expanded := placeholderPattern.ReplaceAllStringFunc(arg, func(placeholder string) string {
+			value, ok := opts[placeholder[1:len(placeholder)-1]]
+			unset = unset || !ok
+			return value
+		})
+		if !unset {
+			args = append(args, expanded)
+		}
+	}
+	return args
+}
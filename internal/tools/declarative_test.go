@@ -0,0 +1,109 @@
+package tools
+
+import (
+	"context"
+	"openshield-agent/internal/config"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"testing"
+)
+
+const testTools = `
+tools:
+  - name: greeter
+    os: [linux, darwin, windows]
+    actions:
+      - name: greet
+        command: [echo, hello, "{names}", "--shout={loud}"]
+        options:
+          - name: names
+            type: list
+            enum: [world, there]
+            required: true
+          - name: loud
+            type: bool
+  - name: scanner
+    os: [linux]
+    script: scanner.sh
+    actions:
+      - name: scan
+      - name: update
+        args: [update, --quiet]
+`
+
+func TestRegisterToolsFromConfig(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("echo is a shell builtin on Windows")
+	}
+	configPath := config.ConfigPath
+	config.ConfigPath = t.TempDir()
+	t.Cleanup(func() { config.ConfigPath = configPath })
+
+	if err := os.WriteFile(filepath.Join(config.ConfigPath, config.ToolsFilename), []byte(testTools), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := RegisterToolsFromConfig(); err != nil {
+		t.Fatalf("RegisterToolsFromConfig() error = %v", err)
+	}
+	t.Cleanup(func() { configuredTools = map[string]Tool{} })
+
+	tool, ok := GetTool("greeter")
+	if !ok {
+		t.Fatal("greeter tool was not registered")
+	}
+	res, err := tool.ExecAction(context.Background(), "greet", map[string]string{"names": "world,there"}, nil)
+	if err != nil {
+		t.Fatalf("ExecAction() error = %v", err)
+	}
+	if res.Stdout != "hello world there\n" {
+		t.Errorf("Stdout = %q, want %q", res.Stdout, "hello world there\n")
+	}
+	for _, opts := range []map[string]string{{"names": "; rm -rf /"}, {"names": "world", "volume": "11"}, {"loud": ""}} {
+		if _, err := tool.ExecAction(context.Background(), "greet", opts, nil); err == nil {
+			t.Errorf("ExecAction() accepted options %q", opts)
+		}
+	}
+	// Older managers send the items of the list option as bare values
+	if res, err := tool.ExecAction(context.Background(), "greet", nil, []string{"world"}); err != nil || res.Stdout != "hello world\n" {
+		t.Errorf("ExecAction() with legacy options = %v, %v", res, err)
+	}
+
+	// An invalid file keeps the loaded tools
+	if err := os.WriteFile(filepath.Join(config.ConfigPath, config.ToolsFilename), []byte("tools:\n  - name: broken\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := RegisterToolsFromConfig(); err == nil {
+		t.Error("RegisterToolsFromConfig() accepted a tool without actions")
+	}
+	if _, ok := GetTool("scanner"); !ok {
+		t.Error("scanner tool was dropped by an invalid reload")
+	}
+}
+
+func TestValidateToolsConfig(t *testing.T) {
+	for name, content := range map[string]string{
+		"no os":           "tools:\n  - name: t\n    actions: [{name: a, command: [true]}]\n",
+		"unknown os":      "tools:\n  - name: t\n    os: [plan9]\n    actions: [{name: a, command: [true]}]\n",
+		"nothing to run":  "tools:\n  - name: t\n    os: [linux]\n    actions: [{name: a}]\n",
+		"script and cmd":  "tools:\n  - name: t\n    os: [linux]\n    actions: [{name: a, script: a.sh, command: [true]}]\n",
+		"bad script name": "tools:\n  - name: t\n    os: [linux]\n    script: ../a.sh\n    actions: [{name: a}]\n",
+		"unknown option":  "tools:\n  - name: t\n    os: [linux]\n    actions: [{name: a, command: [true, \"{x}\"]}]\n",
+		"bad default":     "tools:\n  - name: t\n    os: [linux]\n    actions: [{name: a, command: [true], options: [{name: n, type: int, default: x}]}]\n",
+		"duplicate":       "tools:\n  - name: t\n    os: [linux]\n    actions: [{name: a, command: [true]}, {name: a, command: [false]}]\n",
+		"unknown key":     "tools:\n  - name: t\n    os: [linux]\n    actions: [{name: a, command: [true], run: x}]\n",
+	} {
+		if err := ValidateToolsConfig([]byte(content)); err == nil {
+			t.Errorf("%s: ValidateToolsConfig() accepted an invalid file", name)
+		}
+	}
+}
+
+func TestExpandTemplate(t *testing.T) {
+	schema := []Option{{Name: "paths", Type: OptionList}, {Name: "depth", Type: OptionInt}, {Name: "log"}}
+	got := expandTemplate([]string{"scan", "{paths}", "--depth={depth}", "--log={log}"}, schema, Values{"paths": "/a,/b", "depth": "2"})
+	if want := []string{"scan", "/a", "/b", "--depth=2"}; !slices.Equal(got, want) {
+		t.Errorf("expandTemplate() = %q, want %q", got, want)
+	}
+}
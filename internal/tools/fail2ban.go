@@ -1,71 +1,25 @@
 package tools
 
 import (
+	"context"
 	"fmt"
-	"os"
-	"os/exec"
+	"openshield-agent/internal/config"
+	"openshield-agent/internal/executor"
+	"openshield-agent/internal/services"
+	"strings"
+	"time"
 )
 
-type Fail2BanTool struct {
-	*Tool
+// fail2banJail is a jail.d file that enables the jails of a module.
+type fail2banJail struct {
+	path   string
+	config string
 }
 
-var Fail2Ban = &Fail2BanTool{
-	Tool: &Tool{
-		Name: "fail2ban",
-		Actions: []Action{
-			{
-				Name: "install",
-				Opts: []string{},
-				Exec: func(opts []string) (string, error) {
-					var output string
-					// Detect distro by reading /etc/os-release
-					osRelease, err := os.ReadFile("/etc/os-release")
-					if err != nil {
-						return "", fmt.Errorf("could not read /etc/os-release: %w", err)
-					}
-					id := parseOSReleaseID(osRelease)
-
-					var cmds []*exec.Cmd
-					switch id {
-					case "ubuntu", "debian", "kali":
-						cmds = []*exec.Cmd{
-							exec.Command("sudo", "apt-get", "update"),
-							exec.Command("sudo", "apt-get", "install", "-y", "fail2ban"),
-						}
-					case "centos", "rhel", "fedora":
-						cmds = []*exec.Cmd{
-							exec.Command("sudo", "yum", "install", "-y", "epel-release"),
-							exec.Command("sudo", "yum", "install", "-y", "fail2ban"),
-						}
-					default:
-						return "", fmt.Errorf("unsupported Linux distribution: %s", id)
-					}
-
-					for _, cmd := range cmds {
-						output, err := cmd.CombinedOutput()
-						if err != nil {
-							return "", fmt.Errorf("command %v failed: %w\nOutput: %s", cmd.Args, err, string(output))
-						}
-					}
-					return string(output), nil
-				},
-			},
-			{
-				Name: "configure",
-				Opts: []string{"ssh", "web", "mail"},
-				Exec: func(opts []string) (string, error) {
-					var output string
-					for _, opt := range opts {
-						switch opt {
-						case "ssh":
-							cmd := exec.Command("sudo", "bash", "-c", `echo -e "[sshd]\nenabled = true" | sudo tee /etc/fail2ban/jail.d/sshd.conf`)
-							output, err := cmd.CombinedOutput()
-							if err != nil {
-								return "", fmt.Errorf("failed to configure ssh jail: %w\nOutput: %s", err, string(output))
-							}
-						case "web":
-							webJails := `[nginx-http-auth]
+// fail2banJails are the jail.d files written by the configure action.
+var fail2banJails = map[string]fail2banJail{
+	"ssh": {"/etc/fail2ban/jail.d/sshd.conf", "[sshd]\nenabled = true\n"},
+	"web": {"/etc/fail2ban/jail.d/web.conf", `[nginx-http-auth]
 enabled = true
 [nginx-botsearch]
 enabled = true
@@ -91,111 +45,111 @@ enabled = true
 enabled = true
 [apache-shellshock]
 enabled = true
-`
-							cmd := exec.Command("sudo", "bash", "-c", fmt.Sprintf("echo -e '%s' | sudo tee /etc/fail2ban/jail.d/web.conf", webJails))
-							output, err := cmd.CombinedOutput()
-							if err != nil {
-								return "", fmt.Errorf("failed to configure web jail: %w\nOutput: %s", err, string(output))
-							}
-						case "mail":
-							cmd := exec.Command("sudo", "bash", "-c", `echo -e "[dovecot]\nenabled = true\n[postfix]\nenabled = true" | sudo tee /etc/fail2ban/jail.d/mail.conf`)
-							output, err := cmd.CombinedOutput()
-							if err != nil {
-								return "", fmt.Errorf("failed to configure mail jail: %w\nOutput: %s", err, string(output))
-							}
-						default:
-							return "", fmt.Errorf("unsupported module: %s", opt)
+`},
+	"mail": {"/etc/fail2ban/jail.d/mail.conf", "[dovecot]\nenabled = true\n[postfix]\nenabled = true\n"},
+}
+
+type Fail2BanTool struct {
+	*Tool
+}
+
+var Fail2Ban = &Fail2BanTool{
+	Tool: &Tool{
+		Name: "fail2ban",
+		Actions: []Action{
+			{
+				Name: "install",
+				Exec: func(ctx context.Context, opts Values, run executor.Options) (*executor.Result, error) {
+					pm, err := DetectPackageManager()
+					if err != nil {
+						return nil, err
+					}
+					if epel := pm.EPELPackage(); epel != "" {
+						if res, err := pm.Install(ctx, run, epel); err != nil {
+							return res, fmt.Errorf("failed to enable EPEL: %w", err)
 						}
 					}
-					return string(output), nil
+					return pm.Install(ctx, run, "fail2ban")
 				},
 			},
 			{
-				Name: "start",
-				Opts: []string{},
-				Exec: func(opts []string) (string, error) {
-					checkCmd := exec.Command("sudo", "systemctl", "is-active", "fail2ban")
-					output, err := checkCmd.CombinedOutput()
-					if err != nil {
-						return string(output), fmt.Errorf("failed to check fail2ban service status: %w", err)
-					}
+				Name: "configure",
+				Options: []Option{
+					{Name: "modules", Type: OptionList, Enum: []string{"ssh", "web", "mail"}, Required: true, Description: "Jails to enable"},
+				},
+				Exec: func(ctx context.Context, opts Values, run executor.Options) (*executor.Result, error) {
+					ctx, cancel := context.WithTimeout(ctx, config.Get().Executor.CommandTimeout.Std())
+					defer cancel()
 
-					if output != nil && string(output) == "active\n" {
-						// Service is running, restart it
-						restartCmd := exec.Command("sudo", "systemctl", "restart", "fail2ban")
-						output, err := restartCmd.CombinedOutput()
-						if err != nil {
-							return string(output), fmt.Errorf("failed to restart fail2ban service: %w", err)
+					start := time.Now()
+					var written []string
+					for _, module := range opts.List("modules") {
+						jail, ok := fail2banJails[module]
+						if !ok {
+							return nil, fmt.Errorf("unsupported module: %s", module)
 						}
-						return string(output), nil
-					}
-
-					// Service is not running, start it
-					startCmd := exec.Command("sudo", "systemctl", "start", "fail2ban")
-					output, err = startCmd.CombinedOutput()
-					if err != nil {
-						return string(output), fmt.Errorf("failed to start fail2ban service: %w", err)
+						if err := executor.WriteFile(ctx, jail.path, []byte(jail.config), 0o644); err != nil {
+							return nil, fmt.Errorf("failed to configure jails: %w", err)
+						}
+						written = append(written, jail.path)
 					}
-					return string(output), nil
+					return messageResult(start, "wrote "+strings.Join(written, ", ")), nil
 				},
 			},
 			{
-				Name: "stop",
-				Opts: []string{},
-				Exec: func(opts []string) (string, error) {
-					checkCmd := exec.Command("sudo", "systemctl", "is-active", "fail2ban")
-					output, err := checkCmd.CombinedOutput()
-					if err != nil {
-						return string(output), fmt.Errorf("failed to check fail2ban service status: %w", err)
-					}
+				Name: "start",
+				Exec: func(ctx context.Context, opts Values, run executor.Options) (*executor.Result, error) {
+					return controlService(ctx, func(ctx context.Context, m services.Manager) (string, error) {
+						status, err := m.Status(ctx, "fail2ban")
+						if err != nil {
+							return "", fmt.Errorf("failed to check fail2ban service status: %w", err)
+						}
 
-					if output == nil || string(output) != "active\n" {
-						// Service is not running, nothing to stop
-						return string(output), nil
-					}
+						if status.State == services.StateRunning {
+							// Service is running, restart it
+							if err := m.Restart(ctx, "fail2ban"); err != nil {
+								return "", fmt.Errorf("failed to restart fail2ban service: %w", err)
+							}
+							return "fail2ban restarted", nil
+						}
 
-					stopCmd := exec.Command("sudo", "systemctl", "stop", "fail2ban")
-					output, err = stopCmd.CombinedOutput()
-					if err != nil {
-						return string(output), fmt.Errorf("failed to stop fail2ban service: %w", err)
-					}
-					return string(output), nil
+						// Service is not running, start it
+						if err := m.Start(ctx, "fail2ban"); err != nil {
+							return "", fmt.Errorf("failed to start fail2ban service: %w", err)
+						}
+						return "fail2ban started", nil
+					})
 				},
 			},
 			{
-				Name: "uninstall",
-				Opts: []string{},
-				Exec: func(opts []string) (string, error) {
-					var output string
-
-					osRelease, err := os.ReadFile("/etc/os-release")
-					if err != nil {
-						return "", fmt.Errorf("could not read /etc/os-release: %w", err)
-					}
-					id := parseOSReleaseID(osRelease)
-
-					var cmds []*exec.Cmd
-					switch id {
-					case "ubuntu", "debian", "kali":
-						cmds = []*exec.Cmd{
-							exec.Command("sudo", "apt-get", "remove", "-y", "fail2ban"),
-							exec.Command("sudo", "apt-get", "autoremove", "-y"),
+				Name: "stop",
+				Exec: func(ctx context.Context, opts Values, run executor.Options) (*executor.Result, error) {
+					return controlService(ctx, func(ctx context.Context, m services.Manager) (string, error) {
+						status, err := m.Status(ctx, "fail2ban")
+						if err != nil {
+							return "", fmt.Errorf("failed to check fail2ban service status: %w", err)
 						}
-					case "centos", "rhel", "fedora":
-						cmds = []*exec.Cmd{
-							exec.Command("sudo", "yum", "remove", "-y", "fail2ban"),
+
+						if status.State != services.StateRunning {
+							// Service is not running, nothing to stop
+							return "fail2ban is not running", nil
 						}
-					default:
-						return "", fmt.Errorf("unsupported Linux distribution: %s", id)
-					}
 
-					for _, cmd := range cmds {
-						output, err := cmd.CombinedOutput()
-						if err != nil {
-							return string(output), fmt.Errorf("command %v failed: %w", cmd.Args, err)
+						if err := m.Stop(ctx, "fail2ban"); err != nil {
+							return "", fmt.Errorf("failed to stop fail2ban service: %w", err)
 						}
+						return "fail2ban stopped", nil
+					})
+				},
+			},
+			{
+				Name: "uninstall",
+				Exec: func(ctx context.Context, opts Values, run executor.Options) (*executor.Result, error) {
+					pm, err := DetectPackageManager()
+					if err != nil {
+						return nil, err
 					}
-					return string(output), nil
+					return pm.Remove(ctx, run, "fail2ban")
 				},
 			},
 		},
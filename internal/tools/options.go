@@ -0,0 +1,206 @@
+package tools
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Option types
+const (
+	OptionString = "string"
+	OptionInt    = "int"
+	OptionBool   = "bool"
+	OptionPath   = "path" // an absolute path
+	OptionList   = "list" // comma separated, or the option given several times
+)
+
+var optionNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// Option describes an option an action accepts.
+type Option struct {
+	Name        string   `yaml:"name"`
+	Type        string   `yaml:"type"` // string if empty
+	Description string   `yaml:"description"`
+	Enum        []string `yaml:"enum"` // allowed values of a string or list option
+	Default     string   `yaml:"default"`
+	Required    bool     `yaml:"required"`
+}
+
+// Values are the options of a request by name, after they were checked
+// against the action's schema. List options are joined with commas.
+type Values map[string]string
+
+// Get returns the value of an option, or "" if it is not set.
+func (v Values) Get(name string) string {
+	return v[name]
+}
+
+// List returns the items of a list option.
+func (v Values) List(name string) []string {
+	if v[name] == "" {
+		return nil
+	}
+	return strings.Split(v[name], ",")
+}
+
+// Bool returns the value of a bool option.
+func (v Values) Bool(name string) bool {
+	b, _ := strconv.ParseBool(v[name])
+	return b
+}
+
+// Int returns the value of an int option.
+func (v Values) Int(name string) int {
+	n, _ := strconv.Atoi(v[name])
+	return n
+}
+
+// validateSchema checks that the options of an action are well formed.
+func validateSchema(options []Option) error {
+	seen := make(map[string]bool, len(options))
+	for _, opt := range options {
+		if !optionNamePattern.MatchString(opt.Name) {
+			return fmt.Errorf("invalid option name %q", opt.Name)
+		}
+		if seen[opt.Name] {
+			return fmt.Errorf("option %s is defined twice", opt.Name)
+		}
+		seen[opt.Name] = true
+
+		switch opt.optionType() {
+		case OptionString, OptionList:
+		case OptionInt, OptionBool, OptionPath:
+			if len(opt.Enum) > 0 {
+				return fmt.Errorf("option %s: enum is only allowed for string and list options", opt.Name)
+			}
+		default:
+			return fmt.Errorf("option %s: unknown type %q", opt.Name, opt.Type)
+		}
+		if opt.Default != "" {
+			if _, err := opt.check(opt.Default); err != nil {
+				return fmt.Errorf("option %s: invalid default: %w", opt.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// optionType returns the type of the option.
+func (o Option) optionType() string {
+	if o.Type == "" {
+		return OptionString
+	}
+	return o.Type
+}
+
+// check validates a value of the option and returns it in canonical form.
+func (o Option) check(value string) (string, error) {
+	switch o.optionType() {
+	case OptionInt:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return "", fmt.Errorf("%q is not a whole number", value)
+		}
+		return strconv.Itoa(n), nil
+	case OptionBool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return "", fmt.Errorf("%q is not true or false", value)
+		}
+		return strconv.FormatBool(b), nil
+	case OptionPath:
+		if !filepath.IsAbs(value) || strings.ContainsRune(value, 0) {
+			return "", fmt.Errorf("%q is not an absolute path", value)
+		}
+		return filepath.Clean(value), nil
+	case OptionList:
+		items := strings.Split(value, ",")
+		for _, item := range items {
+			if item == "" {
+				return "", fmt.Errorf("%q has an empty item", value)
+			}
+			if len(o.Enum) > 0 && !slices.Contains(o.Enum, item) {
+				return "", fmt.Errorf("%q is not one of %s", item, strings.Join(o.Enum, ", "))
+			}
+		}
+		return value, nil
+	}
+	if len(o.Enum) > 0 && !slices.Contains(o.Enum, value) {
+		return "", fmt.Errorf("%q is not one of %s", value, strings.Join(o.Enum, ", "))
+	}
+	return value, nil
+}
+
+// legacyOption returns the index of the option that takes the bare values of
+// requests from older managers, which is the action's first list option, or
+// -1 if it has none.
+func legacyOption(schema []Option) int {
+	return slices.IndexFunc(schema, func(o Option) bool { return o.optionType() == OptionList })
+}
+
+// LegacyValues returns the values older managers can send for an action: the
+// enum of its legacy list option.
+func LegacyValues(schema []Option) []string {
+	if i := legacyOption(schema); i >= 0 {
+		return schema[i].Enum
+	}
+	return nil
+}
+
+// parseOptions checks the options of a request against the schema. Options
+// are given by name; legacy holds bare values as older managers send them,
+// which are items of the action's list option. Defaults are filled in for
+// options that are not given.
+func parseOptions(schema []Option, named map[string]string, legacy []string) (Values, error) {
+	values := Values{}
+	for name, value := range named {
+		i := slices.IndexFunc(schema, func(o Option) bool { return o.Name == name })
+		if i < 0 {
+			return nil, fmt.Errorf("unknown option %q", name)
+		}
+		value, err := schema[i].check(value)
+		if err != nil {
+			return nil, fmt.Errorf("option %s: %w", name, err)
+		}
+		values[name] = value
+	}
+
+	if len(legacy) > 0 {
+		i := legacyOption(schema)
+		if i < 0 {
+			return nil, fmt.Errorf("unexpected options %q", legacy)
+		}
+		opt := schema[i]
+		value, err := opt.check(strings.Join(legacy, ","))
+		if err != nil {
+			return nil, fmt.Errorf("option %s: %w", opt.Name, err)
+		}
+		if prev, ok := values[opt.Name]; ok {
+			value = prev + "," + value
+		}
+		values[opt.Name] = value
+	}
+
+	var errs []error
+	for _, opt := range schema {
+		if _, ok := values[opt.Name]; ok {
+			continue
+		}
+		if opt.Required {
+			errs = append(errs, fmt.Errorf("option %s is required", opt.Name))
+			continue
+		}
+		if opt.Default != "" {
+			values[opt.Name], _ = opt.check(opt.Default)
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
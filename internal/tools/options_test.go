@@ -0,0 +1,60 @@
+package tools
+
+import "testing"
+
+func TestParseOptions(t *testing.T) {
+	schema := []Option{
+		{Name: "path", Type: OptionPath, Required: true},
+		{Name: "modules", Type: OptionList, Enum: []string{"ssh", "web", "mail"}},
+		{Name: "depth", Type: OptionInt, Default: "3"},
+		{Name: "recursive", Type: OptionBool},
+		{Name: "mode", Enum: []string{"quick", "full"}, Default: "quick"},
+	}
+
+	values, err := parseOptions(schema, map[string]string{"path": "/var/../tmp", "modules": "ssh", "recursive": "true"}, []string{"web"})
+	if err != nil {
+		t.Fatalf("parseOptions() error = %v", err)
+	}
+	for name, want := range map[string]string{
+		"path":      "/tmp",
+		"modules":   "ssh,web",
+		"depth":     "3",
+		"recursive": "true",
+		"mode":      "quick",
+	} {
+		if got := values.Get(name); got != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+
+	for _, named := range []map[string]string{
+		{},                                      // path is required
+		{"path": "relative"},                    // not absolute
+		{"path": "/tmp", "modules": "ftp"},      // not in the enum
+		{"path": "/tmp", "depth": "deep"},       // not a number
+		{"path": "/tmp", "mode": "slow"},        // not in the enum
+		{"path": "/tmp", "recursive": "yes!"},   // not a bool
+		{"path": "/tmp", "colour": "red"},       // unknown
+		{"path": "/tmp", "modules": "ssh,,web"}, // empty item
+	} {
+		if _, err := parseOptions(schema, named, nil); err == nil {
+			t.Errorf("parseOptions(%q) accepted invalid options", named)
+		}
+	}
+	if _, err := parseOptions(schema, map[string]string{"path": "/tmp"}, []string{"ftp"}); err == nil {
+		t.Error("parseOptions() accepted a legacy value that is not in the enum")
+	}
+	if _, err := parseOptions(schema[:1], map[string]string{"path": "/tmp"}, []string{"ssh"}); err == nil {
+		t.Error("parseOptions() accepted legacy values without a list option")
+	}
+}
+
+func TestBuiltinSchemas(t *testing.T) {
+	for name, tool := range GetTools() {
+		for _, action := range tool.Actions {
+			if err := validateSchema(action.Options); err != nil {
+				t.Errorf("%s %s: %v", name, action.Name, err)
+			}
+		}
+	}
+}
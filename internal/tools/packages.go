@@ -0,0 +1,284 @@
+package tools
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"openshield-agent/internal/executor"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// osReleasePath is read to detect the distribution's package manager.
+var osReleasePath = "/etc/os-release"
+
+// Package manager backends
+const (
+	Apt    = "apt"
+	Dnf    = "dnf"
+	Yum    = "yum"
+	Zypper = "zypper"
+	Apk    = "apk"
+	Pacman = "pacman"
+)
+
+// packageBackends are the supported package managers.
+var packageBackends = []string{Apt, Dnf, Yum, Zypper, Apk, Pacman}
+
+// distroBackends maps distribution IDs, as in ID and ID_LIKE of os-release,
+// to their package manager. Dnf falls back to yum on older releases.
+var distroBackends = map[string]string{
+	"debian":    Apt,
+	"ubuntu":    Apt,
+	"kali":      Apt,
+	"raspbian":  Apt,
+	"linuxmint": Apt,
+	"fedora":    Dnf,
+	"rhel":      Dnf,
+	"centos":    Dnf,
+	"rocky":     Dnf,
+	"almalinux": Dnf,
+	"ol":        Dnf,
+	"amzn":      Dnf,
+	"suse":      Zypper,
+	"opensuse":  Zypper,
+	"sles":      Zypper,
+	"alpine":    Apk,
+	"arch":      Pacman,
+	"manjaro":   Pacman,
+}
+
+// ErrNoPackageManager is returned when no supported package manager is found.
+var ErrNoPackageManager = errors.New("no supported package manager found")
+
+// OSRelease holds the fields of /etc/os-release.
+type OSRelease map[string]string
+
+// ReadOSRelease reads and parses /etc/os-release.
+func ReadOSRelease() (OSRelease, error) {
+	data, err := os.ReadFile(osReleasePath)
+	if err != nil {
+		return nil, fmt.Errorf("could not read %s: %w", osReleasePath, err)
+	}
+	return parseOSRelease(data), nil
+}
+
+// parseOSRelease parses the KEY=value lines of an os-release file.
+func parseOSRelease(data []byte) OSRelease {
+	release := OSRelease{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(strings.TrimSpace(scanner.Text()), "=")
+		if !ok || strings.HasPrefix(key, "#") {
+			continue
+		}
+		release[key] = strings.Trim(value, `"'`)
+	}
+	return release
+}
+
+// IDs returns the distribution's ID followed by the ones it is like, most
+// specific first.
+func (r OSRelease) IDs() []string {
+	return append([]string{r["ID"]}, strings.Fields(r["ID_LIKE"])...)
+}
+
+// Is reports whether the distribution is, or is like, any of the IDs.
+func (r OSRelease) Is(ids ...string) bool {
+	for _, id := range r.IDs() {
+		for _, want := range ids {
+			if id == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// PackageManager installs and queries packages with the distribution's
+// package manager.
+type PackageManager struct {
+	Backend string
+	Release OSRelease
+}
+
+// DetectPackageManager finds the package manager of this system from
+// /etc/os-release, trying the distribution's ID and then those in ID_LIKE.
+func DetectPackageManager() (*PackageManager, error) {
+	release, err := ReadOSRelease()
+	if err != nil {
+		return nil, err
+	}
+	backend, err := detectBackend(release, exec.LookPath)
+	if err != nil {
+		return nil, err
+	}
+	return &PackageManager{Backend: backend, Release: release}, nil
+}
+
+// detectBackend picks the package manager for a distribution. Unknown
+// distributions get the first package manager found in PATH.
+func detectBackend(release OSRelease, lookPath func(string) (string, error)) (string, error) {
+	for _, id := range release.IDs() {
+		backend, ok := distroBackends[strings.SplitN(id, "-", 2)[0]]
+		if !ok {
+			continue
+		}
+		if backend == Dnf {
+			if _, err := lookPath(Dnf); err != nil {
+				return Yum, nil
+			}
+		}
+		return backend, nil
+	}
+	for _, backend := range []string{Apt + "-get", Dnf, Yum, Zypper, Apk, Pacman} {
+		if _, err := lookPath(backend); err == nil {
+			return strings.TrimSuffix(backend, "-get"), nil
+		}
+	}
+	return "", fmt.Errorf("%w for distribution %q", ErrNoPackageManager, release["ID"])
+}
+
+// EPELPackage returns the package that enables EPEL, where packages that
+// Fedora ships are only available from EPEL: epel-release on RHEL and its
+// rebuilds, and Oracle's own release package on Oracle Linux. It is empty
+// on other distributions, including Amazon Linux, which EPEL does not
+// support.
+func (p *PackageManager) EPELPackage() string {
+	if p.Backend != Dnf && p.Backend != Yum {
+		return ""
+	}
+	switch p.Release["ID"] {
+	case "rhel", "centos", "rocky", "almalinux":
+		return "epel-release"
+	case "ol":
+		major, _, _ := strings.Cut(p.Release["VERSION_ID"], ".")
+		if major == "" {
+			return ""
+		}
+		return "oracle-epel-release-el" + major
+	}
+	return ""
+}
+
+// installCommands returns the commands that install packages.
+func (p *PackageManager) installCommands(packages []string) [][]string {
+	switch p.Backend {
+	case Apt:
+		return [][]string{
+			{"apt-get", "update"},
+			append([]string{"env", "DEBIAN_FRONTEND=noninteractive", "apt-get", "install", "-y"}, packages...),
+		}
+	case Dnf, Yum:
+		return [][]string{append([]string{p.Backend, "install", "-y"}, packages...)}
+	case Zypper:
+		return [][]string{append([]string{"zypper", "--non-interactive", "install"}, packages...)}
+	case Apk:
+		return [][]string{append([]string{"apk", "add", "--no-progress"}, packages...)}
+	case Pacman:
+		return [][]string{append([]string{"pacman", "-S", "--noconfirm", "--needed"}, packages...)}
+	}
+	return nil
+}
+
+// removeCommands returns the commands that remove packages.
+func (p *PackageManager) removeCommands(packages []string) [][]string {
+	switch p.Backend {
+	case Apt:
+		return [][]string{
+			append([]string{"env", "DEBIAN_FRONTEND=noninteractive", "apt-get", "remove", "-y"}, packages...),
+			{"env", "DEBIAN_FRONTEND=noninteractive", "apt-get", "autoremove", "-y"},
+		}
+	case Dnf, Yum:
+		return [][]string{append([]string{p.Backend, "remove", "-y"}, packages...)}
+	case Zypper:
+		return [][]string{append([]string{"zypper", "--non-interactive", "remove"}, packages...)}
+	case Apk:
+		return [][]string{append([]string{"apk", "del", "--no-progress"}, packages...)}
+	case Pacman:
+		return [][]string{append([]string{"pacman", "-R", "--noconfirm"}, packages...)}
+	}
+	return nil
+}
+
+// Install installs the packages.
+func (p *PackageManager) Install(ctx context.Context, run executor.Options, packages ...string) (*executor.Result, error) {
+	return runPrivileged(ctx, run, p.installCommands(packages)...)
+}
+
+// Remove removes the packages.
+func (p *PackageManager) Remove(ctx context.Context, run executor.Options, packages ...string) (*executor.Result, error) {
+	return runPrivileged(ctx, run, p.removeCommands(packages)...)
+}
+
+// Installed reports whether a package is installed.
+func (p *PackageManager) Installed(ctx context.Context, run executor.Options, pkg string) (bool, error) {
+	version, err := p.Version(ctx, run, pkg)
+	return version != "", err
+}
+
+// Version returns the installed version of a package, or "" if it is not
+// installed.
+func (p *PackageManager) Version(ctx context.Context, run executor.Options, pkg string) (string, error) {
+	var args []string
+	switch p.Backend {
+	case Apt:
+		args = []string{"dpkg-query", "-W", "-f=${Status}\t${Version}", pkg}
+	case Dnf, Yum, Zypper:
+		args = []string{"rpm", "-q", "--qf", "%{VERSION}-%{RELEASE}", pkg}
+	case Apk:
+		args = []string{"apk", "list", "--installed", pkg}
+	case Pacman:
+		args = []string{"pacman", "-Q", pkg}
+	default:
+		return "", fmt.Errorf("unknown package manager %q", p.Backend)
+	}
+
+	res, err := executor.Run(ctx, run, args[0], args[1:]...)
+	if res == nil {
+		return "", err
+	}
+	if res.ExitCode != 0 {
+		// The query tools exit with an error for packages that are not installed
+		return "", nil
+	}
+	return parseVersion(p.Backend, pkg, res.Stdout), nil
+}
+
+// parseVersion extracts the version from the output of a package query.
+func parseVersion(backend, pkg, output string) string {
+	output = strings.TrimSpace(output)
+	switch backend {
+	case Apt:
+		status, version, _ := strings.Cut(output, "\t")
+		if !strings.HasSuffix(status, " installed") {
+			return ""
+		}
+		return version
+	case Apk:
+		// pkg-1.2.3-r0 x86_64 {origin} (license) [installed]
+		for _, line := range strings.Split(output, "\n") {
+			fields := strings.Fields(line)
+			if len(fields) == 0 || !strings.Contains(line, "[installed]") {
+				continue
+			}
+			// Versions start with a digit, unlike the rest of longer package names
+			version, ok := strings.CutPrefix(fields[0], pkg+"-")
+			if ok && version != "" && version[0] >= '0' && version[0] <= '9' {
+				return version
+			}
+		}
+		return ""
+	case Pacman:
+		// pkg 1.2.3-1
+		fields := strings.Fields(output)
+		if len(fields) == 2 && fields[0] == pkg {
+			return fields[1]
+		}
+		return ""
+	}
+	return output
+}
@@ -0,0 +1,101 @@
+package tools
+
+import (
+	"errors"
+	"openshield-agent/internal/config"
+	"testing"
+)
+
+func TestDetectBackend(t *testing.T) {
+	all := func(string) (string, error) { return "/usr/bin/x", nil }
+	none := func(string) (string, error) { return "", errors.New("not found") }
+
+	for _, tt := range []struct {
+		release  string
+		lookPath func(string) (string, error)
+		want     string
+	}{
+		{"ID=ubuntu\nID_LIKE=debian\n", all, Apt},
+		{"ID=pop\nID_LIKE=\"ubuntu debian\"\n", all, Apt},
+		{"ID=\"rocky\"\nID_LIKE=\"rhel centos fedora\"\n", all, Dnf},
+		{"ID=\"centos\"\nVERSION_ID=\"7\"\n", none, Yum},
+		{"ID=\"opensuse-leap\"\nID_LIKE=\"suse opensuse\"\n", all, Zypper},
+		{"ID=alpine\n", all, Apk},
+		{"ID=endeavouros\nID_LIKE=arch\n", all, Pacman},
+		{"ID=unknown\n", all, Apt},
+	} {
+		got, err := detectBackend(parseOSRelease([]byte(tt.release)), tt.lookPath)
+		if err != nil || got != tt.want {
+			t.Errorf("detectBackend(%q) = %q, %v, want %q", tt.release, got, err, tt.want)
+		}
+	}
+
+	if _, err := detectBackend(OSRelease{"ID": "unknown"}, none); !errors.Is(err, ErrNoPackageManager) {
+		t.Errorf("detectBackend() error = %v, want ErrNoPackageManager", err)
+	}
+}
+
+func TestEPELPackage(t *testing.T) {
+	for _, tt := range []struct {
+		backend, release, want string
+	}{
+		{Dnf, "ID=rocky\nID_LIKE=\"rhel centos fedora\"\n", "epel-release"},
+		{Dnf, "ID=almalinux\nID_LIKE=\"rhel centos fedora\"\n", "epel-release"},
+		{Yum, "ID=centos\n", "epel-release"},
+		{Dnf, "ID=\"ol\"\nVERSION_ID=\"9.3\"\nID_LIKE=\"fedora\"\n", "oracle-epel-release-el9"},
+		{Dnf, "ID=\"amzn\"\nVERSION_ID=\"2023\"\nID_LIKE=\"fedora\"\n", ""},
+		{Yum, "ID=\"amzn\"\nVERSION_ID=\"2\"\nID_LIKE=\"centos rhel fedora\"\n", ""},
+		{Dnf, "ID=fedora\n", ""},
+		{Apt, "ID=debian\n", ""},
+	} {
+		pm := &PackageManager{Backend: tt.backend, Release: parseOSRelease([]byte(tt.release))}
+		if got := pm.EPELPackage(); got != tt.want {
+			t.Errorf("EPELPackage() for %q = %q, want %q", tt.release, got, tt.want)
+		}
+	}
+}
+
+func TestParseVersion(t *testing.T) {
+	for _, tt := range []struct {
+		backend, output, want string
+	}{
+		{Apt, "install ok installed\t1:1.0.1+dfsg-1", "1:1.0.1+dfsg-1"},
+		{Apt, "deinstall ok config-files\t1.0.1", ""},
+		{Dnf, "1.0.3-1.el9\n", "1.0.3-1.el9"},
+		{Apk, "clamav-daemon-1.2.1-r0 x86_64 {clamav} (GPL-2.0) [installed]\nclamav-1.2.1-r0 x86_64 {clamav} (GPL-2.0) [installed]\n", "1.2.1-r0"},
+		{Pacman, "clamav 1.2.1-1\n", "1.2.1-1"},
+	} {
+		if got := parseVersion(tt.backend, "clamav", tt.output); got != tt.want {
+			t.Errorf("parseVersion(%s, %q) = %q, want %q", tt.backend, tt.output, got, tt.want)
+		}
+	}
+}
+
+func TestPackageSet(t *testing.T) {
+	err := ValidateToolsConfig([]byte(`
+tools:
+  - name: av
+    os: [linux]
+    actions:
+      - name: install
+        install:
+          default: [clamav]
+          dnf: [clamav, clamd]
+      - name: uninstall
+        remove: [clamav]
+`))
+	if err != nil {
+		t.Fatalf("ValidateToolsConfig() error = %v", err)
+	}
+	set := config.PackageSet{"default": {"clamav"}, Dnf: {"clamav", "clamd"}}
+	if got := set.For(Dnf); len(got) != 2 {
+		t.Errorf("For(dnf) = %q, want the dnf packages", got)
+	}
+	if got := set.For(Apt); len(got) != 1 {
+		t.Errorf("For(apt) = %q, want the default packages", got)
+	}
+
+	if err := ValidateToolsConfig([]byte("tools:\n  - name: av\n    os: [linux]\n    actions: [{name: i, install: {brew: [clamav]}}]\n")); err == nil {
+		t.Error("ValidateToolsConfig() accepted an unknown package manager")
+	}
+}
@@ -1,24 +1,49 @@
 package tools
 
 import (
-	"bufio"
-	"bytes"
+	"context"
 	"fmt"
+	"openshield-agent/internal/config"
+	"openshield-agent/internal/executor"
+	"openshield-agent/internal/services"
 	"openshield-agent/internal/utils"
-	"strings"
+	"sync"
+	"time"
 )
 
-var toolRegistry = make(map[string]Tool)
+var (
+	registryMu   sync.RWMutex
+	toolRegistry = make(map[string]Tool)
+	// configuredTools are the tools from tools.yml. They take precedence over
+	// built-in tools of the same name.
+	configuredTools = make(map[string]Tool)
+)
 
 func RegisterTool(t Tool) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
 	toolRegistry[t.Name] = t
 }
 
 func GetTools() map[string]Tool {
-	return toolRegistry
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	tools := make(map[string]Tool, len(toolRegistry)+len(configuredTools))
+	for name, tool := range toolRegistry {
+		tools[name] = tool
+	}
+	for name, tool := range configuredTools {
+		tools[name] = tool
+	}
+	return tools
 }
 
 func GetTool(name string) (Tool, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	if tool, exists := configuredTools[name]; exists {
+		return tool, true
+	}
 	if tool, exists := toolRegistry[name]; exists {
 		return tool, true
 	}
@@ -29,12 +54,27 @@ type Tool struct {
 	Name    string   `yaml:"name" json:"name"`
 	Actions []Action `yaml:"actions" json:"actions"`
 	OS      []string `yaml:"os" json:"os"`
+	// Lock names the resource the tool's actions hold while they run. Tools
+	// with the same lock never run at the same time. The tool's name if empty.
+	Lock string `yaml:"lock" json:"lock"`
+}
+
+// LockName returns the name of the lock the tool's actions hold.
+func (t *Tool) LockName() string {
+	if t.Lock == "" {
+		return t.Name
+	}
+	return t.Lock
 }
 
 type Action struct {
-	Name string                              `yaml:"name"`
-	Opts []string                            `yaml:"opts"`
-	Exec func(opts []string) (string, error) `yaml:"-"`
+	Name    string   `yaml:"name"`
+	Options []Option `yaml:"options"` // schema the options of a request are checked against
+	Profile string   `yaml:"profile"` // execution profile the action's processes run with
+	// Exec runs the action with the checked options. It should stop when the
+	// context is cancelled, and processes it starts should use the given
+	// executor options.
+	Exec func(ctx context.Context, opts Values, run executor.Options) (*executor.Result, error) `yaml:"-"`
 }
 
 // isActionSupported checks if the given action is supported by the tool.
@@ -57,35 +97,99 @@ func (t *Tool) isOSSupported(os string) bool {
 	return false
 }
 
-// Helper to parse ID from /etc/os-release
-func parseOSReleaseID(data []byte) string {
-	scanner := bufio.NewScanner(bytes.NewReader(data))
-	for scanner.Scan() {
-		line := scanner.Text()
-		if strings.HasPrefix(line, "ID=") {
-			return strings.Trim(strings.TrimPrefix(line, "ID="), "\"")
-		}
+// ExecAction runs an action of the tool until it finishes or ctx is
+// cancelled. The options are checked against the action's schema before
+// anything runs.
+func (t *Tool) ExecAction(ctx context.Context, action string, named map[string]string, legacy []string) (*executor.Result, error) {
+	a, opts, err := t.prepareAction(action, named, legacy)
+	if err != nil {
+		return nil, err
 	}
-	return ""
+	return a.Exec(ctx, opts, executor.Options{Profile: a.Profile})
+}
+
+// ValidateAction checks that the tool can run the action with the options
+// on this OS, without running it.
+func (t *Tool) ValidateAction(action string, named map[string]string, legacy []string) error {
+	_, _, err := t.prepareAction(action, named, legacy)
+	return err
 }
 
-func (t *Tool) ExecAction(action string, args []string) (string, error) {
+// prepareAction looks up an action and checks the options of a request.
+func (t *Tool) prepareAction(action string, named map[string]string, legacy []string) (Action, Values, error) {
 	if !t.isActionSupported(action) {
-		return "", fmt.Errorf("action %s not supported by tool %s", action, t.Name)
+		return Action{}, nil, fmt.Errorf("action %s not supported by tool %s", action, t.Name)
 	}
 
 	if !t.isOSSupported(utils.GetDeviceOS()) {
-		return "", fmt.Errorf("tool %s is not supported on this OS", t.Name)
+		return Action{}, nil, fmt.Errorf("tool %s is not supported on this OS", t.Name)
 	}
 
 	for _, a := range t.Actions {
 		if a.Name == action {
-			if a.Exec != nil {
-				return a.Exec(args)
+			if a.Exec == nil {
+				return Action{}, nil, fmt.Errorf("no execution function defined for action %s in tool %s", action, t.Name)
+			}
+			opts, err := parseOptions(a.Options, named, legacy)
+			if err != nil {
+				return Action{}, nil, fmt.Errorf("tool %s action %s: %w", t.Name, action, err)
 			}
-			return "", fmt.Errorf("no execution function defined for action %s in tool %s", action, t.Name)
+			return a, opts, nil
 		}
 	}
 
-	return "", fmt.Errorf("action %s not found in tool %s", action, t.Name)
+	return Action{}, nil, fmt.Errorf("action %s not found in tool %s", action, t.Name)
+}
+
+// runPrivileged runs commands that need root privileges in order, through
+// the privilege helper unless the agent is root, and stops at the first one
+// that fails. The returned result carries the exit status of the last
+// command run and the output of all of them.
+func runPrivileged(ctx context.Context, run executor.Options, cmds ...[]string) (*executor.Result, error) {
+	var combined *executor.Result
+	for _, args := range cmds {
+		res, err := executor.RunElevated(ctx, run, args[0], args[1:]...)
+		if res != nil {
+			if combined == nil {
+				combined = &executor.Result{StartTime: res.StartTime}
+			}
+			combined.ExitCode = res.ExitCode
+			combined.Signal = res.Signal
+			combined.Stdout += res.Stdout
+			combined.Stderr += res.Stderr
+			combined.Output += res.Output
+			combined.EndTime = res.EndTime
+			combined.Truncated = combined.Truncated || res.Truncated
+		}
+		if err != nil {
+			return combined, err
+		}
+	}
+	return combined, nil
+}
+
+// controlService runs an operation on services with the init system's service
+// manager, bounded by the command timeout. The message the operation returns
+// becomes the output of the result.
+func controlService(ctx context.Context, op func(ctx context.Context, m services.Manager) (string, error)) (*executor.Result, error) {
+	m, err := services.Detect()
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, config.Get().Executor.CommandTimeout.Std())
+	defer cancel()
+
+	start := time.Now()
+	message, err := op(ctx, m)
+	if err != nil {
+		return nil, err
+	}
+	return messageResult(start, message), nil
+}
+
+// messageResult returns the result of an action that ran no process, with a
+// message describing what it did as its output.
+func messageResult(start time.Time, message string) *executor.Result {
+	message += "\n"
+	return &executor.Result{Stdout: message, Output: message, StartTime: start, EndTime: time.Now()}
 }
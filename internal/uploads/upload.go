@@ -0,0 +1,190 @@
+package uploads
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+var (
+	// ErrTooLarge is returned for uploads that exceed MaxSize.
+	ErrTooLarge = errors.New("upload exceeds the size limit")
+	// ErrInProgress is returned when another upload with the same key is
+	// still being received.
+	ErrInProgress = errors.New("upload already in progress")
+)
+
+// Upload is a file being received in chunks. Its content is kept in a partial
+// file so an interrupted upload can be resumed from the received offset.
+type Upload struct {
+	path   string
+	file   *os.File
+	size   int64
+	closed bool
+}
+
+// Dir holds partial uploads. It is set by the caller, usually to a directory
+// in the agent's state.
+var Dir = filepath.Join("config", "state", "uploads")
+
+// MaxSize is the largest upload accepted, in bytes.
+var MaxSize int64 = 1 << 30
+
+var (
+	activeMu sync.Mutex
+	active   = map[string]bool{} // partial files of open uploads
+)
+
+// partialPath returns the partial file of an upload. The key identifies the
+// upload, for example its kind, sync session and file name.
+func partialPath(key ...string) string {
+	parts := make([]string, len(key))
+	for i, k := range key {
+		parts[i] = url.PathEscape(k)
+	}
+	return filepath.Join(Dir, strings.Join(parts, "-")+".part")
+}
+
+// Offset returns how many bytes of the upload were received so far.
+func Offset(key ...string) (int64, error) {
+	info, err := os.Stat(partialPath(key...))
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+// Open starts or resumes an upload at offset. Resuming requires offset to
+// match the bytes already received; an offset of zero starts over. Only one
+// upload per key can be open at a time, until it is closed, finished or
+// discarded.
+func Open(offset int64, key ...string) (*Upload, error) {
+	if offset > MaxSize {
+		return nil, ErrTooLarge
+	}
+	if err := os.MkdirAll(Dir, 0700); err != nil {
+		return nil, err
+	}
+	path := partialPath(key...)
+
+	activeMu.Lock()
+	defer activeMu.Unlock()
+	if active[path] {
+		return nil, ErrInProgress
+	}
+
+	flags := os.O_WRONLY | os.O_CREATE
+	if offset == 0 {
+		flags |= os.O_TRUNC
+	}
+	f, err := os.OpenFile(path, flags, 0600)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	if info.Size() != offset {
+		f.Close()
+		return nil, fmt.Errorf("cannot resume at offset %d, %d bytes were received", offset, info.Size())
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		f.Close()
+		return nil, err
+	}
+	active[path] = true
+	return &Upload{path: path, file: f, size: offset}, nil
+}
+
+// release closes the partial file and lets the key be opened again.
+func (u *Upload) release() error {
+	if u.closed {
+		return nil
+	}
+	u.closed = true
+	activeMu.Lock()
+	delete(active, u.path)
+	activeMu.Unlock()
+	if err := u.file.Close(); err != nil && !errors.Is(err, os.ErrClosed) {
+		return err
+	}
+	return nil
+}
+
+// Write appends a chunk to the upload. A chunk that would take the upload
+// past MaxSize is not written and returns ErrTooLarge.
+func (u *Upload) Write(p []byte) (int, error) {
+	if u.size+int64(len(p)) > MaxSize {
+		return 0, ErrTooLarge
+	}
+	n, err := u.file.Write(p)
+	u.size += int64(n)
+	return n, err
+}
+
+// Size returns the bytes received so far.
+func (u *Upload) Size() int64 {
+	return u.size
+}
+
+// Close keeps the partial file so the upload can be resumed. It can be
+// called more than once, and after Finish or Discard.
+func (u *Upload) Close() error {
+	return u.release()
+}
+
+// Finish closes the partial file and checks the received content against the
+// hex encoded SHA-256. It returns the path of the complete file, which the
+// caller moves into place before calling Close. A mismatching upload is
+// discarded.
+func (u *Upload) Finish(checksum string) (string, error) {
+	if err := u.file.Sync(); err != nil {
+		u.release()
+		return "", err
+	}
+	if err := u.file.Close(); err != nil {
+		u.release()
+		return "", err
+	}
+
+	f, err := os.Open(u.path)
+	if err != nil {
+		u.release()
+		return "", err
+	}
+	h := sha256.New()
+	_, err = io.Copy(h, f)
+	f.Close()
+	if err != nil {
+		u.release()
+		return "", err
+	}
+	if sum := hex.EncodeToString(h.Sum(nil)); !strings.EqualFold(sum, checksum) {
+		u.Discard()
+		return "", fmt.Errorf("checksum mismatch: received %s, expected %s", sum, checksum)
+	}
+	return u.path, nil
+}
+
+// Discard removes the partial file.
+func (u *Upload) Discard() error {
+	if u.closed {
+		// The key may be open in another upload by now
+		return nil
+	}
+	err := os.Remove(u.path)
+	u.release()
+	return err
+}
@@ -0,0 +1,104 @@
+package uploads
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResumeUpload(t *testing.T) {
+	Dir = t.TempDir()
+	content := []byte("first part, second part")
+	sum := sha256.Sum256(content)
+
+	u, err := Open(0, "SCRIPT", "a.sh")
+	if err != nil {
+		t.Fatal(err)
+	}
+	u.Write(content[:11])
+	u.Close()
+
+	if offset, _ := Offset("SCRIPT", "a.sh"); offset != 11 {
+		t.Fatalf("Offset() = %d, want 11", offset)
+	}
+	if _, err := Open(5, "SCRIPT", "a.sh"); err == nil {
+		t.Error("Open() resumed at an offset that was not received")
+	}
+
+	u, err = Open(11, "SCRIPT", "a.sh")
+	if err != nil {
+		t.Fatalf("Open() resume error = %v", err)
+	}
+	u.Write(content[11:])
+	path, err := u.Finish(hex.EncodeToString(sum[:]))
+	if err != nil {
+		t.Fatalf("Finish() error = %v", err)
+	}
+
+	dst := filepath.Join(t.TempDir(), "a.sh")
+	if err := MoveFile(path, dst, 0755); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := os.ReadFile(dst)
+	if string(got) != string(content) {
+		t.Errorf("content = %q, want %q", got, content)
+	}
+}
+
+func TestFinishChecksumMismatch(t *testing.T) {
+	Dir = t.TempDir()
+
+	u, err := Open(0, "CONFIG", "config.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	u.Write([]byte("data"))
+	if _, err := u.Finish("00"); err == nil {
+		t.Fatal("Finish() accepted a wrong checksum")
+	}
+	if offset, _ := Offset("CONFIG", "config.yml"); offset != 0 {
+		t.Error("mismatching upload was not discarded")
+	}
+}
+
+func TestOpenInProgress(t *testing.T) {
+	Dir = t.TempDir()
+
+	u, err := Open(0, "SCRIPT", "a.sh")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Open(0, "SCRIPT", "a.sh"); !errors.Is(err, ErrInProgress) {
+		t.Fatalf("second Open() error = %v, want ErrInProgress", err)
+	}
+	u.Close()
+	u, err = Open(0, "SCRIPT", "a.sh")
+	if err != nil {
+		t.Fatalf("Open() after Close() error = %v", err)
+	}
+	u.Close()
+}
+
+func TestUploadTooLarge(t *testing.T) {
+	Dir = t.TempDir()
+	defer func(size int64) { MaxSize = size }(MaxSize)
+	MaxSize = 8
+
+	u, err := Open(0, "CONFIG", "config.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer u.Close()
+	if _, err := u.Write([]byte("12345")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := u.Write([]byte("6789")); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("Write() error = %v, want ErrTooLarge", err)
+	}
+	if _, err := Open(9, "CONFIG", "other.yml"); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("Open() error = %v, want ErrTooLarge", err)
+	}
+}
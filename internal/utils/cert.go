@@ -12,15 +12,17 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"net"
 	"net/http"
 	"openshield-agent/internal/config"
 	"os"
 	"path/filepath"
+	"strconv"
 )
 
 // GeneratePrivateKey generates a new RSA private key and saves it to the specified directory.
 func GeneratePrivateKey() error {
-	keyPath := filepath.Join(config.CertsPath, "agent.key")
+	keyPath := config.Get().TLS.KeyPath()
 	privateKey, err := rsa.GenerateKey(rand.Reader, 4096)
 	if err != nil {
 		return err
@@ -39,7 +41,7 @@ func GeneratePrivateKey() error {
 // GenerateCSR generates a Certificate Signing Request (CSR) using the provided private key and common name.
 func GenerateCSR(commonName string) error {
 	csrPath := filepath.Join(config.CertsPath, "agent.csr")
-	keyFile, err := os.ReadFile(config.CertsPath + "/agent.key")
+	keyFile, err := os.ReadFile(config.Get().TLS.KeyPath())
 	if err != nil {
 		return err
 	}
@@ -86,7 +88,8 @@ func RequestCSRSigning(csr []byte) (*CertResponse, error) {
 	}
 
 	// Create a new HTTP request to the manager
-	req, err := http.NewRequest("POST", "http://"+config.GlobalConfig.MANAGER_ADDRESS+":"+config.GlobalConfig.MANAGER_API_PORT+"/api/certs/sign", bytes.NewReader(csr))
+	manager := config.Get().Manager
+	req, err := http.NewRequest("POST", "http://"+net.JoinHostPort(manager.Address, strconv.Itoa(manager.APIPort))+"/api/certs/sign", bytes.NewReader(csr))
 	if err != nil {
 		return nil, err
 	}
@@ -126,12 +129,12 @@ func RequestCSRSigning(csr []byte) (*CertResponse, error) {
 
 // SaveCertificates saves the signed agent and CA certificates to disk.
 func SaveCertificates(certsResp *CertResponse) error {
-	agentCertPath := filepath.Join(config.CertsPath, "agent.crt")
+	agentCertPath := config.Get().TLS.CertPath()
 	if err := os.WriteFile(agentCertPath, []byte(certsResp.Cert), 0644); err != nil {
 		log.Printf("[AGENT] Failed to save agent certificate: %v", err)
 		return err
 	}
-	caCertPath := filepath.Join(config.CertsPath, "ca.crt")
+	caCertPath := config.Get().TLS.CAPath()
 	if err := os.WriteFile(caCertPath, []byte(certsResp.CA), 0644); err != nil {
 		log.Printf("[AGENT] Failed to save CA certificate: %v", err)
 		return err
@@ -140,41 +143,46 @@ func SaveCertificates(certsResp *CertResponse) error {
 }
 
 func LoadClientTLSCredentials() (*tls.Config, error) {
-	// Load TLS credentials from the provided files
-	cert, err := tls.LoadX509KeyPair(config.CertsPath+"/agent.crt", config.CertsPath+"/agent.key")
+	tlsConfig, caPool, err := loadTLSCredentials()
 	if err != nil {
 		return nil, err
 	}
-	caCert, err := os.ReadFile(config.CertsPath + "/ca.crt")
+	tlsConfig.RootCAs = caPool
+	return tlsConfig, nil
+}
+
+func LoadServerTLSCredentials() (*tls.Config, error) {
+	tlsConfig, caPool, err := loadTLSCredentials()
 	if err != nil {
 		return nil, err
 	}
-	caPool := x509.NewCertPool()
-	caPool.AppendCertsFromPEM(caCert)
-
-	return &tls.Config{
-		Certificates: []tls.Certificate{cert},
-		RootCAs:      caPool,
-	}, nil
-
+	tlsConfig.ClientCAs = caPool
+	tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	return tlsConfig, nil
 }
 
-func LoadServerTLSCredentials() (*tls.Config, error) {
-	// Load TLS credentials from the provided files
-	cert, err := tls.LoadX509KeyPair(config.CertsPath+"/agent.crt", config.CertsPath+"/agent.key")
+// loadTLSCredentials loads the agent's certificate and CA from the files in
+// the tls section and applies its version and cipher suite restrictions.
+func loadTLSCredentials() (*tls.Config, *x509.CertPool, error) {
+	settings := config.Get().TLS
+	cert, err := tls.LoadX509KeyPair(settings.CertPath(), settings.KeyPath())
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	caCert, err := os.ReadFile(config.CertsPath + "/ca.crt")
+	caCert, err := os.ReadFile(settings.CAPath())
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	caPool := x509.NewCertPool()
 	caPool.AppendCertsFromPEM(caCert)
 
+	cipherSuites, err := settings.CipherSuiteIDs()
+	if err != nil {
+		return nil, nil, err
+	}
 	return &tls.Config{
 		Certificates: []tls.Certificate{cert},
-		ClientCAs:    caPool,
-		ClientAuth:   tls.RequireAndVerifyClientCert,
-	}, nil
+		MinVersion:   config.TLSVersions[settings.MinVersion],
+		CipherSuites: cipherSuites,
+	}, caPool, nil
 }
@@ -1,15 +1,21 @@
 package utils
 
 import (
+	"context"
 	"fmt"
-	"os/exec"
+	"openshield-agent/internal/services"
 )
 
-func StartSystemdService() error {
-	if err := exec.Command("systemctl", "enable", "openshield-agent").Run(); err != nil {
+// StartAgentService enables the agent's service and starts it.
+func StartAgentService(ctx context.Context) error {
+	m, err := services.Detect()
+	if err != nil {
+		return err
+	}
+	if err := m.Enable(ctx, "openshield-agent"); err != nil {
 		return fmt.Errorf("failed to enable service: %w", err)
 	}
-	if err := exec.Command("systemctl", "start", "openshield-agent").Run(); err != nil {
+	if err := m.Start(ctx, "openshield-agent"); err != nil {
 		return fmt.Errorf("failed to start service: %w", err)
 	}
 	return nil
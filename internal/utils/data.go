@@ -4,11 +4,8 @@ import (
 	"fmt"
 	"log"
 	"net"
-	"openshield-agent/internal/models"
 	"openshield-agent/internal/osquery"
-	"os/exec"
 	"runtime"
-	"strings"
 
 	"github.com/denisbrodbeck/machineid"
 )
@@ -68,99 +65,3 @@ func GetAllLocalAddresses() ([]string, error) {
 	}
 	return addresses, nil
 }
-
-// GetAllServices returns a list of all services on the machine and their states.
-func GetAllServices() ([]models.Service, error) {
-	var cmd *exec.Cmd
-	osType := runtime.GOOS
-
-	switch osType {
-	case "windows":
-		// Use 'Get-Service' to get all services and their states
-		cmd = exec.Command("powershell", "-Command", "Get-Service | Select-Object Status,Name")
-	case "linux":
-		// Try systemctl first
-		cmd = exec.Command("systemctl", "list-units", "--type=service", "--all", "--no-pager", "--no-legend")
-	case "darwin":
-		// Use 'launchctl list' to get all services (macOS)
-		cmd = exec.Command("launchctl", "list")
-	default:
-		return nil, fmt.Errorf("unsupported OS: %s", osType)
-	}
-
-	output, err := cmd.Output()
-	if err != nil && osType == "linux" {
-		// Fallback: Try 'service --status-all' if systemctl fails
-		cmd = exec.Command("service", "--status-all")
-		output, err = cmd.Output()
-		if err != nil {
-			return nil, fmt.Errorf("failed to list services with both systemctl and service: %w", err)
-		}
-	}
-
-	var services []models.Service
-
-	switch osType {
-	case "windows":
-		// Parse output for Windows
-		lines := strings.Split(string(output), "\n")
-		for i, line := range lines {
-			// Skip header lines (first 2 lines)
-			if i < 3 {
-				continue
-			}
-			fields := strings.Fields(line)
-			if len(fields) >= 2 {
-				state := fields[0]
-				name := fields[1]
-				services = append(services, models.Service{Name: name, State: strings.ToUpper(state)})
-			}
-		}
-	case "linux":
-		// Parse output for Linux
-		if strings.Contains(cmd.String(), "systemctl") {
-			lines := strings.Split(string(output), "\n")
-			for _, line := range lines {
-				fields := strings.Fields(line)
-				if len(fields) >= 4 {
-					name := fields[0]
-					state := fields[3]
-					services = append(services, models.Service{Name: name, State: state})
-				}
-			}
-		} else {
-			// Fallback: Parse 'service --status-all' output
-			lines := strings.Split(string(output), "\n")
-			for _, line := range lines {
-				line = strings.TrimSpace(line)
-				if len(line) > 0 {
-					// Format: [ + ]  service_name
-					parts := strings.Fields(line)
-					if len(parts) >= 4 {
-						state := parts[1]
-						name := parts[3]
-						services = append(services, models.Service{Name: name, State: state})
-					}
-				}
-			}
-		}
-	case "darwin":
-		// Parse output for macOS
-		lines := strings.Split(string(output), "\n")
-		for _, line := range lines {
-			fields := strings.Fields(line)
-			if len(fields) >= 3 {
-				name := fields[2]
-				state := "Unknown"
-				if fields[0] == "-" {
-					state = "Stopped"
-				} else if fields[0] == "0" {
-					state = "Running"
-				}
-				services = append(services, models.Service{Name: name, State: state})
-			}
-		}
-	}
-
-	return services, nil
-}
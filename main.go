@@ -4,28 +4,59 @@ import (
 	"flag"
 	"log"
 	"openshield-agent/internal/config"
+	"openshield-agent/internal/executor"
 	agentgrpc "openshield-agent/internal/grpc"
+	"openshield-agent/internal/scripts"
+	"openshield-agent/internal/tools"
+	"openshield-agent/internal/uploads"
 	"openshield-agent/internal/utils"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
-	"time"
 
 	"openshield-agent/internal/service"
 )
 
 func main() {
+	// Run as the sandbox helper if started as one by the executor
+	executor.RunSandboxHelper()
+
 	// Parse command-line arguments
 	managerAddr := flag.String("manager", "", "Manager address (hostname or IP)")
 	configPath := flag.String("config", config.ConfigPath, "Path to configuration file")
 	scriptsPath := flag.String("scripts", config.ScriptsPath, "Path to scripts directory")
 	certsPath := flag.String("certs", config.CertsPath, "Path to certificates directory")
+	var heartbeatInterval, syncInterval config.Duration
+	flag.Var(&heartbeatInterval, "heartbeat-interval", "Interval between heartbeats, overrides heartbeat.interval")
+	flag.Var(&syncInterval, "sync-interval", "Interval between pulls from the manager, overrides sync.interval")
 	flag.Parse()
 	config.ConfigPath = *configPath
 	config.ScriptsPath = *scriptsPath
 	config.CertsPath = *certsPath
 
+	// Flags override config.yml, the drop-ins and the environment
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "manager":
+			config.Override(func(cfg *config.Config) { cfg.Manager.Address = *managerAddr })
+		case "heartbeat-interval":
+			config.Override(func(cfg *config.Config) { cfg.Heartbeat.Interval = heartbeatInterval })
+		case "sync-interval":
+			config.Override(func(cfg *config.Config) { cfg.Sync.Interval = syncInterval })
+		}
+	})
+
 	// Create the config directory if it doesn't exist
-	utils.CreateConfig(config.ConfigPath, *managerAddr)
+	address := *managerAddr
+	if address == "" {
+		address = os.Getenv(config.EnvPrefix + "MANAGER_ADDRESS")
+	}
+	utils.CreateConfig(config.ConfigPath, address)
+	// Partial uploads are kept with the agent's state so they can be resumed
+	uploads.Dir = filepath.Join(config.ConfigPath, "state", "uploads")
+	// Finish or roll back a script sync interrupted by a restart
+	scripts.RecoverSync()
 	// Create the scripts directory if it doesn't exist
 	utils.CreateScriptsDir(config.ScriptsPath)
 	// Create the certs directory if it doesn't exist
@@ -55,13 +86,14 @@ func main() {
 
 	// Start background tasks
 	stopHeartbeat := make(chan struct{})
-	service.ManagerHeartbeatMonitor(10*time.Second, stopHeartbeat)
+	service.ManagerHeartbeatMonitor(service.HeartbeatInterval(), stopHeartbeat)
+	service.ManagerSyncMonitor(service.SyncInterval(), stopHeartbeat)
 
 	// Load agent tools
-	// err = tools.RegisterToolsFromConfig()
-	// if err != nil {
-	// 	log.Fatalf("Failed to load tools: %v", err)
-	// }
+	err = tools.RegisterToolsFromConfig()
+	if err != nil {
+		log.Printf("Failed to load tools, only built-in tools are available: %v", err)
+	}
 
 	// Start the gRPC server
 	err = agentgrpc.StartGRPCServer(50051)
@@ -9,7 +9,9 @@ package proto
 import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
 	emptypb "google.golang.org/protobuf/types/known/emptypb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
 	unsafe "unsafe"
@@ -29,6 +31,7 @@ const (
 	TaskStatus_RUNNING   TaskStatus = 1
 	TaskStatus_COMPLETED TaskStatus = 2
 	TaskStatus_FAILED    TaskStatus = 3
+	TaskStatus_CANCELLED TaskStatus = 4
 )
 
 // Enum value maps for TaskStatus.
@@ -38,12 +41,14 @@ var (
 		1: "RUNNING",
 		2: "COMPLETED",
 		3: "FAILED",
+		4: "CANCELLED",
 	}
 	TaskStatus_value = map[string]int32{
 		"PENDING":   0,
 		"RUNNING":   1,
 		"COMPLETED": 2,
 		"FAILED":    3,
+		"CANCELLED": 4,
 	}
 )
 
@@ -74,6 +79,98 @@ func (TaskStatus) EnumDescriptor() ([]byte, []int) {
 	return file_proto_rpc_proto_rawDescGZIP(), []int{0}
 }
 
+type OutputStream int32
+
+const (
+	OutputStream_STDOUT OutputStream = 0
+	OutputStream_STDERR OutputStream = 1
+)
+
+// Enum value maps for OutputStream.
+var (
+	OutputStream_name = map[int32]string{
+		0: "STDOUT",
+		1: "STDERR",
+	}
+	OutputStream_value = map[string]int32{
+		"STDOUT": 0,
+		"STDERR": 1,
+	}
+)
+
+func (x OutputStream) Enum() *OutputStream {
+	p := new(OutputStream)
+	*p = x
+	return p
+}
+
+func (x OutputStream) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (OutputStream) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_rpc_proto_enumTypes[1].Descriptor()
+}
+
+func (OutputStream) Type() protoreflect.EnumType {
+	return &file_proto_rpc_proto_enumTypes[1]
+}
+
+func (x OutputStream) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use OutputStream.Descriptor instead.
+func (OutputStream) EnumDescriptor() ([]byte, []int) {
+	return file_proto_rpc_proto_rawDescGZIP(), []int{1}
+}
+
+type FileKind int32
+
+const (
+	FileKind_SCRIPT FileKind = 0
+	FileKind_CONFIG FileKind = 1
+)
+
+// Enum value maps for FileKind.
+var (
+	FileKind_name = map[int32]string{
+		0: "SCRIPT",
+		1: "CONFIG",
+	}
+	FileKind_value = map[string]int32{
+		"SCRIPT": 0,
+		"CONFIG": 1,
+	}
+)
+
+func (x FileKind) Enum() *FileKind {
+	p := new(FileKind)
+	*p = x
+	return p
+}
+
+func (x FileKind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (FileKind) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_rpc_proto_enumTypes[2].Descriptor()
+}
+
+func (FileKind) Type() protoreflect.EnumType {
+	return &file_proto_rpc_proto_enumTypes[2]
+}
+
+func (x FileKind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use FileKind.Descriptor instead.
+func (FileKind) EnumDescriptor() ([]byte, []int) {
+	return file_proto_rpc_proto_rawDescGZIP(), []int{2}
+}
+
 type Job struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
@@ -81,6 +178,12 @@ type Job struct {
 	Description   string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
 	Type          string                 `protobuf:"bytes,4,opt,name=type,proto3" json:"type,omitempty"`
 	Target        string                 `protobuf:"bytes,5,opt,name=target,proto3" json:"target,omitempty"`
+	Args          []string               `protobuf:"bytes,6,rep,name=args,proto3" json:"args,omitempty"`                                                                         // Extra arguments appended to the command or script
+	Timeout       *durationpb.Duration   `protobuf:"bytes,7,opt,name=timeout,proto3" json:"timeout,omitempty"`                                                                   // Overrides the agent's executor.command_timeout when set
+	Env           map[string]string      `protobuf:"bytes,8,rep,name=env,proto3" json:"env,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // Added to the agent's environment; loader and interpreter variables such as PATH, LD_* or BASH_ENV are rejected
+	WorkingDir    string                 `protobuf:"bytes,9,opt,name=working_dir,json=workingDir,proto3" json:"working_dir,omitempty"`
+	Stdin         []byte                 `protobuf:"bytes,10,opt,name=stdin,proto3" json:"stdin,omitempty"`
+	Profile       string                 `protobuf:"bytes,11,opt,name=profile,proto3" json:"profile,omitempty"` // Execution profile from profiles.yml to sandbox the job with
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -150,6 +253,48 @@ func (x *Job) GetTarget() string {
 	return ""
 }
 
+func (x *Job) GetArgs() []string {
+	if x != nil {
+		return x.Args
+	}
+	return nil
+}
+
+func (x *Job) GetTimeout() *durationpb.Duration {
+	if x != nil {
+		return x.Timeout
+	}
+	return nil
+}
+
+func (x *Job) GetEnv() map[string]string {
+	if x != nil {
+		return x.Env
+	}
+	return nil
+}
+
+func (x *Job) GetWorkingDir() string {
+	if x != nil {
+		return x.WorkingDir
+	}
+	return ""
+}
+
+func (x *Job) GetStdin() []byte {
+	if x != nil {
+		return x.Stdin
+	}
+	return nil
+}
+
+func (x *Job) GetProfile() string {
+	if x != nil {
+		return x.Profile
+	}
+	return ""
+}
+
 type Task struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
@@ -330,27 +475,36 @@ func (x *AssignTaskResponse) GetMessage() string {
 	return ""
 }
 
-type JobStatusRequest struct {
+// Outcome of a process the agent started. It is absent when the process
+// could not be started at all.
+type ExecutionResult struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	JobId         string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	ExitCode      int32                  `protobuf:"varint,1,opt,name=exit_code,json=exitCode,proto3" json:"exit_code,omitempty"`
+	Stdout        []byte                 `protobuf:"bytes,2,opt,name=stdout,proto3" json:"stdout,omitempty"`
+	Stderr        []byte                 `protobuf:"bytes,3,opt,name=stderr,proto3" json:"stderr,omitempty"`
+	Signal        string                 `protobuf:"bytes,4,opt,name=signal,proto3" json:"signal,omitempty"` // Signal that terminated the process, if any
+	StartedAt     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	FinishedAt    *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=finished_at,json=finishedAt,proto3" json:"finished_at,omitempty"`
+	Truncated     bool                   `protobuf:"varint,7,opt,name=truncated,proto3" json:"truncated,omitempty"` // Output exceeded the agent's limit and was cut off
+	Resources     *ResourceUsage         `protobuf:"bytes,8,opt,name=resources,proto3" json:"resources,omitempty"`  // Absent if the agent could not measure it
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *JobStatusRequest) Reset() {
-	*x = JobStatusRequest{}
+func (x *ExecutionResult) Reset() {
+	*x = ExecutionResult{}
 	mi := &file_proto_rpc_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *JobStatusRequest) String() string {
+func (x *ExecutionResult) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*JobStatusRequest) ProtoMessage() {}
+func (*ExecutionResult) ProtoMessage() {}
 
-func (x *JobStatusRequest) ProtoReflect() protoreflect.Message {
+func (x *ExecutionResult) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_rpc_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -362,41 +516,91 @@ func (x *JobStatusRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use JobStatusRequest.ProtoReflect.Descriptor instead.
-func (*JobStatusRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use ExecutionResult.ProtoReflect.Descriptor instead.
+func (*ExecutionResult) Descriptor() ([]byte, []int) {
 	return file_proto_rpc_proto_rawDescGZIP(), []int{4}
 }
 
-func (x *JobStatusRequest) GetJobId() string {
+func (x *ExecutionResult) GetExitCode() int32 {
 	if x != nil {
-		return x.JobId
+		return x.ExitCode
+	}
+	return 0
+}
+
+func (x *ExecutionResult) GetStdout() []byte {
+	if x != nil {
+		return x.Stdout
+	}
+	return nil
+}
+
+func (x *ExecutionResult) GetStderr() []byte {
+	if x != nil {
+		return x.Stderr
+	}
+	return nil
+}
+
+func (x *ExecutionResult) GetSignal() string {
+	if x != nil {
+		return x.Signal
 	}
 	return ""
 }
 
-type JobStatusResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	JobId         string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
-	Status        TaskStatus             `protobuf:"varint,2,opt,name=status,proto3,enum=TaskStatus" json:"status,omitempty"`
-	Result        string                 `protobuf:"bytes,3,opt,name=result,proto3" json:"result,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *ExecutionResult) GetStartedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartedAt
+	}
+	return nil
 }
 
-func (x *JobStatusResponse) Reset() {
-	*x = JobStatusResponse{}
+func (x *ExecutionResult) GetFinishedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.FinishedAt
+	}
+	return nil
+}
+
+func (x *ExecutionResult) GetTruncated() bool {
+	if x != nil {
+		return x.Truncated
+	}
+	return false
+}
+
+func (x *ExecutionResult) GetResources() *ResourceUsage {
+	if x != nil {
+		return x.Resources
+	}
+	return nil
+}
+
+type ResourceUsage struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	PeakMemoryBytes uint64                 `protobuf:"varint,1,opt,name=peak_memory_bytes,json=peakMemoryBytes,proto3" json:"peak_memory_bytes,omitempty"`
+	CpuTime         *durationpb.Duration   `protobuf:"bytes,2,opt,name=cpu_time,json=cpuTime,proto3" json:"cpu_time,omitempty"`
+	OomKilled       bool                   `protobuf:"varint,3,opt,name=oom_killed,json=oomKilled,proto3" json:"oom_killed,omitempty"`
+	Source          string                 `protobuf:"bytes,4,opt,name=source,proto3" json:"source,omitempty"` // "cgroup", or "rusage" which misses orphaned children and OOM kills
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *ResourceUsage) Reset() {
+	*x = ResourceUsage{}
 	mi := &file_proto_rpc_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *JobStatusResponse) String() string {
+func (x *ResourceUsage) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*JobStatusResponse) ProtoMessage() {}
+func (*ResourceUsage) ProtoMessage() {}
 
-func (x *JobStatusResponse) ProtoReflect() protoreflect.Message {
+func (x *ResourceUsage) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_rpc_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -408,54 +612,60 @@ func (x *JobStatusResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use JobStatusResponse.ProtoReflect.Descriptor instead.
-func (*JobStatusResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use ResourceUsage.ProtoReflect.Descriptor instead.
+func (*ResourceUsage) Descriptor() ([]byte, []int) {
 	return file_proto_rpc_proto_rawDescGZIP(), []int{5}
 }
 
-func (x *JobStatusResponse) GetJobId() string {
+func (x *ResourceUsage) GetPeakMemoryBytes() uint64 {
 	if x != nil {
-		return x.JobId
+		return x.PeakMemoryBytes
 	}
-	return ""
+	return 0
 }
 
-func (x *JobStatusResponse) GetStatus() TaskStatus {
+func (x *ResourceUsage) GetCpuTime() *durationpb.Duration {
 	if x != nil {
-		return x.Status
+		return x.CpuTime
 	}
-	return TaskStatus_PENDING
+	return nil
 }
 
-func (x *JobStatusResponse) GetResult() string {
+func (x *ResourceUsage) GetOomKilled() bool {
 	if x != nil {
-		return x.Result
+		return x.OomKilled
+	}
+	return false
+}
+
+func (x *ResourceUsage) GetSource() string {
+	if x != nil {
+		return x.Source
 	}
 	return ""
 }
 
-type Checksum struct {
+type CancelTaskRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Filename      string                 `protobuf:"bytes,1,opt,name=filename,proto3" json:"filename,omitempty"`
-	Checksum      string                 `protobuf:"bytes,2,opt,name=checksum,proto3" json:"checksum,omitempty"`
+	TaskId        string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *Checksum) Reset() {
-	*x = Checksum{}
+func (x *CancelTaskRequest) Reset() {
+	*x = CancelTaskRequest{}
 	mi := &file_proto_rpc_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *Checksum) String() string {
+func (x *CancelTaskRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Checksum) ProtoMessage() {}
+func (*CancelTaskRequest) ProtoMessage() {}
 
-func (x *Checksum) ProtoReflect() protoreflect.Message {
+func (x *CancelTaskRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_rpc_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -467,46 +677,40 @@ func (x *Checksum) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Checksum.ProtoReflect.Descriptor instead.
-func (*Checksum) Descriptor() ([]byte, []int) {
+// Deprecated: Use CancelTaskRequest.ProtoReflect.Descriptor instead.
+func (*CancelTaskRequest) Descriptor() ([]byte, []int) {
 	return file_proto_rpc_proto_rawDescGZIP(), []int{6}
 }
 
-func (x *Checksum) GetFilename() string {
-	if x != nil {
-		return x.Filename
-	}
-	return ""
-}
-
-func (x *Checksum) GetChecksum() string {
+func (x *CancelTaskRequest) GetTaskId() string {
 	if x != nil {
-		return x.Checksum
+		return x.TaskId
 	}
 	return ""
 }
 
-type ChecksumResponse struct {
+type CancelTaskResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Files         []*Checksum            `protobuf:"bytes,1,rep,name=files,proto3" json:"files,omitempty"`
+	Cancelled     bool                   `protobuf:"varint,1,opt,name=cancelled,proto3" json:"cancelled,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ChecksumResponse) Reset() {
-	*x = ChecksumResponse{}
+func (x *CancelTaskResponse) Reset() {
+	*x = CancelTaskResponse{}
 	mi := &file_proto_rpc_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ChecksumResponse) String() string {
+func (x *CancelTaskResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ChecksumResponse) ProtoMessage() {}
+func (*CancelTaskResponse) ProtoMessage() {}
 
-func (x *ChecksumResponse) ProtoReflect() protoreflect.Message {
+func (x *CancelTaskResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_rpc_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -518,40 +722,46 @@ func (x *ChecksumResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ChecksumResponse.ProtoReflect.Descriptor instead.
-func (*ChecksumResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use CancelTaskResponse.ProtoReflect.Descriptor instead.
+func (*CancelTaskResponse) Descriptor() ([]byte, []int) {
 	return file_proto_rpc_proto_rawDescGZIP(), []int{7}
 }
 
-func (x *ChecksumResponse) GetFiles() []*Checksum {
+func (x *CancelTaskResponse) GetCancelled() bool {
 	if x != nil {
-		return x.Files
+		return x.Cancelled
 	}
-	return nil
+	return false
 }
 
-type FileContent struct {
+func (x *CancelTaskResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type JobStatusRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Filename      string                 `protobuf:"bytes,1,opt,name=filename,proto3" json:"filename,omitempty"`
-	Content       []byte                 `protobuf:"bytes,2,opt,name=content,proto3" json:"content,omitempty"`
+	JobId         string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"` // Task ID, or job ID to get its most recent assignment
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *FileContent) Reset() {
-	*x = FileContent{}
+func (x *JobStatusRequest) Reset() {
+	*x = JobStatusRequest{}
 	mi := &file_proto_rpc_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *FileContent) String() string {
+func (x *JobStatusRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*FileContent) ProtoMessage() {}
+func (*JobStatusRequest) ProtoMessage() {}
 
-func (x *FileContent) ProtoReflect() protoreflect.Message {
+func (x *JobStatusRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_rpc_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -563,47 +773,46 @@ func (x *FileContent) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use FileContent.ProtoReflect.Descriptor instead.
-func (*FileContent) Descriptor() ([]byte, []int) {
+// Deprecated: Use JobStatusRequest.ProtoReflect.Descriptor instead.
+func (*JobStatusRequest) Descriptor() ([]byte, []int) {
 	return file_proto_rpc_proto_rawDescGZIP(), []int{8}
 }
 
-func (x *FileContent) GetFilename() string {
+func (x *JobStatusRequest) GetJobId() string {
 	if x != nil {
-		return x.Filename
+		return x.JobId
 	}
 	return ""
 }
 
-func (x *FileContent) GetContent() []byte {
-	if x != nil {
-		return x.Content
-	}
-	return nil
-}
-
-type SyncStatus struct {
+type JobStatusResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	JobId         string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	Status        TaskStatus             `protobuf:"varint,2,opt,name=status,proto3,enum=TaskStatus" json:"status,omitempty"`
+	Result        string                 `protobuf:"bytes,3,opt,name=result,proto3" json:"result,omitempty"`
+	TaskId        string                 `protobuf:"bytes,4,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	Error         string                 `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`
+	StartedAt     *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	FinishedAt    *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=finished_at,json=finishedAt,proto3" json:"finished_at,omitempty"`
+	Execution     *ExecutionResult       `protobuf:"bytes,8,opt,name=execution,proto3" json:"execution,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *SyncStatus) Reset() {
-	*x = SyncStatus{}
+func (x *JobStatusResponse) Reset() {
+	*x = JobStatusResponse{}
 	mi := &file_proto_rpc_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *SyncStatus) String() string {
+func (x *JobStatusResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SyncStatus) ProtoMessage() {}
+func (*JobStatusResponse) ProtoMessage() {}
 
-func (x *SyncStatus) ProtoReflect() protoreflect.Message {
+func (x *JobStatusResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_rpc_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -615,47 +824,1555 @@ func (x *SyncStatus) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use SyncStatus.ProtoReflect.Descriptor instead.
-func (*SyncStatus) Descriptor() ([]byte, []int) {
+// Deprecated: Use JobStatusResponse.ProtoReflect.Descriptor instead.
+func (*JobStatusResponse) Descriptor() ([]byte, []int) {
 	return file_proto_rpc_proto_rawDescGZIP(), []int{9}
 }
 
-func (x *SyncStatus) GetSuccess() bool {
+func (x *JobStatusResponse) GetJobId() string {
 	if x != nil {
-		return x.Success
+		return x.JobId
 	}
-	return false
+	return ""
 }
 
-func (x *SyncStatus) GetMessage() string {
+func (x *JobStatusResponse) GetStatus() TaskStatus {
 	if x != nil {
-		return x.Message
+		return x.Status
 	}
-	return ""
+	return TaskStatus_PENDING
 }
 
-type DeleteScriptRequest struct {
+func (x *JobStatusResponse) GetResult() string {
+	if x != nil {
+		return x.Result
+	}
+	return ""
+}
+
+func (x *JobStatusResponse) GetTaskId() string {
+	if x != nil {
+		return x.TaskId
+	}
+	return ""
+}
+
+func (x *JobStatusResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *JobStatusResponse) GetStartedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartedAt
+	}
+	return nil
+}
+
+func (x *JobStatusResponse) GetFinishedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.FinishedAt
+	}
+	return nil
+}
+
+func (x *JobStatusResponse) GetExecution() *ExecutionResult {
+	if x != nil {
+		return x.Execution
+	}
+	return nil
+}
+
+type TaskOutputRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Filename      string                 `protobuf:"bytes,1,opt,name=filename,proto3" json:"filename,omitempty"`
+	TaskId        string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	FromSequence  uint64                 `protobuf:"varint,2,opt,name=from_sequence,json=fromSequence,proto3" json:"from_sequence,omitempty"` // Skip chunks before this sequence number
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DeleteScriptRequest) Reset() {
-	*x = DeleteScriptRequest{}
+func (x *TaskOutputRequest) Reset() {
+	*x = TaskOutputRequest{}
+	mi := &file_proto_rpc_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TaskOutputRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TaskOutputRequest) ProtoMessage() {}
+
+func (x *TaskOutputRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_rpc_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TaskOutputRequest.ProtoReflect.Descriptor instead.
+func (*TaskOutputRequest) Descriptor() ([]byte, []int) {
+	return file_proto_rpc_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *TaskOutputRequest) GetTaskId() string {
+	if x != nil {
+		return x.TaskId
+	}
+	return ""
+}
+
+func (x *TaskOutputRequest) GetFromSequence() uint64 {
+	if x != nil {
+		return x.FromSequence
+	}
+	return 0
+}
+
+type TaskOutputChunk struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Sequence      uint64                 `protobuf:"varint,1,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	Stream        OutputStream           `protobuf:"varint,2,opt,name=stream,proto3,enum=OutputStream" json:"stream,omitempty"`
+	Data          []byte                 `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"`
+	Timestamp     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Dropped       uint64                 `protobuf:"varint,5,opt,name=dropped,proto3" json:"dropped,omitempty"` // Chunks right before this one that the agent no longer buffers
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TaskOutputChunk) Reset() {
+	*x = TaskOutputChunk{}
+	mi := &file_proto_rpc_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TaskOutputChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TaskOutputChunk) ProtoMessage() {}
+
+func (x *TaskOutputChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_rpc_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TaskOutputChunk.ProtoReflect.Descriptor instead.
+func (*TaskOutputChunk) Descriptor() ([]byte, []int) {
+	return file_proto_rpc_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *TaskOutputChunk) GetSequence() uint64 {
+	if x != nil {
+		return x.Sequence
+	}
+	return 0
+}
+
+func (x *TaskOutputChunk) GetStream() OutputStream {
+	if x != nil {
+		return x.Stream
+	}
+	return OutputStream_STDOUT
+}
+
+func (x *TaskOutputChunk) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *TaskOutputChunk) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+func (x *TaskOutputChunk) GetDropped() uint64 {
+	if x != nil {
+		return x.Dropped
+	}
+	return 0
+}
+
+type TaskOutputMessage struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Payload:
+	//
+	//	*TaskOutputMessage_Chunk
+	//	*TaskOutputMessage_Status
+	Payload       isTaskOutputMessage_Payload `protobuf_oneof:"payload"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TaskOutputMessage) Reset() {
+	*x = TaskOutputMessage{}
+	mi := &file_proto_rpc_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TaskOutputMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TaskOutputMessage) ProtoMessage() {}
+
+func (x *TaskOutputMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_rpc_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TaskOutputMessage.ProtoReflect.Descriptor instead.
+func (*TaskOutputMessage) Descriptor() ([]byte, []int) {
+	return file_proto_rpc_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *TaskOutputMessage) GetPayload() isTaskOutputMessage_Payload {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *TaskOutputMessage) GetChunk() *TaskOutputChunk {
+	if x != nil {
+		if x, ok := x.Payload.(*TaskOutputMessage_Chunk); ok {
+			return x.Chunk
+		}
+	}
+	return nil
+}
+
+func (x *TaskOutputMessage) GetStatus() *JobStatusResponse {
+	if x != nil {
+		if x, ok := x.Payload.(*TaskOutputMessage_Status); ok {
+			return x.Status
+		}
+	}
+	return nil
+}
+
+type isTaskOutputMessage_Payload interface {
+	isTaskOutputMessage_Payload()
+}
+
+type TaskOutputMessage_Chunk struct {
+	Chunk *TaskOutputChunk `protobuf:"bytes,1,opt,name=chunk,proto3,oneof"`
+}
+
+type TaskOutputMessage_Status struct {
+	Status *JobStatusResponse `protobuf:"bytes,2,opt,name=status,proto3,oneof"` // Sent once the task has finished
+}
+
+func (*TaskOutputMessage_Chunk) isTaskOutputMessage_Payload() {}
+
+func (*TaskOutputMessage_Status) isTaskOutputMessage_Payload() {}
+
+type Checksum struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Filename      string                 `protobuf:"bytes,1,opt,name=filename,proto3" json:"filename,omitempty"`
+	Checksum      string                 `protobuf:"bytes,2,opt,name=checksum,proto3" json:"checksum,omitempty"`
+	Version       string                 `protobuf:"bytes,3,opt,name=version,proto3" json:"version,omitempty"` // Version the script was synced with, if any
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Checksum) Reset() {
+	*x = Checksum{}
+	mi := &file_proto_rpc_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Checksum) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Checksum) ProtoMessage() {}
+
+func (x *Checksum) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_rpc_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Checksum.ProtoReflect.Descriptor instead.
+func (*Checksum) Descriptor() ([]byte, []int) {
+	return file_proto_rpc_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *Checksum) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+func (x *Checksum) GetChecksum() string {
+	if x != nil {
+		return x.Checksum
+	}
+	return ""
+}
+
+func (x *Checksum) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+type ChecksumResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Files         []*Checksum            `protobuf:"bytes,1,rep,name=files,proto3" json:"files,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChecksumResponse) Reset() {
+	*x = ChecksumResponse{}
+	mi := &file_proto_rpc_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChecksumResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChecksumResponse) ProtoMessage() {}
+
+func (x *ChecksumResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_rpc_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChecksumResponse.ProtoReflect.Descriptor instead.
+func (*ChecksumResponse) Descriptor() ([]byte, []int) {
+	return file_proto_rpc_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ChecksumResponse) GetFiles() []*Checksum {
+	if x != nil {
+		return x.Files
+	}
+	return nil
+}
+
+type FileContent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Filename      string                 `protobuf:"bytes,1,opt,name=filename,proto3" json:"filename,omitempty"`
+	Content       []byte                 `protobuf:"bytes,2,opt,name=content,proto3" json:"content,omitempty"`
+	Signature     []byte                 `protobuf:"bytes,3,opt,name=signature,proto3" json:"signature,omitempty"`     // Detached signature of content, required for scripts
+	Certificate   []byte                 `protobuf:"bytes,4,opt,name=certificate,proto3" json:"certificate,omitempty"` // PEM encoded signer certificate, chained to the agent's CA
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FileContent) Reset() {
+	*x = FileContent{}
+	mi := &file_proto_rpc_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FileContent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FileContent) ProtoMessage() {}
+
+func (x *FileContent) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_rpc_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FileContent.ProtoReflect.Descriptor instead.
+func (*FileContent) Descriptor() ([]byte, []int) {
+	return file_proto_rpc_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *FileContent) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+func (x *FileContent) GetContent() []byte {
+	if x != nil {
+		return x.Content
+	}
+	return nil
+}
+
+func (x *FileContent) GetSignature() []byte {
+	if x != nil {
+		return x.Signature
+	}
+	return nil
+}
+
+func (x *FileContent) GetCertificate() []byte {
+	if x != nil {
+		return x.Certificate
+	}
+	return nil
+}
+
+type SyncStatus struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SyncStatus) Reset() {
+	*x = SyncStatus{}
+	mi := &file_proto_rpc_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SyncStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SyncStatus) ProtoMessage() {}
+
+func (x *SyncStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_rpc_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SyncStatus.ProtoReflect.Descriptor instead.
+func (*SyncStatus) Descriptor() ([]byte, []int) {
+	return file_proto_rpc_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *SyncStatus) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *SyncStatus) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type DeleteScriptRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Filename      string                 `protobuf:"bytes,1,opt,name=filename,proto3" json:"filename,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteScriptRequest) Reset() {
+	*x = DeleteScriptRequest{}
+	mi := &file_proto_rpc_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteScriptRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteScriptRequest) ProtoMessage() {}
+
+func (x *DeleteScriptRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_rpc_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteScriptRequest.ProtoReflect.Descriptor instead.
+func (*DeleteScriptRequest) Descriptor() ([]byte, []int) {
+	return file_proto_rpc_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *DeleteScriptRequest) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+type ScriptManifestEntry struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Sha256        string                 `protobuf:"bytes,2,opt,name=sha256,proto3" json:"sha256,omitempty"`
+	Version       string                 `protobuf:"bytes,3,opt,name=version,proto3" json:"version,omitempty"`
+	Mode          uint32                 `protobuf:"varint,4,opt,name=mode,proto3" json:"mode,omitempty"` // File permission bits, 0755 if unset
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScriptManifestEntry) Reset() {
+	*x = ScriptManifestEntry{}
+	mi := &file_proto_rpc_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScriptManifestEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScriptManifestEntry) ProtoMessage() {}
+
+func (x *ScriptManifestEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_rpc_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScriptManifestEntry.ProtoReflect.Descriptor instead.
+func (*ScriptManifestEntry) Descriptor() ([]byte, []int) {
+	return file_proto_rpc_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *ScriptManifestEntry) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ScriptManifestEntry) GetSha256() string {
+	if x != nil {
+		return x.Sha256
+	}
+	return ""
+}
+
+func (x *ScriptManifestEntry) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *ScriptManifestEntry) GetMode() uint32 {
+	if x != nil {
+		return x.Mode
+	}
+	return 0
+}
+
+// Complete set of scripts the agent should have. Scripts not listed are removed.
+type ScriptManifest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Scripts       []*ScriptManifestEntry `protobuf:"bytes,1,rep,name=scripts,proto3" json:"scripts,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScriptManifest) Reset() {
+	*x = ScriptManifest{}
+	mi := &file_proto_rpc_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScriptManifest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScriptManifest) ProtoMessage() {}
+
+func (x *ScriptManifest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_rpc_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScriptManifest.ProtoReflect.Descriptor instead.
+func (*ScriptManifest) Descriptor() ([]byte, []int) {
+	return file_proto_rpc_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *ScriptManifest) GetScripts() []*ScriptManifestEntry {
+	if x != nil {
+		return x.Scripts
+	}
+	return nil
+}
+
+type ScriptSyncSession struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Needed        []string               `protobuf:"bytes,2,rep,name=needed,proto3" json:"needed,omitempty"` // Scripts that have to be sent with StageScriptFile
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScriptSyncSession) Reset() {
+	*x = ScriptSyncSession{}
+	mi := &file_proto_rpc_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScriptSyncSession) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScriptSyncSession) ProtoMessage() {}
+
+func (x *ScriptSyncSession) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_rpc_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScriptSyncSession.ProtoReflect.Descriptor instead.
+func (*ScriptSyncSession) Descriptor() ([]byte, []int) {
+	return file_proto_rpc_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *ScriptSyncSession) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *ScriptSyncSession) GetNeeded() []string {
+	if x != nil {
+		return x.Needed
+	}
+	return nil
+}
+
+type StageScriptRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	File          *FileContent           `protobuf:"bytes,2,opt,name=file,proto3" json:"file,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StageScriptRequest) Reset() {
+	*x = StageScriptRequest{}
+	mi := &file_proto_rpc_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StageScriptRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StageScriptRequest) ProtoMessage() {}
+
+func (x *StageScriptRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_rpc_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StageScriptRequest.ProtoReflect.Descriptor instead.
+func (*StageScriptRequest) Descriptor() ([]byte, []int) {
+	return file_proto_rpc_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *StageScriptRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *StageScriptRequest) GetFile() *FileContent {
+	if x != nil {
+		return x.File
+	}
+	return nil
+}
+
+type ScriptSyncRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScriptSyncRequest) Reset() {
+	*x = ScriptSyncRequest{}
+	mi := &file_proto_rpc_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScriptSyncRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScriptSyncRequest) ProtoMessage() {}
+
+func (x *ScriptSyncRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_rpc_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScriptSyncRequest.ProtoReflect.Descriptor instead.
+func (*ScriptSyncRequest) Descriptor() ([]byte, []int) {
+	return file_proto_rpc_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *ScriptSyncRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+type ScriptArg struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Description   string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	Required      bool                   `protobuf:"varint,3,opt,name=required,proto3" json:"required,omitempty"`
+	Values        []string               `protobuf:"bytes,4,rep,name=values,proto3" json:"values,omitempty"`
+	Pattern       string                 `protobuf:"bytes,5,opt,name=pattern,proto3" json:"pattern,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScriptArg) Reset() {
+	*x = ScriptArg{}
+	mi := &file_proto_rpc_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScriptArg) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScriptArg) ProtoMessage() {}
+
+func (x *ScriptArg) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_rpc_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScriptArg.ProtoReflect.Descriptor instead.
+func (*ScriptArg) Descriptor() ([]byte, []int) {
+	return file_proto_rpc_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *ScriptArg) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ScriptArg) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *ScriptArg) GetRequired() bool {
+	if x != nil {
+		return x.Required
+	}
+	return false
+}
+
+func (x *ScriptArg) GetValues() []string {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
+
+func (x *ScriptArg) GetPattern() string {
+	if x != nil {
+		return x.Pattern
+	}
+	return ""
+}
+
+type ScriptInfo struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Name           string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Package        bool                   `protobuf:"varint,2,opt,name=package,proto3" json:"package,omitempty"` // A directory with an entrypoint rather than a single file
+	Description    string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	Interpreter    string                 `protobuf:"bytes,4,opt,name=interpreter,proto3" json:"interpreter,omitempty"`
+	Os             []string               `protobuf:"bytes,5,rep,name=os,proto3" json:"os,omitempty"`
+	Args           []*ScriptArg           `protobuf:"bytes,6,rep,name=args,proto3" json:"args,omitempty"`
+	ArgsRestricted bool                   `protobuf:"varint,7,opt,name=args_restricted,json=argsRestricted,proto3" json:"args_restricted,omitempty"` // Only the arguments in args are accepted
+	Timeout        *durationpb.Duration   `protobuf:"bytes,8,opt,name=timeout,proto3" json:"timeout,omitempty"`
+	Privileges     string                 `protobuf:"bytes,9,opt,name=privileges,proto3" json:"privileges,omitempty"`
+	Error          string                 `protobuf:"bytes,10,opt,name=error,proto3" json:"error,omitempty"` // Set if the script's metadata could not be read
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ScriptInfo) Reset() {
+	*x = ScriptInfo{}
+	mi := &file_proto_rpc_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScriptInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScriptInfo) ProtoMessage() {}
+
+func (x *ScriptInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_rpc_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScriptInfo.ProtoReflect.Descriptor instead.
+func (*ScriptInfo) Descriptor() ([]byte, []int) {
+	return file_proto_rpc_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *ScriptInfo) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ScriptInfo) GetPackage() bool {
+	if x != nil {
+		return x.Package
+	}
+	return false
+}
+
+func (x *ScriptInfo) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *ScriptInfo) GetInterpreter() string {
+	if x != nil {
+		return x.Interpreter
+	}
+	return ""
+}
+
+func (x *ScriptInfo) GetOs() []string {
+	if x != nil {
+		return x.Os
+	}
+	return nil
+}
+
+func (x *ScriptInfo) GetArgs() []*ScriptArg {
+	if x != nil {
+		return x.Args
+	}
+	return nil
+}
+
+func (x *ScriptInfo) GetArgsRestricted() bool {
+	if x != nil {
+		return x.ArgsRestricted
+	}
+	return false
+}
+
+func (x *ScriptInfo) GetTimeout() *durationpb.Duration {
+	if x != nil {
+		return x.Timeout
+	}
+	return nil
+}
+
+func (x *ScriptInfo) GetPrivileges() string {
+	if x != nil {
+		return x.Privileges
+	}
+	return ""
+}
+
+func (x *ScriptInfo) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type ListScriptsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Scripts       []*ScriptInfo          `protobuf:"bytes,1,rep,name=scripts,proto3" json:"scripts,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListScriptsResponse) Reset() {
+	*x = ListScriptsResponse{}
+	mi := &file_proto_rpc_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListScriptsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListScriptsResponse) ProtoMessage() {}
+
+func (x *ListScriptsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_rpc_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListScriptsResponse.ProtoReflect.Descriptor instead.
+func (*ListScriptsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_rpc_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *ListScriptsResponse) GetScripts() []*ScriptInfo {
+	if x != nil {
+		return x.Scripts
+	}
+	return nil
+}
+
+type UploadHeader struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Kind          FileKind               `protobuf:"varint,1,opt,name=kind,proto3,enum=FileKind" json:"kind,omitempty"`
+	Filename      string                 `protobuf:"bytes,2,opt,name=filename,proto3" json:"filename,omitempty"`
+	SessionId     string                 `protobuf:"bytes,3,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"` // Script sync session to stage the script in, if any
+	Offset        uint64                 `protobuf:"varint,4,opt,name=offset,proto3" json:"offset,omitempty"`                       // Resume a previous upload from this byte offset
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UploadHeader) Reset() {
+	*x = UploadHeader{}
+	mi := &file_proto_rpc_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UploadHeader) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UploadHeader) ProtoMessage() {}
+
+func (x *UploadHeader) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_rpc_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UploadHeader.ProtoReflect.Descriptor instead.
+func (*UploadHeader) Descriptor() ([]byte, []int) {
+	return file_proto_rpc_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *UploadHeader) GetKind() FileKind {
+	if x != nil {
+		return x.Kind
+	}
+	return FileKind_SCRIPT
+}
+
+func (x *UploadHeader) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+func (x *UploadHeader) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *UploadHeader) GetOffset() uint64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+type UploadTrailer struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Sha256        string                 `protobuf:"bytes,1,opt,name=sha256,proto3" json:"sha256,omitempty"`           // Checksum of the complete file
+	Signature     []byte                 `protobuf:"bytes,2,opt,name=signature,proto3" json:"signature,omitempty"`     // Detached signature, required for scripts
+	Certificate   []byte                 `protobuf:"bytes,3,opt,name=certificate,proto3" json:"certificate,omitempty"` // PEM encoded signer certificate
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UploadTrailer) Reset() {
+	*x = UploadTrailer{}
+	mi := &file_proto_rpc_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UploadTrailer) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UploadTrailer) ProtoMessage() {}
+
+func (x *UploadTrailer) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_rpc_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UploadTrailer.ProtoReflect.Descriptor instead.
+func (*UploadTrailer) Descriptor() ([]byte, []int) {
+	return file_proto_rpc_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *UploadTrailer) GetSha256() string {
+	if x != nil {
+		return x.Sha256
+	}
+	return ""
+}
+
+func (x *UploadTrailer) GetSignature() []byte {
+	if x != nil {
+		return x.Signature
+	}
+	return nil
+}
+
+func (x *UploadTrailer) GetCertificate() []byte {
+	if x != nil {
+		return x.Certificate
+	}
+	return nil
+}
+
+// An upload is a header, any number of data chunks and a trailer.
+type UploadFileMessage struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Payload:
+	//
+	//	*UploadFileMessage_Header
+	//	*UploadFileMessage_Chunk
+	//	*UploadFileMessage_Trailer
+	Payload       isUploadFileMessage_Payload `protobuf_oneof:"payload"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UploadFileMessage) Reset() {
+	*x = UploadFileMessage{}
+	mi := &file_proto_rpc_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UploadFileMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UploadFileMessage) ProtoMessage() {}
+
+func (x *UploadFileMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_rpc_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UploadFileMessage.ProtoReflect.Descriptor instead.
+func (*UploadFileMessage) Descriptor() ([]byte, []int) {
+	return file_proto_rpc_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *UploadFileMessage) GetPayload() isUploadFileMessage_Payload {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *UploadFileMessage) GetHeader() *UploadHeader {
+	if x != nil {
+		if x, ok := x.Payload.(*UploadFileMessage_Header); ok {
+			return x.Header
+		}
+	}
+	return nil
+}
+
+func (x *UploadFileMessage) GetChunk() []byte {
+	if x != nil {
+		if x, ok := x.Payload.(*UploadFileMessage_Chunk); ok {
+			return x.Chunk
+		}
+	}
+	return nil
+}
+
+func (x *UploadFileMessage) GetTrailer() *UploadTrailer {
+	if x != nil {
+		if x, ok := x.Payload.(*UploadFileMessage_Trailer); ok {
+			return x.Trailer
+		}
+	}
+	return nil
+}
+
+type isUploadFileMessage_Payload interface {
+	isUploadFileMessage_Payload()
+}
+
+type UploadFileMessage_Header struct {
+	Header *UploadHeader `protobuf:"bytes,1,opt,name=header,proto3,oneof"`
+}
+
+type UploadFileMessage_Chunk struct {
+	Chunk []byte `protobuf:"bytes,2,opt,name=chunk,proto3,oneof"`
+}
+
+type UploadFileMessage_Trailer struct {
+	Trailer *UploadTrailer `protobuf:"bytes,3,opt,name=trailer,proto3,oneof"`
+}
+
+func (*UploadFileMessage_Header) isUploadFileMessage_Payload() {}
+
+func (*UploadFileMessage_Chunk) isUploadFileMessage_Payload() {}
+
+func (*UploadFileMessage_Trailer) isUploadFileMessage_Payload() {}
+
+type UploadFileResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Received      uint64                 `protobuf:"varint,3,opt,name=received,proto3" json:"received,omitempty"` // Bytes received so far; resume from here after a failure
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UploadFileResponse) Reset() {
+	*x = UploadFileResponse{}
+	mi := &file_proto_rpc_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UploadFileResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UploadFileResponse) ProtoMessage() {}
+
+func (x *UploadFileResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_rpc_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UploadFileResponse.ProtoReflect.Descriptor instead.
+func (*UploadFileResponse) Descriptor() ([]byte, []int) {
+	return file_proto_rpc_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *UploadFileResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *UploadFileResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *UploadFileResponse) GetReceived() uint64 {
+	if x != nil {
+		return x.Received
+	}
+	return 0
+}
+
+type UploadOffsetRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Kind          FileKind               `protobuf:"varint,1,opt,name=kind,proto3,enum=FileKind" json:"kind,omitempty"`
+	Filename      string                 `protobuf:"bytes,2,opt,name=filename,proto3" json:"filename,omitempty"`
+	SessionId     string                 `protobuf:"bytes,3,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UploadOffsetRequest) Reset() {
+	*x = UploadOffsetRequest{}
+	mi := &file_proto_rpc_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UploadOffsetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UploadOffsetRequest) ProtoMessage() {}
+
+func (x *UploadOffsetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_rpc_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UploadOffsetRequest.ProtoReflect.Descriptor instead.
+func (*UploadOffsetRequest) Descriptor() ([]byte, []int) {
+	return file_proto_rpc_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *UploadOffsetRequest) GetKind() FileKind {
+	if x != nil {
+		return x.Kind
+	}
+	return FileKind_SCRIPT
+}
+
+func (x *UploadOffsetRequest) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+func (x *UploadOffsetRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+type UploadOffsetResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Offset        uint64                 `protobuf:"varint,1,opt,name=offset,proto3" json:"offset,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UploadOffsetResponse) Reset() {
+	*x = UploadOffsetResponse{}
+	mi := &file_proto_rpc_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UploadOffsetResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UploadOffsetResponse) ProtoMessage() {}
+
+func (x *UploadOffsetResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_rpc_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UploadOffsetResponse.ProtoReflect.Descriptor instead.
+func (*UploadOffsetResponse) Descriptor() ([]byte, []int) {
+	return file_proto_rpc_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *UploadOffsetResponse) GetOffset() uint64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+type DesiredStateRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DesiredStateRequest) Reset() {
+	*x = DesiredStateRequest{}
+	mi := &file_proto_rpc_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DesiredStateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DesiredStateRequest) ProtoMessage() {}
+
+func (x *DesiredStateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_rpc_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DesiredStateRequest.ProtoReflect.Descriptor instead.
+func (*DesiredStateRequest) Descriptor() ([]byte, []int) {
+	return file_proto_rpc_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *DesiredStateRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+// Scripts and config files the manager wants the agent to have
+type DesiredState struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Scripts       *ScriptManifest        `protobuf:"bytes,1,opt,name=scripts,proto3" json:"scripts,omitempty"` // Complete script set; scripts are left unchanged if absent
+	Configs       []*Checksum            `protobuf:"bytes,2,rep,name=configs,proto3" json:"configs,omitempty"` // Config files to update; others are left unchanged
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DesiredState) Reset() {
+	*x = DesiredState{}
+	mi := &file_proto_rpc_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DesiredState) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DesiredState) ProtoMessage() {}
+
+func (x *DesiredState) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_rpc_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DesiredState.ProtoReflect.Descriptor instead.
+func (*DesiredState) Descriptor() ([]byte, []int) {
+	return file_proto_rpc_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *DesiredState) GetScripts() *ScriptManifest {
+	if x != nil {
+		return x.Scripts
+	}
+	return nil
+}
+
+func (x *DesiredState) GetConfigs() []*Checksum {
+	if x != nil {
+		return x.Configs
+	}
+	return nil
+}
+
+type DownloadFileRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Kind          FileKind               `protobuf:"varint,2,opt,name=kind,proto3,enum=FileKind" json:"kind,omitempty"`
+	Filename      string                 `protobuf:"bytes,3,opt,name=filename,proto3" json:"filename,omitempty"`
+	Offset        uint64                 `protobuf:"varint,4,opt,name=offset,proto3" json:"offset,omitempty"` // Resume a previous download from this byte offset
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DownloadFileRequest) Reset() {
+	*x = DownloadFileRequest{}
+	mi := &file_proto_rpc_proto_msgTypes[34]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DeleteScriptRequest) String() string {
+func (x *DownloadFileRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DeleteScriptRequest) ProtoMessage() {}
+func (*DownloadFileRequest) ProtoMessage() {}
 
-func (x *DeleteScriptRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_rpc_proto_msgTypes[10]
+func (x *DownloadFileRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_rpc_proto_msgTypes[34]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -666,18 +2383,39 @@ func (x *DeleteScriptRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DeleteScriptRequest.ProtoReflect.Descriptor instead.
-func (*DeleteScriptRequest) Descriptor() ([]byte, []int) {
-	return file_proto_rpc_proto_rawDescGZIP(), []int{10}
+// Deprecated: Use DownloadFileRequest.ProtoReflect.Descriptor instead.
+func (*DownloadFileRequest) Descriptor() ([]byte, []int) {
+	return file_proto_rpc_proto_rawDescGZIP(), []int{34}
 }
 
-func (x *DeleteScriptRequest) GetFilename() string {
+func (x *DownloadFileRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *DownloadFileRequest) GetKind() FileKind {
+	if x != nil {
+		return x.Kind
+	}
+	return FileKind_SCRIPT
+}
+
+func (x *DownloadFileRequest) GetFilename() string {
 	if x != nil {
 		return x.Filename
 	}
 	return ""
 }
 
+func (x *DownloadFileRequest) GetOffset() uint64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
 type HeartbeatRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
@@ -688,7 +2426,7 @@ type HeartbeatRequest struct {
 
 func (x *HeartbeatRequest) Reset() {
 	*x = HeartbeatRequest{}
-	mi := &file_proto_rpc_proto_msgTypes[11]
+	mi := &file_proto_rpc_proto_msgTypes[35]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -700,7 +2438,7 @@ func (x *HeartbeatRequest) String() string {
 func (*HeartbeatRequest) ProtoMessage() {}
 
 func (x *HeartbeatRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_rpc_proto_msgTypes[11]
+	mi := &file_proto_rpc_proto_msgTypes[35]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -713,7 +2451,7 @@ func (x *HeartbeatRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use HeartbeatRequest.ProtoReflect.Descriptor instead.
 func (*HeartbeatRequest) Descriptor() ([]byte, []int) {
-	return file_proto_rpc_proto_rawDescGZIP(), []int{11}
+	return file_proto_rpc_proto_rawDescGZIP(), []int{35}
 }
 
 func (x *HeartbeatRequest) GetAgentId() string {
@@ -739,7 +2477,7 @@ type HeartbeatResponse struct {
 
 func (x *HeartbeatResponse) Reset() {
 	*x = HeartbeatResponse{}
-	mi := &file_proto_rpc_proto_msgTypes[12]
+	mi := &file_proto_rpc_proto_msgTypes[36]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -751,7 +2489,7 @@ func (x *HeartbeatResponse) String() string {
 func (*HeartbeatResponse) ProtoMessage() {}
 
 func (x *HeartbeatResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_rpc_proto_msgTypes[12]
+	mi := &file_proto_rpc_proto_msgTypes[36]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -764,7 +2502,7 @@ func (x *HeartbeatResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use HeartbeatResponse.ProtoReflect.Descriptor instead.
 func (*HeartbeatResponse) Descriptor() ([]byte, []int) {
-	return file_proto_rpc_proto_rawDescGZIP(), []int{12}
+	return file_proto_rpc_proto_rawDescGZIP(), []int{36}
 }
 
 func (x *HeartbeatResponse) GetOk() bool {
@@ -783,7 +2521,7 @@ type RegisterAgentRequest struct {
 
 func (x *RegisterAgentRequest) Reset() {
 	*x = RegisterAgentRequest{}
-	mi := &file_proto_rpc_proto_msgTypes[13]
+	mi := &file_proto_rpc_proto_msgTypes[37]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -795,7 +2533,7 @@ func (x *RegisterAgentRequest) String() string {
 func (*RegisterAgentRequest) ProtoMessage() {}
 
 func (x *RegisterAgentRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_rpc_proto_msgTypes[13]
+	mi := &file_proto_rpc_proto_msgTypes[37]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -808,7 +2546,7 @@ func (x *RegisterAgentRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RegisterAgentRequest.ProtoReflect.Descriptor instead.
 func (*RegisterAgentRequest) Descriptor() ([]byte, []int) {
-	return file_proto_rpc_proto_rawDescGZIP(), []int{13}
+	return file_proto_rpc_proto_rawDescGZIP(), []int{37}
 }
 
 func (x *RegisterAgentRequest) GetDeviceId() string {
@@ -828,7 +2566,7 @@ type RegisterAgentResponse struct {
 
 func (x *RegisterAgentResponse) Reset() {
 	*x = RegisterAgentResponse{}
-	mi := &file_proto_rpc_proto_msgTypes[14]
+	mi := &file_proto_rpc_proto_msgTypes[38]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -840,7 +2578,7 @@ func (x *RegisterAgentResponse) String() string {
 func (*RegisterAgentResponse) ProtoMessage() {}
 
 func (x *RegisterAgentResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_rpc_proto_msgTypes[14]
+	mi := &file_proto_rpc_proto_msgTypes[38]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -853,7 +2591,7 @@ func (x *RegisterAgentResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RegisterAgentResponse.ProtoReflect.Descriptor instead.
 func (*RegisterAgentResponse) Descriptor() ([]byte, []int) {
-	return file_proto_rpc_proto_rawDescGZIP(), []int{14}
+	return file_proto_rpc_proto_rawDescGZIP(), []int{38}
 }
 
 func (x *RegisterAgentResponse) GetId() string {
@@ -879,7 +2617,7 @@ type UnregisterAgentRequest struct {
 
 func (x *UnregisterAgentRequest) Reset() {
 	*x = UnregisterAgentRequest{}
-	mi := &file_proto_rpc_proto_msgTypes[15]
+	mi := &file_proto_rpc_proto_msgTypes[39]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -891,7 +2629,7 @@ func (x *UnregisterAgentRequest) String() string {
 func (*UnregisterAgentRequest) ProtoMessage() {}
 
 func (x *UnregisterAgentRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_rpc_proto_msgTypes[15]
+	mi := &file_proto_rpc_proto_msgTypes[39]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -904,7 +2642,7 @@ func (x *UnregisterAgentRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UnregisterAgentRequest.ProtoReflect.Descriptor instead.
 func (*UnregisterAgentRequest) Descriptor() ([]byte, []int) {
-	return file_proto_rpc_proto_rawDescGZIP(), []int{15}
+	return file_proto_rpc_proto_rawDescGZIP(), []int{39}
 }
 
 func (x *UnregisterAgentRequest) GetId() string {
@@ -925,7 +2663,7 @@ type Tool struct {
 
 func (x *Tool) Reset() {
 	*x = Tool{}
-	mi := &file_proto_rpc_proto_msgTypes[16]
+	mi := &file_proto_rpc_proto_msgTypes[40]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -937,7 +2675,7 @@ func (x *Tool) String() string {
 func (*Tool) ProtoMessage() {}
 
 func (x *Tool) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_rpc_proto_msgTypes[16]
+	mi := &file_proto_rpc_proto_msgTypes[40]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -950,7 +2688,7 @@ func (x *Tool) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Tool.ProtoReflect.Descriptor instead.
 func (*Tool) Descriptor() ([]byte, []int) {
-	return file_proto_rpc_proto_rawDescGZIP(), []int{16}
+	return file_proto_rpc_proto_rawDescGZIP(), []int{40}
 }
 
 func (x *Tool) GetName() string {
@@ -977,14 +2715,15 @@ func (x *Tool) GetOs() []string {
 type ToolAction struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
-	Options       []string               `protobuf:"bytes,2,rep,name=options,proto3" json:"options,omitempty"`
+	Options       []string               `protobuf:"bytes,2,rep,name=options,proto3" json:"options,omitempty"` // Values accepted in ExecuteToolRequest.options
+	Schema        []*ToolOption          `protobuf:"bytes,3,rep,name=schema,proto3" json:"schema,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *ToolAction) Reset() {
 	*x = ToolAction{}
-	mi := &file_proto_rpc_proto_msgTypes[17]
+	mi := &file_proto_rpc_proto_msgTypes[41]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -996,7 +2735,7 @@ func (x *ToolAction) String() string {
 func (*ToolAction) ProtoMessage() {}
 
 func (x *ToolAction) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_rpc_proto_msgTypes[17]
+	mi := &file_proto_rpc_proto_msgTypes[41]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1009,7 +2748,7 @@ func (x *ToolAction) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ToolAction.ProtoReflect.Descriptor instead.
 func (*ToolAction) Descriptor() ([]byte, []int) {
-	return file_proto_rpc_proto_rawDescGZIP(), []int{17}
+	return file_proto_rpc_proto_rawDescGZIP(), []int{41}
 }
 
 func (x *ToolAction) GetName() string {
@@ -1026,6 +2765,97 @@ func (x *ToolAction) GetOptions() []string {
 	return nil
 }
 
+func (x *ToolAction) GetSchema() []*ToolOption {
+	if x != nil {
+		return x.Schema
+	}
+	return nil
+}
+
+type ToolOption struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Type          string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"` // string, int, bool, path (absolute) or list (comma separated)
+	Description   string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	Enum          []string               `protobuf:"bytes,4,rep,name=enum,proto3" json:"enum,omitempty"` // Allowed values of a string or list option
+	Default       string                 `protobuf:"bytes,5,opt,name=default,proto3" json:"default,omitempty"`
+	Required      bool                   `protobuf:"varint,6,opt,name=required,proto3" json:"required,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ToolOption) Reset() {
+	*x = ToolOption{}
+	mi := &file_proto_rpc_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ToolOption) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ToolOption) ProtoMessage() {}
+
+func (x *ToolOption) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_rpc_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ToolOption.ProtoReflect.Descriptor instead.
+func (*ToolOption) Descriptor() ([]byte, []int) {
+	return file_proto_rpc_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *ToolOption) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ToolOption) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *ToolOption) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *ToolOption) GetEnum() []string {
+	if x != nil {
+		return x.Enum
+	}
+	return nil
+}
+
+func (x *ToolOption) GetDefault() string {
+	if x != nil {
+		return x.Default
+	}
+	return ""
+}
+
+func (x *ToolOption) GetRequired() bool {
+	if x != nil {
+		return x.Required
+	}
+	return false
+}
+
 type GetToolsResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Tools         []*Tool                `protobuf:"bytes,1,rep,name=tools,proto3" json:"tools,omitempty"`
@@ -1035,7 +2865,7 @@ type GetToolsResponse struct {
 
 func (x *GetToolsResponse) Reset() {
 	*x = GetToolsResponse{}
-	mi := &file_proto_rpc_proto_msgTypes[18]
+	mi := &file_proto_rpc_proto_msgTypes[43]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1047,7 +2877,7 @@ func (x *GetToolsResponse) String() string {
 func (*GetToolsResponse) ProtoMessage() {}
 
 func (x *GetToolsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_rpc_proto_msgTypes[18]
+	mi := &file_proto_rpc_proto_msgTypes[43]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1060,7 +2890,7 @@ func (x *GetToolsResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetToolsResponse.ProtoReflect.Descriptor instead.
 func (*GetToolsResponse) Descriptor() ([]byte, []int) {
-	return file_proto_rpc_proto_rawDescGZIP(), []int{18}
+	return file_proto_rpc_proto_rawDescGZIP(), []int{43}
 }
 
 func (x *GetToolsResponse) GetTools() []*Tool {
@@ -1074,14 +2904,15 @@ type ExecuteToolRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
 	Action        string                 `protobuf:"bytes,2,opt,name=action,proto3" json:"action,omitempty"`
-	Options       []string               `protobuf:"bytes,3,rep,name=options,proto3" json:"options,omitempty"`
+	Options       []string               `protobuf:"bytes,3,rep,name=options,proto3" json:"options,omitempty"`                                                                         // Items of the action's list option, as sent by older managers
+	Values        map[string]string      `protobuf:"bytes,4,rep,name=values,proto3" json:"values,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // Options by name, checked against the action's schema
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *ExecuteToolRequest) Reset() {
 	*x = ExecuteToolRequest{}
-	mi := &file_proto_rpc_proto_msgTypes[19]
+	mi := &file_proto_rpc_proto_msgTypes[44]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1093,7 +2924,7 @@ func (x *ExecuteToolRequest) String() string {
 func (*ExecuteToolRequest) ProtoMessage() {}
 
 func (x *ExecuteToolRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_rpc_proto_msgTypes[19]
+	mi := &file_proto_rpc_proto_msgTypes[44]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1106,7 +2937,7 @@ func (x *ExecuteToolRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ExecuteToolRequest.ProtoReflect.Descriptor instead.
 func (*ExecuteToolRequest) Descriptor() ([]byte, []int) {
-	return file_proto_rpc_proto_rawDescGZIP(), []int{19}
+	return file_proto_rpc_proto_rawDescGZIP(), []int{44}
 }
 
 func (x *ExecuteToolRequest) GetName() string {
@@ -1130,19 +2961,27 @@ func (x *ExecuteToolRequest) GetOptions() []string {
 	return nil
 }
 
+func (x *ExecuteToolRequest) GetValues() map[string]string {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
+
 type ExecuteToolResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
 	Action        string                 `protobuf:"bytes,2,opt,name=action,proto3" json:"action,omitempty"`
 	Accepted      bool                   `protobuf:"varint,3,opt,name=accepted,proto3" json:"accepted,omitempty"`
 	Message       string                 `protobuf:"bytes,4,opt,name=message,proto3" json:"message,omitempty"`
+	ExecutionId   string                 `protobuf:"bytes,5,opt,name=execution_id,json=executionId,proto3" json:"execution_id,omitempty"` // Identifies the execution in ReportToolExecutionStatus
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *ExecuteToolResponse) Reset() {
 	*x = ExecuteToolResponse{}
-	mi := &file_proto_rpc_proto_msgTypes[20]
+	mi := &file_proto_rpc_proto_msgTypes[45]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1154,7 +2993,7 @@ func (x *ExecuteToolResponse) String() string {
 func (*ExecuteToolResponse) ProtoMessage() {}
 
 func (x *ExecuteToolResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_rpc_proto_msgTypes[20]
+	mi := &file_proto_rpc_proto_msgTypes[45]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1167,7 +3006,7 @@ func (x *ExecuteToolResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ExecuteToolResponse.ProtoReflect.Descriptor instead.
 func (*ExecuteToolResponse) Descriptor() ([]byte, []int) {
-	return file_proto_rpc_proto_rawDescGZIP(), []int{20}
+	return file_proto_rpc_proto_rawDescGZIP(), []int{45}
 }
 
 func (x *ExecuteToolResponse) GetName() string {
@@ -1198,17 +3037,25 @@ func (x *ExecuteToolResponse) GetMessage() string {
 	return ""
 }
 
+func (x *ExecuteToolResponse) GetExecutionId() string {
+	if x != nil {
+		return x.ExecutionId
+	}
+	return ""
+}
+
 type ToolExecutionStatusRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
 	Action        string                 `protobuf:"bytes,2,opt,name=action,proto3" json:"action,omitempty"`
+	ExecutionId   string                 `protobuf:"bytes,3,opt,name=execution_id,json=executionId,proto3" json:"execution_id,omitempty"` // If empty, the most recent execution of the tool's action
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *ToolExecutionStatusRequest) Reset() {
 	*x = ToolExecutionStatusRequest{}
-	mi := &file_proto_rpc_proto_msgTypes[21]
+	mi := &file_proto_rpc_proto_msgTypes[46]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1220,7 +3067,7 @@ func (x *ToolExecutionStatusRequest) String() string {
 func (*ToolExecutionStatusRequest) ProtoMessage() {}
 
 func (x *ToolExecutionStatusRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_rpc_proto_msgTypes[21]
+	mi := &file_proto_rpc_proto_msgTypes[46]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1233,7 +3080,7 @@ func (x *ToolExecutionStatusRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ToolExecutionStatusRequest.ProtoReflect.Descriptor instead.
 func (*ToolExecutionStatusRequest) Descriptor() ([]byte, []int) {
-	return file_proto_rpc_proto_rawDescGZIP(), []int{21}
+	return file_proto_rpc_proto_rawDescGZIP(), []int{46}
 }
 
 func (x *ToolExecutionStatusRequest) GetName() string {
@@ -1250,19 +3097,31 @@ func (x *ToolExecutionStatusRequest) GetAction() string {
 	return ""
 }
 
+func (x *ToolExecutionStatusRequest) GetExecutionId() string {
+	if x != nil {
+		return x.ExecutionId
+	}
+	return ""
+}
+
 type ToolExecutionStatusResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
 	Action        string                 `protobuf:"bytes,2,opt,name=action,proto3" json:"action,omitempty"`
 	Status        TaskStatus             `protobuf:"varint,3,opt,name=status,proto3,enum=TaskStatus" json:"status,omitempty"`
 	Result        string                 `protobuf:"bytes,4,opt,name=result,proto3" json:"result,omitempty"`
+	Execution     *ExecutionResult       `protobuf:"bytes,5,opt,name=execution,proto3" json:"execution,omitempty"`
+	ExecutionId   string                 `protobuf:"bytes,6,opt,name=execution_id,json=executionId,proto3" json:"execution_id,omitempty"`
+	Error         string                 `protobuf:"bytes,7,opt,name=error,proto3" json:"error,omitempty"`
+	StartedAt     *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	FinishedAt    *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=finished_at,json=finishedAt,proto3" json:"finished_at,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *ToolExecutionStatusResponse) Reset() {
 	*x = ToolExecutionStatusResponse{}
-	mi := &file_proto_rpc_proto_msgTypes[22]
+	mi := &file_proto_rpc_proto_msgTypes[47]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1274,7 +3133,7 @@ func (x *ToolExecutionStatusResponse) String() string {
 func (*ToolExecutionStatusResponse) ProtoMessage() {}
 
 func (x *ToolExecutionStatusResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_rpc_proto_msgTypes[22]
+	mi := &file_proto_rpc_proto_msgTypes[47]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1287,7 +3146,7 @@ func (x *ToolExecutionStatusResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ToolExecutionStatusResponse.ProtoReflect.Descriptor instead.
 func (*ToolExecutionStatusResponse) Descriptor() ([]byte, []int) {
-	return file_proto_rpc_proto_rawDescGZIP(), []int{22}
+	return file_proto_rpc_proto_rawDescGZIP(), []int{47}
 }
 
 func (x *ToolExecutionStatusResponse) GetName() string {
@@ -1318,17 +3177,160 @@ func (x *ToolExecutionStatusResponse) GetResult() string {
 	return ""
 }
 
+func (x *ToolExecutionStatusResponse) GetExecution() *ExecutionResult {
+	if x != nil {
+		return x.Execution
+	}
+	return nil
+}
+
+func (x *ToolExecutionStatusResponse) GetExecutionId() string {
+	if x != nil {
+		return x.ExecutionId
+	}
+	return ""
+}
+
+func (x *ToolExecutionStatusResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *ToolExecutionStatusResponse) GetStartedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartedAt
+	}
+	return nil
+}
+
+func (x *ToolExecutionStatusResponse) GetFinishedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.FinishedAt
+	}
+	return nil
+}
+
+type ToolExecutionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`     // Only executions of this tool, if set
+	Action        string                 `protobuf:"bytes,2,opt,name=action,proto3" json:"action,omitempty"` // Only executions of this action, if set
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ToolExecutionsRequest) Reset() {
+	*x = ToolExecutionsRequest{}
+	mi := &file_proto_rpc_proto_msgTypes[48]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ToolExecutionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ToolExecutionsRequest) ProtoMessage() {}
+
+func (x *ToolExecutionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_rpc_proto_msgTypes[48]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ToolExecutionsRequest.ProtoReflect.Descriptor instead.
+func (*ToolExecutionsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_rpc_proto_rawDescGZIP(), []int{48}
+}
+
+func (x *ToolExecutionsRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ToolExecutionsRequest) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+// Executions of the last 24 hours, oldest first
+type ToolExecutionsResponse struct {
+	state         protoimpl.MessageState         `protogen:"open.v1"`
+	Executions    []*ToolExecutionStatusResponse `protobuf:"bytes,1,rep,name=executions,proto3" json:"executions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ToolExecutionsResponse) Reset() {
+	*x = ToolExecutionsResponse{}
+	mi := &file_proto_rpc_proto_msgTypes[49]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ToolExecutionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ToolExecutionsResponse) ProtoMessage() {}
+
+func (x *ToolExecutionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_rpc_proto_msgTypes[49]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ToolExecutionsResponse.ProtoReflect.Descriptor instead.
+func (*ToolExecutionsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_rpc_proto_rawDescGZIP(), []int{49}
+}
+
+func (x *ToolExecutionsResponse) GetExecutions() []*ToolExecutionStatusResponse {
+	if x != nil {
+		return x.Executions
+	}
+	return nil
+}
+
 var File_proto_rpc_proto protoreflect.FileDescriptor
 
 const file_proto_rpc_proto_rawDesc = "" +
 	"\n" +
-	"\x0fproto/rpc.proto\x1a\x1bgoogle/protobuf/empty.proto\"w\n" +
+	"\x0fproto/rpc.proto\x1a\x1egoogle/protobuf/duration.proto\x1a\x1bgoogle/protobuf/empty.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\xea\x02\n" +
 	"\x03Job\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
 	"\x04name\x18\x02 \x01(\tR\x04name\x12 \n" +
 	"\vdescription\x18\x03 \x01(\tR\vdescription\x12\x12\n" +
 	"\x04type\x18\x04 \x01(\tR\x04type\x12\x16\n" +
-	"\x06target\x18\x05 \x01(\tR\x06target\"\x85\x01\n" +
+	"\x06target\x18\x05 \x01(\tR\x06target\x12\x12\n" +
+	"\x04args\x18\x06 \x03(\tR\x04args\x123\n" +
+	"\atimeout\x18\a \x01(\v2\x19.google.protobuf.DurationR\atimeout\x12\x1f\n" +
+	"\x03env\x18\b \x03(\v2\r.Job.EnvEntryR\x03env\x12\x1f\n" +
+	"\vworking_dir\x18\t \x01(\tR\n" +
+	"workingDir\x12\x14\n" +
+	"\x05stdin\x18\n" +
+	" \x01(\fR\x05stdin\x12\x18\n" +
+	"\aprofile\x18\v \x01(\tR\aprofile\x1a6\n" +
+	"\bEnvEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x85\x01\n" +
 	"\x04Task\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x15\n" +
 	"\x06job_id\x18\x02 \x01(\tR\x05jobId\x12\x19\n" +
@@ -1340,27 +3342,150 @@ const file_proto_rpc_proto_rawDesc = "" +
 	"\x03job\x18\x02 \x01(\v2\x04.JobR\x03job\"J\n" +
 	"\x12AssignTaskResponse\x12\x1a\n" +
 	"\baccepted\x18\x01 \x01(\bR\baccepted\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"\xba\x02\n" +
+	"\x0fExecutionResult\x12\x1b\n" +
+	"\texit_code\x18\x01 \x01(\x05R\bexitCode\x12\x16\n" +
+	"\x06stdout\x18\x02 \x01(\fR\x06stdout\x12\x16\n" +
+	"\x06stderr\x18\x03 \x01(\fR\x06stderr\x12\x16\n" +
+	"\x06signal\x18\x04 \x01(\tR\x06signal\x129\n" +
+	"\n" +
+	"started_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tstartedAt\x12;\n" +
+	"\vfinished_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"finishedAt\x12\x1c\n" +
+	"\ttruncated\x18\a \x01(\bR\ttruncated\x12,\n" +
+	"\tresources\x18\b \x01(\v2\x0e.ResourceUsageR\tresources\"\xa8\x01\n" +
+	"\rResourceUsage\x12*\n" +
+	"\x11peak_memory_bytes\x18\x01 \x01(\x04R\x0fpeakMemoryBytes\x124\n" +
+	"\bcpu_time\x18\x02 \x01(\v2\x19.google.protobuf.DurationR\acpuTime\x12\x1d\n" +
+	"\n" +
+	"oom_killed\x18\x03 \x01(\bR\toomKilled\x12\x16\n" +
+	"\x06source\x18\x04 \x01(\tR\x06source\",\n" +
+	"\x11CancelTaskRequest\x12\x17\n" +
+	"\atask_id\x18\x01 \x01(\tR\x06taskId\"L\n" +
+	"\x12CancelTaskResponse\x12\x1c\n" +
+	"\tcancelled\x18\x01 \x01(\bR\tcancelled\x12\x18\n" +
 	"\amessage\x18\x02 \x01(\tR\amessage\")\n" +
 	"\x10JobStatusRequest\x12\x15\n" +
-	"\x06job_id\x18\x01 \x01(\tR\x05jobId\"g\n" +
+	"\x06job_id\x18\x01 \x01(\tR\x05jobId\"\xbe\x02\n" +
 	"\x11JobStatusResponse\x12\x15\n" +
 	"\x06job_id\x18\x01 \x01(\tR\x05jobId\x12#\n" +
 	"\x06status\x18\x02 \x01(\x0e2\v.TaskStatusR\x06status\x12\x16\n" +
-	"\x06result\x18\x03 \x01(\tR\x06result\"B\n" +
+	"\x06result\x18\x03 \x01(\tR\x06result\x12\x17\n" +
+	"\atask_id\x18\x04 \x01(\tR\x06taskId\x12\x14\n" +
+	"\x05error\x18\x05 \x01(\tR\x05error\x129\n" +
+	"\n" +
+	"started_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tstartedAt\x12;\n" +
+	"\vfinished_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"finishedAt\x12.\n" +
+	"\texecution\x18\b \x01(\v2\x10.ExecutionResultR\texecution\"Q\n" +
+	"\x11TaskOutputRequest\x12\x17\n" +
+	"\atask_id\x18\x01 \x01(\tR\x06taskId\x12#\n" +
+	"\rfrom_sequence\x18\x02 \x01(\x04R\ffromSequence\"\xbc\x01\n" +
+	"\x0fTaskOutputChunk\x12\x1a\n" +
+	"\bsequence\x18\x01 \x01(\x04R\bsequence\x12%\n" +
+	"\x06stream\x18\x02 \x01(\x0e2\r.OutputStreamR\x06stream\x12\x12\n" +
+	"\x04data\x18\x03 \x01(\fR\x04data\x128\n" +
+	"\ttimestamp\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\x12\x18\n" +
+	"\adropped\x18\x05 \x01(\x04R\adropped\"v\n" +
+	"\x11TaskOutputMessage\x12(\n" +
+	"\x05chunk\x18\x01 \x01(\v2\x10.TaskOutputChunkH\x00R\x05chunk\x12,\n" +
+	"\x06status\x18\x02 \x01(\v2\x12.JobStatusResponseH\x00R\x06statusB\t\n" +
+	"\apayload\"\\\n" +
 	"\bChecksum\x12\x1a\n" +
 	"\bfilename\x18\x01 \x01(\tR\bfilename\x12\x1a\n" +
-	"\bchecksum\x18\x02 \x01(\tR\bchecksum\"3\n" +
+	"\bchecksum\x18\x02 \x01(\tR\bchecksum\x12\x18\n" +
+	"\aversion\x18\x03 \x01(\tR\aversion\"3\n" +
 	"\x10ChecksumResponse\x12\x1f\n" +
-	"\x05files\x18\x01 \x03(\v2\t.ChecksumR\x05files\"C\n" +
+	"\x05files\x18\x01 \x03(\v2\t.ChecksumR\x05files\"\x83\x01\n" +
 	"\vFileContent\x12\x1a\n" +
 	"\bfilename\x18\x01 \x01(\tR\bfilename\x12\x18\n" +
-	"\acontent\x18\x02 \x01(\fR\acontent\"@\n" +
+	"\acontent\x18\x02 \x01(\fR\acontent\x12\x1c\n" +
+	"\tsignature\x18\x03 \x01(\fR\tsignature\x12 \n" +
+	"\vcertificate\x18\x04 \x01(\fR\vcertificate\"@\n" +
 	"\n" +
 	"SyncStatus\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
 	"\amessage\x18\x02 \x01(\tR\amessage\"1\n" +
 	"\x13DeleteScriptRequest\x12\x1a\n" +
-	"\bfilename\x18\x01 \x01(\tR\bfilename\"G\n" +
+	"\bfilename\x18\x01 \x01(\tR\bfilename\"o\n" +
+	"\x13ScriptManifestEntry\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x16\n" +
+	"\x06sha256\x18\x02 \x01(\tR\x06sha256\x12\x18\n" +
+	"\aversion\x18\x03 \x01(\tR\aversion\x12\x12\n" +
+	"\x04mode\x18\x04 \x01(\rR\x04mode\"@\n" +
+	"\x0eScriptManifest\x12.\n" +
+	"\ascripts\x18\x01 \x03(\v2\x14.ScriptManifestEntryR\ascripts\"J\n" +
+	"\x11ScriptSyncSession\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x16\n" +
+	"\x06needed\x18\x02 \x03(\tR\x06needed\"U\n" +
+	"\x12StageScriptRequest\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\x12 \n" +
+	"\x04file\x18\x02 \x01(\v2\f.FileContentR\x04file\"2\n" +
+	"\x11ScriptSyncRequest\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\"\x8f\x01\n" +
+	"\tScriptArg\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x02 \x01(\tR\vdescription\x12\x1a\n" +
+	"\brequired\x18\x03 \x01(\bR\brequired\x12\x16\n" +
+	"\x06values\x18\x04 \x03(\tR\x06values\x12\x18\n" +
+	"\apattern\x18\x05 \x01(\tR\apattern\"\xc2\x02\n" +
+	"\n" +
+	"ScriptInfo\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x18\n" +
+	"\apackage\x18\x02 \x01(\bR\apackage\x12 \n" +
+	"\vdescription\x18\x03 \x01(\tR\vdescription\x12 \n" +
+	"\vinterpreter\x18\x04 \x01(\tR\vinterpreter\x12\x0e\n" +
+	"\x02os\x18\x05 \x03(\tR\x02os\x12\x1e\n" +
+	"\x04args\x18\x06 \x03(\v2\n" +
+	".ScriptArgR\x04args\x12'\n" +
+	"\x0fargs_restricted\x18\a \x01(\bR\x0eargsRestricted\x123\n" +
+	"\atimeout\x18\b \x01(\v2\x19.google.protobuf.DurationR\atimeout\x12\x1e\n" +
+	"\n" +
+	"privileges\x18\t \x01(\tR\n" +
+	"privileges\x12\x14\n" +
+	"\x05error\x18\n" +
+	" \x01(\tR\x05error\"<\n" +
+	"\x13ListScriptsResponse\x12%\n" +
+	"\ascripts\x18\x01 \x03(\v2\v.ScriptInfoR\ascripts\"\x80\x01\n" +
+	"\fUploadHeader\x12\x1d\n" +
+	"\x04kind\x18\x01 \x01(\x0e2\t.FileKindR\x04kind\x12\x1a\n" +
+	"\bfilename\x18\x02 \x01(\tR\bfilename\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x03 \x01(\tR\tsessionId\x12\x16\n" +
+	"\x06offset\x18\x04 \x01(\x04R\x06offset\"g\n" +
+	"\rUploadTrailer\x12\x16\n" +
+	"\x06sha256\x18\x01 \x01(\tR\x06sha256\x12\x1c\n" +
+	"\tsignature\x18\x02 \x01(\fR\tsignature\x12 \n" +
+	"\vcertificate\x18\x03 \x01(\fR\vcertificate\"\x8b\x01\n" +
+	"\x11UploadFileMessage\x12'\n" +
+	"\x06header\x18\x01 \x01(\v2\r.UploadHeaderH\x00R\x06header\x12\x16\n" +
+	"\x05chunk\x18\x02 \x01(\fH\x00R\x05chunk\x12*\n" +
+	"\atrailer\x18\x03 \x01(\v2\x0e.UploadTrailerH\x00R\atrailerB\t\n" +
+	"\apayload\"d\n" +
+	"\x12UploadFileResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12\x1a\n" +
+	"\breceived\x18\x03 \x01(\x04R\breceived\"o\n" +
+	"\x13UploadOffsetRequest\x12\x1d\n" +
+	"\x04kind\x18\x01 \x01(\x0e2\t.FileKindR\x04kind\x12\x1a\n" +
+	"\bfilename\x18\x02 \x01(\tR\bfilename\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x03 \x01(\tR\tsessionId\".\n" +
+	"\x14UploadOffsetResponse\x12\x16\n" +
+	"\x06offset\x18\x01 \x01(\x04R\x06offset\"0\n" +
+	"\x13DesiredStateRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\"^\n" +
+	"\fDesiredState\x12)\n" +
+	"\ascripts\x18\x01 \x01(\v2\x0f.ScriptManifestR\ascripts\x12#\n" +
+	"\aconfigs\x18\x02 \x03(\v2\t.ChecksumR\aconfigs\"\x83\x01\n" +
+	"\x13DownloadFileRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x1d\n" +
+	"\x04kind\x18\x02 \x01(\x0e2\t.FileKindR\x04kind\x12\x1a\n" +
+	"\bfilename\x18\x03 \x01(\tR\bfilename\x12\x16\n" +
+	"\x06offset\x18\x04 \x01(\x04R\x06offset\"G\n" +
 	"\x10HeartbeatRequest\x12\x19\n" +
 	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x18\n" +
 	"\amessage\x18\x02 \x01(\tR\amessage\"#\n" +
@@ -1376,55 +3501,110 @@ const file_proto_rpc_proto_rawDesc = "" +
 	"\x04Tool\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12%\n" +
 	"\aactions\x18\x02 \x03(\v2\v.ToolActionR\aactions\x12\x0e\n" +
-	"\x02os\x18\x03 \x03(\tR\x02os\":\n" +
+	"\x02os\x18\x03 \x03(\tR\x02os\"_\n" +
 	"\n" +
 	"ToolAction\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12\x18\n" +
-	"\aoptions\x18\x02 \x03(\tR\aoptions\"/\n" +
+	"\aoptions\x18\x02 \x03(\tR\aoptions\x12#\n" +
+	"\x06schema\x18\x03 \x03(\v2\v.ToolOptionR\x06schema\"\xa0\x01\n" +
+	"\n" +
+	"ToolOption\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12 \n" +
+	"\vdescription\x18\x03 \x01(\tR\vdescription\x12\x12\n" +
+	"\x04enum\x18\x04 \x03(\tR\x04enum\x12\x18\n" +
+	"\adefault\x18\x05 \x01(\tR\adefault\x12\x1a\n" +
+	"\brequired\x18\x06 \x01(\bR\brequired\"/\n" +
 	"\x10GetToolsResponse\x12\x1b\n" +
-	"\x05tools\x18\x01 \x03(\v2\x05.ToolR\x05tools\"Z\n" +
+	"\x05tools\x18\x01 \x03(\v2\x05.ToolR\x05tools\"\xce\x01\n" +
 	"\x12ExecuteToolRequest\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12\x16\n" +
 	"\x06action\x18\x02 \x01(\tR\x06action\x12\x18\n" +
-	"\aoptions\x18\x03 \x03(\tR\aoptions\"w\n" +
+	"\aoptions\x18\x03 \x03(\tR\aoptions\x127\n" +
+	"\x06values\x18\x04 \x03(\v2\x1f.ExecuteToolRequest.ValuesEntryR\x06values\x1a9\n" +
+	"\vValuesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x9a\x01\n" +
 	"\x13ExecuteToolResponse\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12\x16\n" +
 	"\x06action\x18\x02 \x01(\tR\x06action\x12\x1a\n" +
 	"\baccepted\x18\x03 \x01(\bR\baccepted\x12\x18\n" +
-	"\amessage\x18\x04 \x01(\tR\amessage\"H\n" +
+	"\amessage\x18\x04 \x01(\tR\amessage\x12!\n" +
+	"\fexecution_id\x18\x05 \x01(\tR\vexecutionId\"k\n" +
 	"\x1aToolExecutionStatusRequest\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12\x16\n" +
-	"\x06action\x18\x02 \x01(\tR\x06action\"\x86\x01\n" +
+	"\x06action\x18\x02 \x01(\tR\x06action\x12!\n" +
+	"\fexecution_id\x18\x03 \x01(\tR\vexecutionId\"\xe7\x02\n" +
 	"\x1bToolExecutionStatusResponse\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12\x16\n" +
 	"\x06action\x18\x02 \x01(\tR\x06action\x12#\n" +
 	"\x06status\x18\x03 \x01(\x0e2\v.TaskStatusR\x06status\x12\x16\n" +
-	"\x06result\x18\x04 \x01(\tR\x06result*A\n" +
+	"\x06result\x18\x04 \x01(\tR\x06result\x12.\n" +
+	"\texecution\x18\x05 \x01(\v2\x10.ExecutionResultR\texecution\x12!\n" +
+	"\fexecution_id\x18\x06 \x01(\tR\vexecutionId\x12\x14\n" +
+	"\x05error\x18\a \x01(\tR\x05error\x129\n" +
+	"\n" +
+	"started_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\tstartedAt\x12;\n" +
+	"\vfinished_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"finishedAt\"C\n" +
+	"\x15ToolExecutionsRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x16\n" +
+	"\x06action\x18\x02 \x01(\tR\x06action\"V\n" +
+	"\x16ToolExecutionsResponse\x12<\n" +
+	"\n" +
+	"executions\x18\x01 \x03(\v2\x1c.ToolExecutionStatusResponseR\n" +
+	"executions*P\n" +
 	"\n" +
 	"TaskStatus\x12\v\n" +
 	"\aPENDING\x10\x00\x12\v\n" +
 	"\aRUNNING\x10\x01\x12\r\n" +
 	"\tCOMPLETED\x10\x02\x12\n" +
 	"\n" +
-	"\x06FAILED\x10\x032\xe5\x05\n" +
+	"\x06FAILED\x10\x03\x12\r\n" +
+	"\tCANCELLED\x10\x04*&\n" +
+	"\fOutputStream\x12\n" +
+	"\n" +
+	"\x06STDOUT\x10\x00\x12\n" +
+	"\n" +
+	"\x06STDERR\x10\x01*\"\n" +
+	"\bFileKind\x12\n" +
+	"\n" +
+	"\x06SCRIPT\x10\x00\x12\n" +
+	"\n" +
+	"\x06CONFIG\x10\x012\xad\n" +
+	"\n" +
 	"\fAgentService\x125\n" +
 	"\n" +
 	"AssignTask\x12\x12.AssignTaskRequest\x1a\x13.AssignTaskResponse\x129\n" +
-	"\x10ReportTaskStatus\x12\x11.JobStatusRequest\x1a\x12.JobStatusResponse\x12?\n" +
+	"\x10ReportTaskStatus\x12\x11.JobStatusRequest\x1a\x12.JobStatusResponse\x125\n" +
+	"\n" +
+	"CancelTask\x12\x12.CancelTaskRequest\x1a\x13.CancelTaskResponse\x12<\n" +
+	"\x10StreamTaskOutput\x12\x12.TaskOutputRequest\x1a\x12.TaskOutputMessage0\x01\x12?\n" +
 	"\x12GetScriptChecksums\x12\x16.google.protobuf.Empty\x1a\x11.ChecksumResponse\x12+\n" +
 	"\x0eSendScriptFile\x12\f.FileContent\x1a\v.SyncStatus\x125\n" +
-	"\x10DeleteScriptFile\x12\x14.DeleteScriptRequest\x1a\v.SyncStatus\x12D\n" +
+	"\x10DeleteScriptFile\x12\x14.DeleteScriptRequest\x1a\v.SyncStatus\x126\n" +
+	"\x0fBeginScriptSync\x12\x0f.ScriptManifest\x1a\x12.ScriptSyncSession\x123\n" +
+	"\x0fStageScriptFile\x12\x13.StageScriptRequest\x1a\v.SyncStatus\x123\n" +
+	"\x10CommitScriptSync\x12\x12.ScriptSyncRequest\x1a\v.SyncStatus\x122\n" +
+	"\x0fAbortScriptSync\x12\x12.ScriptSyncRequest\x1a\v.SyncStatus\x12;\n" +
+	"\vListScripts\x12\x16.google.protobuf.Empty\x1a\x14.ListScriptsResponse\x127\n" +
+	"\n" +
+	"UploadFile\x12\x12.UploadFileMessage\x1a\x13.UploadFileResponse(\x01\x12>\n" +
+	"\x0fGetUploadOffset\x12\x14.UploadOffsetRequest\x1a\x15.UploadOffsetResponse\x12D\n" +
 	"\x12UnregisterAgentAsk\x12\x16.google.protobuf.Empty\x1a\x16.google.protobuf.Empty\x12A\n" +
 	"\x0fTryAgentAddress\x12\x16.google.protobuf.Empty\x1a\x16.google.protobuf.Empty\x12?\n" +
 	"\x12GetConfigChecksums\x12\x16.google.protobuf.Empty\x1a\x11.ChecksumResponse\x12+\n" +
 	"\x0eSendConfigFile\x12\f.FileContent\x1a\v.SyncStatus\x125\n" +
 	"\bGetTools\x12\x16.google.protobuf.Empty\x1a\x11.GetToolsResponse\x128\n" +
 	"\vExecuteTool\x12\x13.ExecuteToolRequest\x1a\x14.ExecuteToolResponse\x12V\n" +
-	"\x19ReportToolExecutionStatus\x12\x1b.ToolExecutionStatusRequest\x1a\x1c.ToolExecutionStatusResponse2\xc8\x01\n" +
+	"\x19ReportToolExecutionStatus\x12\x1b.ToolExecutionStatusRequest\x1a\x1c.ToolExecutionStatusResponse\x12E\n" +
+	"\x12ListToolExecutions\x12\x16.ToolExecutionsRequest\x1a\x17.ToolExecutionsResponse2\xbc\x02\n" +
 	"\x0eManagerService\x12>\n" +
 	"\rRegisterAgent\x12\x15.RegisterAgentRequest\x1a\x16.RegisterAgentResponse\x12B\n" +
 	"\x0fUnregisterAgent\x12\x17.UnregisterAgentRequest\x1a\x16.google.protobuf.Empty\x122\n" +
-	"\tHeartbeat\x12\x11.HeartbeatRequest\x1a\x12.HeartbeatResponseB\bZ\x06proto/b\x06proto3"
+	"\tHeartbeat\x12\x11.HeartbeatRequest\x1a\x12.HeartbeatResponse\x126\n" +
+	"\x0fGetDesiredState\x12\x14.DesiredStateRequest\x1a\r.DesiredState\x12:\n" +
+	"\fDownloadFile\x12\x14.DownloadFileRequest\x1a\x12.UploadFileMessage0\x01B\bZ\x06proto/b\x06proto3"
 
 var (
 	file_proto_rpc_proto_rawDescOnce sync.Once
@@ -1438,79 +3618,167 @@ func file_proto_rpc_proto_rawDescGZIP() []byte {
 	return file_proto_rpc_proto_rawDescData
 }
 
-var file_proto_rpc_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_proto_rpc_proto_msgTypes = make([]protoimpl.MessageInfo, 23)
+var file_proto_rpc_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
+var file_proto_rpc_proto_msgTypes = make([]protoimpl.MessageInfo, 52)
 var file_proto_rpc_proto_goTypes = []any{
 	(TaskStatus)(0),                     // 0: TaskStatus
-	(*Job)(nil),                         // 1: Job
-	(*Task)(nil),                        // 2: Task
-	(*AssignTaskRequest)(nil),           // 3: AssignTaskRequest
-	(*AssignTaskResponse)(nil),          // 4: AssignTaskResponse
-	(*JobStatusRequest)(nil),            // 5: JobStatusRequest
-	(*JobStatusResponse)(nil),           // 6: JobStatusResponse
-	(*Checksum)(nil),                    // 7: Checksum
-	(*ChecksumResponse)(nil),            // 8: ChecksumResponse
-	(*FileContent)(nil),                 // 9: FileContent
-	(*SyncStatus)(nil),                  // 10: SyncStatus
-	(*DeleteScriptRequest)(nil),         // 11: DeleteScriptRequest
-	(*HeartbeatRequest)(nil),            // 12: HeartbeatRequest
-	(*HeartbeatResponse)(nil),           // 13: HeartbeatResponse
-	(*RegisterAgentRequest)(nil),        // 14: RegisterAgentRequest
-	(*RegisterAgentResponse)(nil),       // 15: RegisterAgentResponse
-	(*UnregisterAgentRequest)(nil),      // 16: UnregisterAgentRequest
-	(*Tool)(nil),                        // 17: Tool
-	(*ToolAction)(nil),                  // 18: ToolAction
-	(*GetToolsResponse)(nil),            // 19: GetToolsResponse
-	(*ExecuteToolRequest)(nil),          // 20: ExecuteToolRequest
-	(*ExecuteToolResponse)(nil),         // 21: ExecuteToolResponse
-	(*ToolExecutionStatusRequest)(nil),  // 22: ToolExecutionStatusRequest
-	(*ToolExecutionStatusResponse)(nil), // 23: ToolExecutionStatusResponse
-	(*emptypb.Empty)(nil),               // 24: google.protobuf.Empty
+	(OutputStream)(0),                   // 1: OutputStream
+	(FileKind)(0),                       // 2: FileKind
+	(*Job)(nil),                         // 3: Job
+	(*Task)(nil),                        // 4: Task
+	(*AssignTaskRequest)(nil),           // 5: AssignTaskRequest
+	(*AssignTaskResponse)(nil),          // 6: AssignTaskResponse
+	(*ExecutionResult)(nil),             // 7: ExecutionResult
+	(*ResourceUsage)(nil),               // 8: ResourceUsage
+	(*CancelTaskRequest)(nil),           // 9: CancelTaskRequest
+	(*CancelTaskResponse)(nil),          // 10: CancelTaskResponse
+	(*JobStatusRequest)(nil),            // 11: JobStatusRequest
+	(*JobStatusResponse)(nil),           // 12: JobStatusResponse
+	(*TaskOutputRequest)(nil),           // 13: TaskOutputRequest
+	(*TaskOutputChunk)(nil),             // 14: TaskOutputChunk
+	(*TaskOutputMessage)(nil),           // 15: TaskOutputMessage
+	(*Checksum)(nil),                    // 16: Checksum
+	(*ChecksumResponse)(nil),            // 17: ChecksumResponse
+	(*FileContent)(nil),                 // 18: FileContent
+	(*SyncStatus)(nil),                  // 19: SyncStatus
+	(*DeleteScriptRequest)(nil),         // 20: DeleteScriptRequest
+	(*ScriptManifestEntry)(nil),         // 21: ScriptManifestEntry
+	(*ScriptManifest)(nil),              // 22: ScriptManifest
+	(*ScriptSyncSession)(nil),           // 23: ScriptSyncSession
+	(*StageScriptRequest)(nil),          // 24: StageScriptRequest
+	(*ScriptSyncRequest)(nil),           // 25: ScriptSyncRequest
+	(*ScriptArg)(nil),                   // 26: ScriptArg
+	(*ScriptInfo)(nil),                  // 27: ScriptInfo
+	(*ListScriptsResponse)(nil),         // 28: ListScriptsResponse
+	(*UploadHeader)(nil),                // 29: UploadHeader
+	(*UploadTrailer)(nil),               // 30: UploadTrailer
+	(*UploadFileMessage)(nil),           // 31: UploadFileMessage
+	(*UploadFileResponse)(nil),          // 32: UploadFileResponse
+	(*UploadOffsetRequest)(nil),         // 33: UploadOffsetRequest
+	(*UploadOffsetResponse)(nil),        // 34: UploadOffsetResponse
+	(*DesiredStateRequest)(nil),         // 35: DesiredStateRequest
+	(*DesiredState)(nil),                // 36: DesiredState
+	(*DownloadFileRequest)(nil),         // 37: DownloadFileRequest
+	(*HeartbeatRequest)(nil),            // 38: HeartbeatRequest
+	(*HeartbeatResponse)(nil),           // 39: HeartbeatResponse
+	(*RegisterAgentRequest)(nil),        // 40: RegisterAgentRequest
+	(*RegisterAgentResponse)(nil),       // 41: RegisterAgentResponse
+	(*UnregisterAgentRequest)(nil),      // 42: UnregisterAgentRequest
+	(*Tool)(nil),                        // 43: Tool
+	(*ToolAction)(nil),                  // 44: ToolAction
+	(*ToolOption)(nil),                  // 45: ToolOption
+	(*GetToolsResponse)(nil),            // 46: GetToolsResponse
+	(*ExecuteToolRequest)(nil),          // 47: ExecuteToolRequest
+	(*ExecuteToolResponse)(nil),         // 48: ExecuteToolResponse
+	(*ToolExecutionStatusRequest)(nil),  // 49: ToolExecutionStatusRequest
+	(*ToolExecutionStatusResponse)(nil), // 50: ToolExecutionStatusResponse
+	(*ToolExecutionsRequest)(nil),       // 51: ToolExecutionsRequest
+	(*ToolExecutionsResponse)(nil),      // 52: ToolExecutionsResponse
+	nil,                                 // 53: Job.EnvEntry
+	nil,                                 // 54: ExecuteToolRequest.ValuesEntry
+	(*durationpb.Duration)(nil),         // 55: google.protobuf.Duration
+	(*timestamppb.Timestamp)(nil),       // 56: google.protobuf.Timestamp
+	(*emptypb.Empty)(nil),               // 57: google.protobuf.Empty
 }
 var file_proto_rpc_proto_depIdxs = []int32{
-	0,  // 0: Task.status:type_name -> TaskStatus
-	2,  // 1: AssignTaskRequest.task:type_name -> Task
-	1,  // 2: AssignTaskRequest.job:type_name -> Job
-	0,  // 3: JobStatusResponse.status:type_name -> TaskStatus
-	7,  // 4: ChecksumResponse.files:type_name -> Checksum
-	18, // 5: Tool.actions:type_name -> ToolAction
-	17, // 6: GetToolsResponse.tools:type_name -> Tool
-	0,  // 7: ToolExecutionStatusResponse.status:type_name -> TaskStatus
-	3,  // 8: AgentService.AssignTask:input_type -> AssignTaskRequest
-	5,  // 9: AgentService.ReportTaskStatus:input_type -> JobStatusRequest
-	24, // 10: AgentService.GetScriptChecksums:input_type -> google.protobuf.Empty
-	9,  // 11: AgentService.SendScriptFile:input_type -> FileContent
-	11, // 12: AgentService.DeleteScriptFile:input_type -> DeleteScriptRequest
-	24, // 13: AgentService.UnregisterAgentAsk:input_type -> google.protobuf.Empty
-	24, // 14: AgentService.TryAgentAddress:input_type -> google.protobuf.Empty
-	24, // 15: AgentService.GetConfigChecksums:input_type -> google.protobuf.Empty
-	9,  // 16: AgentService.SendConfigFile:input_type -> FileContent
-	24, // 17: AgentService.GetTools:input_type -> google.protobuf.Empty
-	20, // 18: AgentService.ExecuteTool:input_type -> ExecuteToolRequest
-	22, // 19: AgentService.ReportToolExecutionStatus:input_type -> ToolExecutionStatusRequest
-	14, // 20: ManagerService.RegisterAgent:input_type -> RegisterAgentRequest
-	16, // 21: ManagerService.UnregisterAgent:input_type -> UnregisterAgentRequest
-	12, // 22: ManagerService.Heartbeat:input_type -> HeartbeatRequest
-	4,  // 23: AgentService.AssignTask:output_type -> AssignTaskResponse
-	6,  // 24: AgentService.ReportTaskStatus:output_type -> JobStatusResponse
-	8,  // 25: AgentService.GetScriptChecksums:output_type -> ChecksumResponse
-	10, // 26: AgentService.SendScriptFile:output_type -> SyncStatus
-	10, // 27: AgentService.DeleteScriptFile:output_type -> SyncStatus
-	24, // 28: AgentService.UnregisterAgentAsk:output_type -> google.protobuf.Empty
-	24, // 29: AgentService.TryAgentAddress:output_type -> google.protobuf.Empty
-	8,  // 30: AgentService.GetConfigChecksums:output_type -> ChecksumResponse
-	10, // 31: AgentService.SendConfigFile:output_type -> SyncStatus
-	19, // 32: AgentService.GetTools:output_type -> GetToolsResponse
-	21, // 33: AgentService.ExecuteTool:output_type -> ExecuteToolResponse
-	23, // 34: AgentService.ReportToolExecutionStatus:output_type -> ToolExecutionStatusResponse
-	15, // 35: ManagerService.RegisterAgent:output_type -> RegisterAgentResponse
-	24, // 36: ManagerService.UnregisterAgent:output_type -> google.protobuf.Empty
-	13, // 37: ManagerService.Heartbeat:output_type -> HeartbeatResponse
-	23, // [23:38] is the sub-list for method output_type
-	8,  // [8:23] is the sub-list for method input_type
-	8,  // [8:8] is the sub-list for extension type_name
-	8,  // [8:8] is the sub-list for extension extendee
-	0,  // [0:8] is the sub-list for field type_name
+	55, // 0: Job.timeout:type_name -> google.protobuf.Duration
+	53, // 1: Job.env:type_name -> Job.EnvEntry
+	0,  // 2: Task.status:type_name -> TaskStatus
+	4,  // 3: AssignTaskRequest.task:type_name -> Task
+	3,  // 4: AssignTaskRequest.job:type_name -> Job
+	56, // 5: ExecutionResult.started_at:type_name -> google.protobuf.Timestamp
+	56, // 6: ExecutionResult.finished_at:type_name -> google.protobuf.Timestamp
+	8,  // 7: ExecutionResult.resources:type_name -> ResourceUsage
+	55, // 8: ResourceUsage.cpu_time:type_name -> google.protobuf.Duration
+	0,  // 9: JobStatusResponse.status:type_name -> TaskStatus
+	56, // 10: JobStatusResponse.started_at:type_name -> google.protobuf.Timestamp
+	56, // 11: JobStatusResponse.finished_at:type_name -> google.protobuf.Timestamp
+	7,  // 12: JobStatusResponse.execution:type_name -> ExecutionResult
+	1,  // 13: TaskOutputChunk.stream:type_name -> OutputStream
+	56, // 14: TaskOutputChunk.timestamp:type_name -> google.protobuf.Timestamp
+	14, // 15: TaskOutputMessage.chunk:type_name -> TaskOutputChunk
+	12, // 16: TaskOutputMessage.status:type_name -> JobStatusResponse
+	16, // 17: ChecksumResponse.files:type_name -> Checksum
+	21, // 18: ScriptManifest.scripts:type_name -> ScriptManifestEntry
+	18, // 19: StageScriptRequest.file:type_name -> FileContent
+	26, // 20: ScriptInfo.args:type_name -> ScriptArg
+	55, // 21: ScriptInfo.timeout:type_name -> google.protobuf.Duration
+	27, // 22: ListScriptsResponse.scripts:type_name -> ScriptInfo
+	2,  // 23: UploadHeader.kind:type_name -> FileKind
+	29, // 24: UploadFileMessage.header:type_name -> UploadHeader
+	30, // 25: UploadFileMessage.trailer:type_name -> UploadTrailer
+	2,  // 26: UploadOffsetRequest.kind:type_name -> FileKind
+	22, // 27: DesiredState.scripts:type_name -> ScriptManifest
+	16, // 28: DesiredState.configs:type_name -> Checksum
+	2,  // 29: DownloadFileRequest.kind:type_name -> FileKind
+	44, // 30: Tool.actions:type_name -> ToolAction
+	45, // 31: ToolAction.schema:type_name -> ToolOption
+	43, // 32: GetToolsResponse.tools:type_name -> Tool
+	54, // 33: ExecuteToolRequest.values:type_name -> ExecuteToolRequest.ValuesEntry
+	0,  // 34: ToolExecutionStatusResponse.status:type_name -> TaskStatus
+	7,  // 35: ToolExecutionStatusResponse.execution:type_name -> ExecutionResult
+	56, // 36: ToolExecutionStatusResponse.started_at:type_name -> google.protobuf.Timestamp
+	56, // 37: ToolExecutionStatusResponse.finished_at:type_name -> google.protobuf.Timestamp
+	50, // 38: ToolExecutionsResponse.executions:type_name -> ToolExecutionStatusResponse
+	5,  // 39: AgentService.AssignTask:input_type -> AssignTaskRequest
+	11, // 40: AgentService.ReportTaskStatus:input_type -> JobStatusRequest
+	9,  // 41: AgentService.CancelTask:input_type -> CancelTaskRequest
+	13, // 42: AgentService.StreamTaskOutput:input_type -> TaskOutputRequest
+	57, // 43: AgentService.GetScriptChecksums:input_type -> google.protobuf.Empty
+	18, // 44: AgentService.SendScriptFile:input_type -> FileContent
+	20, // 45: AgentService.DeleteScriptFile:input_type -> DeleteScriptRequest
+	22, // 46: AgentService.BeginScriptSync:input_type -> ScriptManifest
+	24, // 47: AgentService.StageScriptFile:input_type -> StageScriptRequest
+	25, // 48: AgentService.CommitScriptSync:input_type -> ScriptSyncRequest
+	25, // 49: AgentService.AbortScriptSync:input_type -> ScriptSyncRequest
+	57, // 50: AgentService.ListScripts:input_type -> google.protobuf.Empty
+	31, // 51: AgentService.UploadFile:input_type -> UploadFileMessage
+	33, // 52: AgentService.GetUploadOffset:input_type -> UploadOffsetRequest
+	57, // 53: AgentService.UnregisterAgentAsk:input_type -> google.protobuf.Empty
+	57, // 54: AgentService.TryAgentAddress:input_type -> google.protobuf.Empty
+	57, // 55: AgentService.GetConfigChecksums:input_type -> google.protobuf.Empty
+	18, // 56: AgentService.SendConfigFile:input_type -> FileContent
+	57, // 57: AgentService.GetTools:input_type -> google.protobuf.Empty
+	47, // 58: AgentService.ExecuteTool:input_type -> ExecuteToolRequest
+	49, // 59: AgentService.ReportToolExecutionStatus:input_type -> ToolExecutionStatusRequest
+	51, // 60: AgentService.ListToolExecutions:input_type -> ToolExecutionsRequest
+	40, // 61: ManagerService.RegisterAgent:input_type -> RegisterAgentRequest
+	42, // 62: ManagerService.UnregisterAgent:input_type -> UnregisterAgentRequest
+	38, // 63: ManagerService.Heartbeat:input_type -> HeartbeatRequest
+	35, // 64: ManagerService.GetDesiredState:input_type -> DesiredStateRequest
+	37, // 65: ManagerService.DownloadFile:input_type -> DownloadFileRequest
+	6,  // 66: AgentService.AssignTask:output_type -> AssignTaskResponse
+	12, // 67: AgentService.ReportTaskStatus:output_type -> JobStatusResponse
+	10, // 68: AgentService.CancelTask:output_type -> CancelTaskResponse
+	15, // 69: AgentService.StreamTaskOutput:output_type -> TaskOutputMessage
+	17, // 70: AgentService.GetScriptChecksums:output_type -> ChecksumResponse
+	19, // 71: AgentService.SendScriptFile:output_type -> SyncStatus
+	19, // 72: AgentService.DeleteScriptFile:output_type -> SyncStatus
+	23, // 73: AgentService.BeginScriptSync:output_type -> ScriptSyncSession
+	19, // 74: AgentService.StageScriptFile:output_type -> SyncStatus
+	19, // 75: AgentService.CommitScriptSync:output_type -> SyncStatus
+	19, // 76: AgentService.AbortScriptSync:output_type -> SyncStatus
+	28, // 77: AgentService.ListScripts:output_type -> ListScriptsResponse
+	32, // 78: AgentService.UploadFile:output_type -> UploadFileResponse
+	34, // 79: AgentService.GetUploadOffset:output_type -> UploadOffsetResponse
+	57, // 80: AgentService.UnregisterAgentAsk:output_type -> google.protobuf.Empty
+	57, // 81: AgentService.TryAgentAddress:output_type -> google.protobuf.Empty
+	17, // 82: AgentService.GetConfigChecksums:output_type -> ChecksumResponse
+	19, // 83: AgentService.SendConfigFile:output_type -> SyncStatus
+	46, // 84: AgentService.GetTools:output_type -> GetToolsResponse
+	48, // 85: AgentService.ExecuteTool:output_type -> ExecuteToolResponse
+	50, // 86: AgentService.ReportToolExecutionStatus:output_type -> ToolExecutionStatusResponse
+	52, // 87: AgentService.ListToolExecutions:output_type -> ToolExecutionsResponse
+	41, // 88: ManagerService.RegisterAgent:output_type -> RegisterAgentResponse
+	57, // 89: ManagerService.UnregisterAgent:output_type -> google.protobuf.Empty
+	39, // 90: ManagerService.Heartbeat:output_type -> HeartbeatResponse
+	36, // 91: ManagerService.GetDesiredState:output_type -> DesiredState
+	31, // 92: ManagerService.DownloadFile:output_type -> UploadFileMessage
+	66, // [66:93] is the sub-list for method output_type
+	39, // [39:66] is the sub-list for method input_type
+	39, // [39:39] is the sub-list for extension type_name
+	39, // [39:39] is the sub-list for extension extendee
+	0,  // [0:39] is the sub-list for field type_name
 }
 
 func init() { file_proto_rpc_proto_init() }
@@ -1518,13 +3786,22 @@ func file_proto_rpc_proto_init() {
 	if File_proto_rpc_proto != nil {
 		return
 	}
+	file_proto_rpc_proto_msgTypes[12].OneofWrappers = []any{
+		(*TaskOutputMessage_Chunk)(nil),
+		(*TaskOutputMessage_Status)(nil),
+	}
+	file_proto_rpc_proto_msgTypes[28].OneofWrappers = []any{
+		(*UploadFileMessage_Header)(nil),
+		(*UploadFileMessage_Chunk)(nil),
+		(*UploadFileMessage_Trailer)(nil),
+	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_rpc_proto_rawDesc), len(file_proto_rpc_proto_rawDesc)),
-			NumEnums:      1,
-			NumMessages:   23,
+			NumEnums:      3,
+			NumMessages:   52,
 			NumExtensions: 0,
 			NumServices:   2,
 		},
@@ -22,9 +22,18 @@ const _ = grpc.SupportPackageIsVersion9
 const (
 	AgentService_AssignTask_FullMethodName                = "/AgentService/AssignTask"
 	AgentService_ReportTaskStatus_FullMethodName          = "/AgentService/ReportTaskStatus"
+	AgentService_CancelTask_FullMethodName                = "/AgentService/CancelTask"
+	AgentService_StreamTaskOutput_FullMethodName          = "/AgentService/StreamTaskOutput"
 	AgentService_GetScriptChecksums_FullMethodName        = "/AgentService/GetScriptChecksums"
 	AgentService_SendScriptFile_FullMethodName            = "/AgentService/SendScriptFile"
 	AgentService_DeleteScriptFile_FullMethodName          = "/AgentService/DeleteScriptFile"
+	AgentService_BeginScriptSync_FullMethodName           = "/AgentService/BeginScriptSync"
+	AgentService_StageScriptFile_FullMethodName           = "/AgentService/StageScriptFile"
+	AgentService_CommitScriptSync_FullMethodName          = "/AgentService/CommitScriptSync"
+	AgentService_AbortScriptSync_FullMethodName           = "/AgentService/AbortScriptSync"
+	AgentService_ListScripts_FullMethodName               = "/AgentService/ListScripts"
+	AgentService_UploadFile_FullMethodName                = "/AgentService/UploadFile"
+	AgentService_GetUploadOffset_FullMethodName           = "/AgentService/GetUploadOffset"
 	AgentService_UnregisterAgentAsk_FullMethodName        = "/AgentService/UnregisterAgentAsk"
 	AgentService_TryAgentAddress_FullMethodName           = "/AgentService/TryAgentAddress"
 	AgentService_GetConfigChecksums_FullMethodName        = "/AgentService/GetConfigChecksums"
@@ -32,6 +41,7 @@ const (
 	AgentService_GetTools_FullMethodName                  = "/AgentService/GetTools"
 	AgentService_ExecuteTool_FullMethodName               = "/AgentService/ExecuteTool"
 	AgentService_ReportToolExecutionStatus_FullMethodName = "/AgentService/ReportToolExecutionStatus"
+	AgentService_ListToolExecutions_FullMethodName        = "/AgentService/ListToolExecutions"
 )
 
 // AgentServiceClient is the client API for AgentService service.
@@ -43,10 +53,20 @@ type AgentServiceClient interface {
 	// Tasks
 	AssignTask(ctx context.Context, in *AssignTaskRequest, opts ...grpc.CallOption) (*AssignTaskResponse, error)
 	ReportTaskStatus(ctx context.Context, in *JobStatusRequest, opts ...grpc.CallOption) (*JobStatusResponse, error)
+	CancelTask(ctx context.Context, in *CancelTaskRequest, opts ...grpc.CallOption) (*CancelTaskResponse, error)
+	StreamTaskOutput(ctx context.Context, in *TaskOutputRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[TaskOutputMessage], error)
 	// Scripts
 	GetScriptChecksums(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ChecksumResponse, error)
 	SendScriptFile(ctx context.Context, in *FileContent, opts ...grpc.CallOption) (*SyncStatus, error)
 	DeleteScriptFile(ctx context.Context, in *DeleteScriptRequest, opts ...grpc.CallOption) (*SyncStatus, error)
+	BeginScriptSync(ctx context.Context, in *ScriptManifest, opts ...grpc.CallOption) (*ScriptSyncSession, error)
+	StageScriptFile(ctx context.Context, in *StageScriptRequest, opts ...grpc.CallOption) (*SyncStatus, error)
+	CommitScriptSync(ctx context.Context, in *ScriptSyncRequest, opts ...grpc.CallOption) (*SyncStatus, error)
+	AbortScriptSync(ctx context.Context, in *ScriptSyncRequest, opts ...grpc.CallOption) (*SyncStatus, error)
+	ListScripts(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ListScriptsResponse, error)
+	// Files
+	UploadFile(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[UploadFileMessage, UploadFileResponse], error)
+	GetUploadOffset(ctx context.Context, in *UploadOffsetRequest, opts ...grpc.CallOption) (*UploadOffsetResponse, error)
 	// Agents
 	UnregisterAgentAsk(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error)
 	TryAgentAddress(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error)
@@ -57,6 +77,7 @@ type AgentServiceClient interface {
 	GetTools(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*GetToolsResponse, error)
 	ExecuteTool(ctx context.Context, in *ExecuteToolRequest, opts ...grpc.CallOption) (*ExecuteToolResponse, error)
 	ReportToolExecutionStatus(ctx context.Context, in *ToolExecutionStatusRequest, opts ...grpc.CallOption) (*ToolExecutionStatusResponse, error)
+	ListToolExecutions(ctx context.Context, in *ToolExecutionsRequest, opts ...grpc.CallOption) (*ToolExecutionsResponse, error)
 }
 
 type agentServiceClient struct {
@@ -87,6 +108,35 @@ func (c *agentServiceClient) ReportTaskStatus(ctx context.Context, in *JobStatus
 	return out, nil
 }
 
+func (c *agentServiceClient) CancelTask(ctx context.Context, in *CancelTaskRequest, opts ...grpc.CallOption) (*CancelTaskResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CancelTaskResponse)
+	err := c.cc.Invoke(ctx, AgentService_CancelTask_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentServiceClient) StreamTaskOutput(ctx context.Context, in *TaskOutputRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[TaskOutputMessage], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &AgentService_ServiceDesc.Streams[0], AgentService_StreamTaskOutput_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[TaskOutputRequest, TaskOutputMessage]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type AgentService_StreamTaskOutputClient = grpc.ServerStreamingClient[TaskOutputMessage]
+
 func (c *agentServiceClient) GetScriptChecksums(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ChecksumResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(ChecksumResponse)
@@ -117,6 +167,79 @@ func (c *agentServiceClient) DeleteScriptFile(ctx context.Context, in *DeleteScr
 	return out, nil
 }
 
+func (c *agentServiceClient) BeginScriptSync(ctx context.Context, in *ScriptManifest, opts ...grpc.CallOption) (*ScriptSyncSession, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ScriptSyncSession)
+	err := c.cc.Invoke(ctx, AgentService_BeginScriptSync_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentServiceClient) StageScriptFile(ctx context.Context, in *StageScriptRequest, opts ...grpc.CallOption) (*SyncStatus, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SyncStatus)
+	err := c.cc.Invoke(ctx, AgentService_StageScriptFile_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentServiceClient) CommitScriptSync(ctx context.Context, in *ScriptSyncRequest, opts ...grpc.CallOption) (*SyncStatus, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SyncStatus)
+	err := c.cc.Invoke(ctx, AgentService_CommitScriptSync_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentServiceClient) AbortScriptSync(ctx context.Context, in *ScriptSyncRequest, opts ...grpc.CallOption) (*SyncStatus, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SyncStatus)
+	err := c.cc.Invoke(ctx, AgentService_AbortScriptSync_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentServiceClient) ListScripts(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ListScriptsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListScriptsResponse)
+	err := c.cc.Invoke(ctx, AgentService_ListScripts_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentServiceClient) UploadFile(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[UploadFileMessage, UploadFileResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &AgentService_ServiceDesc.Streams[1], AgentService_UploadFile_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[UploadFileMessage, UploadFileResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type AgentService_UploadFileClient = grpc.ClientStreamingClient[UploadFileMessage, UploadFileResponse]
+
+func (c *agentServiceClient) GetUploadOffset(ctx context.Context, in *UploadOffsetRequest, opts ...grpc.CallOption) (*UploadOffsetResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UploadOffsetResponse)
+	err := c.cc.Invoke(ctx, AgentService_GetUploadOffset_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *agentServiceClient) UnregisterAgentAsk(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(emptypb.Empty)
@@ -187,6 +310,16 @@ func (c *agentServiceClient) ReportToolExecutionStatus(ctx context.Context, in *
 	return out, nil
 }
 
+func (c *agentServiceClient) ListToolExecutions(ctx context.Context, in *ToolExecutionsRequest, opts ...grpc.CallOption) (*ToolExecutionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ToolExecutionsResponse)
+	err := c.cc.Invoke(ctx, AgentService_ListToolExecutions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // AgentServiceServer is the server API for AgentService service.
 // All implementations must embed UnimplementedAgentServiceServer
 // for forward compatibility.
@@ -196,10 +329,20 @@ type AgentServiceServer interface {
 	// Tasks
 	AssignTask(context.Context, *AssignTaskRequest) (*AssignTaskResponse, error)
 	ReportTaskStatus(context.Context, *JobStatusRequest) (*JobStatusResponse, error)
+	CancelTask(context.Context, *CancelTaskRequest) (*CancelTaskResponse, error)
+	StreamTaskOutput(*TaskOutputRequest, grpc.ServerStreamingServer[TaskOutputMessage]) error
 	// Scripts
 	GetScriptChecksums(context.Context, *emptypb.Empty) (*ChecksumResponse, error)
 	SendScriptFile(context.Context, *FileContent) (*SyncStatus, error)
 	DeleteScriptFile(context.Context, *DeleteScriptRequest) (*SyncStatus, error)
+	BeginScriptSync(context.Context, *ScriptManifest) (*ScriptSyncSession, error)
+	StageScriptFile(context.Context, *StageScriptRequest) (*SyncStatus, error)
+	CommitScriptSync(context.Context, *ScriptSyncRequest) (*SyncStatus, error)
+	AbortScriptSync(context.Context, *ScriptSyncRequest) (*SyncStatus, error)
+	ListScripts(context.Context, *emptypb.Empty) (*ListScriptsResponse, error)
+	// Files
+	UploadFile(grpc.ClientStreamingServer[UploadFileMessage, UploadFileResponse]) error
+	GetUploadOffset(context.Context, *UploadOffsetRequest) (*UploadOffsetResponse, error)
 	// Agents
 	UnregisterAgentAsk(context.Context, *emptypb.Empty) (*emptypb.Empty, error)
 	TryAgentAddress(context.Context, *emptypb.Empty) (*emptypb.Empty, error)
@@ -210,6 +353,7 @@ type AgentServiceServer interface {
 	GetTools(context.Context, *emptypb.Empty) (*GetToolsResponse, error)
 	ExecuteTool(context.Context, *ExecuteToolRequest) (*ExecuteToolResponse, error)
 	ReportToolExecutionStatus(context.Context, *ToolExecutionStatusRequest) (*ToolExecutionStatusResponse, error)
+	ListToolExecutions(context.Context, *ToolExecutionsRequest) (*ToolExecutionsResponse, error)
 	mustEmbedUnimplementedAgentServiceServer()
 }
 
@@ -226,6 +370,12 @@ func (UnimplementedAgentServiceServer) AssignTask(context.Context, *AssignTaskRe
 func (UnimplementedAgentServiceServer) ReportTaskStatus(context.Context, *JobStatusRequest) (*JobStatusResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ReportTaskStatus not implemented")
 }
+func (UnimplementedAgentServiceServer) CancelTask(context.Context, *CancelTaskRequest) (*CancelTaskResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelTask not implemented")
+}
+func (UnimplementedAgentServiceServer) StreamTaskOutput(*TaskOutputRequest, grpc.ServerStreamingServer[TaskOutputMessage]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamTaskOutput not implemented")
+}
 func (UnimplementedAgentServiceServer) GetScriptChecksums(context.Context, *emptypb.Empty) (*ChecksumResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetScriptChecksums not implemented")
 }
@@ -235,6 +385,27 @@ func (UnimplementedAgentServiceServer) SendScriptFile(context.Context, *FileCont
 func (UnimplementedAgentServiceServer) DeleteScriptFile(context.Context, *DeleteScriptRequest) (*SyncStatus, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeleteScriptFile not implemented")
 }
+func (UnimplementedAgentServiceServer) BeginScriptSync(context.Context, *ScriptManifest) (*ScriptSyncSession, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BeginScriptSync not implemented")
+}
+func (UnimplementedAgentServiceServer) StageScriptFile(context.Context, *StageScriptRequest) (*SyncStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StageScriptFile not implemented")
+}
+func (UnimplementedAgentServiceServer) CommitScriptSync(context.Context, *ScriptSyncRequest) (*SyncStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CommitScriptSync not implemented")
+}
+func (UnimplementedAgentServiceServer) AbortScriptSync(context.Context, *ScriptSyncRequest) (*SyncStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AbortScriptSync not implemented")
+}
+func (UnimplementedAgentServiceServer) ListScripts(context.Context, *emptypb.Empty) (*ListScriptsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListScripts not implemented")
+}
+func (UnimplementedAgentServiceServer) UploadFile(grpc.ClientStreamingServer[UploadFileMessage, UploadFileResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method UploadFile not implemented")
+}
+func (UnimplementedAgentServiceServer) GetUploadOffset(context.Context, *UploadOffsetRequest) (*UploadOffsetResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUploadOffset not implemented")
+}
 func (UnimplementedAgentServiceServer) UnregisterAgentAsk(context.Context, *emptypb.Empty) (*emptypb.Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method UnregisterAgentAsk not implemented")
 }
@@ -256,6 +427,9 @@ func (UnimplementedAgentServiceServer) ExecuteTool(context.Context, *ExecuteTool
 func (UnimplementedAgentServiceServer) ReportToolExecutionStatus(context.Context, *ToolExecutionStatusRequest) (*ToolExecutionStatusResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ReportToolExecutionStatus not implemented")
 }
+func (UnimplementedAgentServiceServer) ListToolExecutions(context.Context, *ToolExecutionsRequest) (*ToolExecutionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListToolExecutions not implemented")
+}
 func (UnimplementedAgentServiceServer) mustEmbedUnimplementedAgentServiceServer() {}
 func (UnimplementedAgentServiceServer) testEmbeddedByValue()                      {}
 
@@ -313,6 +487,35 @@ func _AgentService_ReportTaskStatus_Handler(srv interface{}, ctx context.Context
 	return interceptor(ctx, in, info, handler)
 }
 
+func _AgentService_CancelTask_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelTaskRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).CancelTask(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_CancelTask_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).CancelTask(ctx, req.(*CancelTaskRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentService_StreamTaskOutput_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(TaskOutputRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(AgentServiceServer).StreamTaskOutput(m, &grpc.GenericServerStream[TaskOutputRequest, TaskOutputMessage]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type AgentService_StreamTaskOutputServer = grpc.ServerStreamingServer[TaskOutputMessage]
+
 func _AgentService_GetScriptChecksums_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(emptypb.Empty)
 	if err := dec(in); err != nil {
@@ -367,6 +570,121 @@ func _AgentService_DeleteScriptFile_Handler(srv interface{}, ctx context.Context
 	return interceptor(ctx, in, info, handler)
 }
 
+func _AgentService_BeginScriptSync_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ScriptManifest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).BeginScriptSync(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_BeginScriptSync_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).BeginScriptSync(ctx, req.(*ScriptManifest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentService_StageScriptFile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StageScriptRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).StageScriptFile(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_StageScriptFile_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).StageScriptFile(ctx, req.(*StageScriptRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentService_CommitScriptSync_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ScriptSyncRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).CommitScriptSync(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_CommitScriptSync_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).CommitScriptSync(ctx, req.(*ScriptSyncRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentService_AbortScriptSync_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ScriptSyncRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).AbortScriptSync(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_AbortScriptSync_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).AbortScriptSync(ctx, req.(*ScriptSyncRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentService_ListScripts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).ListScripts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_ListScripts_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).ListScripts(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentService_UploadFile_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(AgentServiceServer).UploadFile(&grpc.GenericServerStream[UploadFileMessage, UploadFileResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type AgentService_UploadFileServer = grpc.ClientStreamingServer[UploadFileMessage, UploadFileResponse]
+
+func _AgentService_GetUploadOffset_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UploadOffsetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).GetUploadOffset(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_GetUploadOffset_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).GetUploadOffset(ctx, req.(*UploadOffsetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _AgentService_UnregisterAgentAsk_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(emptypb.Empty)
 	if err := dec(in); err != nil {
@@ -493,6 +811,24 @@ func _AgentService_ReportToolExecutionStatus_Handler(srv interface{}, ctx contex
 	return interceptor(ctx, in, info, handler)
 }
 
+func _AgentService_ListToolExecutions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ToolExecutionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).ListToolExecutions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_ListToolExecutions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).ListToolExecutions(ctx, req.(*ToolExecutionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // AgentService_ServiceDesc is the grpc.ServiceDesc for AgentService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -508,6 +844,10 @@ var AgentService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ReportTaskStatus",
 			Handler:    _AgentService_ReportTaskStatus_Handler,
 		},
+		{
+			MethodName: "CancelTask",
+			Handler:    _AgentService_CancelTask_Handler,
+		},
 		{
 			MethodName: "GetScriptChecksums",
 			Handler:    _AgentService_GetScriptChecksums_Handler,
@@ -520,6 +860,30 @@ var AgentService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "DeleteScriptFile",
 			Handler:    _AgentService_DeleteScriptFile_Handler,
 		},
+		{
+			MethodName: "BeginScriptSync",
+			Handler:    _AgentService_BeginScriptSync_Handler,
+		},
+		{
+			MethodName: "StageScriptFile",
+			Handler:    _AgentService_StageScriptFile_Handler,
+		},
+		{
+			MethodName: "CommitScriptSync",
+			Handler:    _AgentService_CommitScriptSync_Handler,
+		},
+		{
+			MethodName: "AbortScriptSync",
+			Handler:    _AgentService_AbortScriptSync_Handler,
+		},
+		{
+			MethodName: "ListScripts",
+			Handler:    _AgentService_ListScripts_Handler,
+		},
+		{
+			MethodName: "GetUploadOffset",
+			Handler:    _AgentService_GetUploadOffset_Handler,
+		},
 		{
 			MethodName: "UnregisterAgentAsk",
 			Handler:    _AgentService_UnregisterAgentAsk_Handler,
@@ -548,8 +912,23 @@ var AgentService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ReportToolExecutionStatus",
 			Handler:    _AgentService_ReportToolExecutionStatus_Handler,
 		},
+		{
+			MethodName: "ListToolExecutions",
+			Handler:    _AgentService_ListToolExecutions_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamTaskOutput",
+			Handler:       _AgentService_StreamTaskOutput_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "UploadFile",
+			Handler:       _AgentService_UploadFile_Handler,
+			ClientStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/rpc.proto",
 }
 
@@ -557,6 +936,8 @@ const (
 	ManagerService_RegisterAgent_FullMethodName   = "/ManagerService/RegisterAgent"
 	ManagerService_UnregisterAgent_FullMethodName = "/ManagerService/UnregisterAgent"
 	ManagerService_Heartbeat_FullMethodName       = "/ManagerService/Heartbeat"
+	ManagerService_GetDesiredState_FullMethodName = "/ManagerService/GetDesiredState"
+	ManagerService_DownloadFile_FullMethodName    = "/ManagerService/DownloadFile"
 )
 
 // ManagerServiceClient is the client API for ManagerService service.
@@ -568,6 +949,9 @@ type ManagerServiceClient interface {
 	RegisterAgent(ctx context.Context, in *RegisterAgentRequest, opts ...grpc.CallOption) (*RegisterAgentResponse, error)
 	UnregisterAgent(ctx context.Context, in *UnregisterAgentRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
 	Heartbeat(ctx context.Context, in *HeartbeatRequest, opts ...grpc.CallOption) (*HeartbeatResponse, error)
+	GetDesiredState(ctx context.Context, in *DesiredStateRequest, opts ...grpc.CallOption) (*DesiredState, error)
+	// Streams the file's chunks followed by a trailer, as in UploadFile
+	DownloadFile(ctx context.Context, in *DownloadFileRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[UploadFileMessage], error)
 }
 
 type managerServiceClient struct {
@@ -608,6 +992,35 @@ func (c *managerServiceClient) Heartbeat(ctx context.Context, in *HeartbeatReque
 	return out, nil
 }
 
+func (c *managerServiceClient) GetDesiredState(ctx context.Context, in *DesiredStateRequest, opts ...grpc.CallOption) (*DesiredState, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DesiredState)
+	err := c.cc.Invoke(ctx, ManagerService_GetDesiredState_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *managerServiceClient) DownloadFile(ctx context.Context, in *DownloadFileRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[UploadFileMessage], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ManagerService_ServiceDesc.Streams[0], ManagerService_DownloadFile_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[DownloadFileRequest, UploadFileMessage]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ManagerService_DownloadFileClient = grpc.ServerStreamingClient[UploadFileMessage]
+
 // ManagerServiceServer is the server API for ManagerService service.
 // All implementations must embed UnimplementedManagerServiceServer
 // for forward compatibility.
@@ -617,6 +1030,9 @@ type ManagerServiceServer interface {
 	RegisterAgent(context.Context, *RegisterAgentRequest) (*RegisterAgentResponse, error)
 	UnregisterAgent(context.Context, *UnregisterAgentRequest) (*emptypb.Empty, error)
 	Heartbeat(context.Context, *HeartbeatRequest) (*HeartbeatResponse, error)
+	GetDesiredState(context.Context, *DesiredStateRequest) (*DesiredState, error)
+	// Streams the file's chunks followed by a trailer, as in UploadFile
+	DownloadFile(*DownloadFileRequest, grpc.ServerStreamingServer[UploadFileMessage]) error
 	mustEmbedUnimplementedManagerServiceServer()
 }
 
@@ -636,6 +1052,12 @@ func (UnimplementedManagerServiceServer) UnregisterAgent(context.Context, *Unreg
 func (UnimplementedManagerServiceServer) Heartbeat(context.Context, *HeartbeatRequest) (*HeartbeatResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Heartbeat not implemented")
 }
+func (UnimplementedManagerServiceServer) GetDesiredState(context.Context, *DesiredStateRequest) (*DesiredState, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDesiredState not implemented")
+}
+func (UnimplementedManagerServiceServer) DownloadFile(*DownloadFileRequest, grpc.ServerStreamingServer[UploadFileMessage]) error {
+	return status.Errorf(codes.Unimplemented, "method DownloadFile not implemented")
+}
 func (UnimplementedManagerServiceServer) mustEmbedUnimplementedManagerServiceServer() {}
 func (UnimplementedManagerServiceServer) testEmbeddedByValue()                        {}
 
@@ -711,6 +1133,35 @@ func _ManagerService_Heartbeat_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ManagerService_GetDesiredState_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DesiredStateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ManagerServiceServer).GetDesiredState(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ManagerService_GetDesiredState_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ManagerServiceServer).GetDesiredState(ctx, req.(*DesiredStateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ManagerService_DownloadFile_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(DownloadFileRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ManagerServiceServer).DownloadFile(m, &grpc.GenericServerStream[DownloadFileRequest, UploadFileMessage]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ManagerService_DownloadFileServer = grpc.ServerStreamingServer[UploadFileMessage]
+
 // ManagerService_ServiceDesc is the grpc.ServiceDesc for ManagerService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -730,7 +1181,17 @@ var ManagerService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Heartbeat",
 			Handler:    _ManagerService_Heartbeat_Handler,
 		},
+		{
+			MethodName: "GetDesiredState",
+			Handler:    _ManagerService_GetDesiredState_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "DownloadFile",
+			Handler:       _ManagerService_DownloadFile_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/rpc.proto",
 }